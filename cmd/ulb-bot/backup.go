@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/pmurley/ulb-bot/internal/backup"
+	"github.com/pmurley/ulb-bot/internal/config"
+)
+
+// runBackup saves a backup of the storage files without starting the bot
+func runBackup(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	out := fs.String("out", cfg.BackupsDir(), "directory to save the archive in")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ulb-bot backup [--out <dir>]")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	path, manifest, err := backup.Save(*out, backup.LabelManual, cfg.StorageDir(), nil)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Saved", path)
+	printManifest(manifest)
+	return nil
+}
+
+// runRestore replaces the storage files with those in an archive. It refuses
+// to run while the bot has the data directory open.
+func runRestore(cfg *config.Config, args []string) error {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	check := fs.Bool("check", false, "only verify the archive")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: ulb-bot restore [--check] <archive>")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return errUsage
+	}
+	path := fs.Arg(0)
+
+	if *check {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		manifest, _, err := backup.Read(file)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Archive is intact")
+		printManifest(manifest)
+		return nil
+	}
+
+	manifest, previous, err := backup.Restore(path, cfg.StorageDir(), cfg.BackupsDir())
+	if previous != "" {
+		fmt.Println("Previous data saved to", previous)
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Restored", cfg.StorageDir(), "from", path)
+	printManifest(manifest)
+	return nil
+}
+
+// printManifest lists the files in a backup
+func printManifest(manifest *backup.Manifest) {
+	fmt.Println("Created:", manifest.CreatedAt.Format("2006-01-02 15:04:05 MST"))
+	for _, f := range manifest.Files {
+		fmt.Printf("  %-22s %10d bytes  sha256:%s\n", f.Name, f.Size, f.SHA256)
+	}
+}
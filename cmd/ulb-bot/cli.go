@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pmurley/ulb-bot/internal/command"
+	"github.com/pmurley/ulb-bot/internal/config"
+	"github.com/pmurley/ulb-bot/internal/models"
+	"github.com/pmurley/ulb-bot/internal/sheets"
+)
+
+// subcommand is a command that works without connecting to Discord
+type subcommand struct {
+	usage string
+	run   func(cfg *config.Config, args []string) error
+}
+
+// commands returns the subcommands by name
+func commands() map[string]subcommand {
+	return map[string]subcommand{
+		"backup":       {"backup [--out <dir>]", runBackup},
+		"restore":      {"restore [--check] <archive>", runRestore},
+		"player":       {"player <name> [--sheet <file>] [--json]", runPlayer},
+		"team":         {"team <name> [--status=..] [--position=..] [--age=..] [--contracts] [--sheet <file>] [--json]", runTeam},
+		"trade":        {"trade [-v] \"<players> for <players>\" [--sheet <file>] [--json]", runTrade},
+		"transactions": {"transactions import [--file <script>] | list [filters] [--page=<n>] [--csv] [--json]", runTransactions},
+		"config":       {"config check [--json]", runConfig},
+		"digest":       {"digest template", runDigest},
+		"console":      {"console [--sheet <file>] [--as <username>] [--files <dir>]", runConsole},
+	}
+}
+
+// runCommand runs a subcommand and returns the process exit code
+func runCommand(cfg *config.Config, name string, args []string) int {
+	cmds := commands()
+	cmd, ok := cmds[name]
+	if !ok {
+		if name != "help" && name != "-h" && name != "--help" {
+			fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", name)
+		}
+		printUsage(cmds)
+		return 2
+	}
+
+	if err := cmd.run(cfg, args); err != nil {
+		if errors.Is(err, errUsage) {
+			fmt.Fprintln(os.Stderr, "Usage: ulb-bot "+cmd.usage)
+			return 2
+		}
+		fmt.Fprintln(os.Stderr, "Error: "+strings.TrimSpace(command.PlainText(err.Error())))
+		return 1
+	}
+	return 0
+}
+
+// errUsage makes runCommand print the command's usage
+var errUsage = errors.New("invalid usage")
+
+// printUsage lists the subcommands
+func printUsage(cmds map[string]subcommand) {
+	names := make([]string, 0, len(cmds))
+	for name := range cmds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(os.Stderr, "Usage: ulb-bot [command]")
+	fmt.Fprintln(os.Stderr, "Without a command the Discord bot is started. Commands:")
+	for _, name := range names {
+		fmt.Fprintln(os.Stderr, "  ulb-bot "+cmds[name].usage)
+	}
+}
+
+// takeFlag removes a boolean flag from args and reports whether it was present
+func takeFlag(args []string, name string) (bool, []string) {
+	found := false
+	rest := make([]string, 0, len(args))
+	for _, arg := range args {
+		if arg == name {
+			found = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	return found, rest
+}
+
+// takeValue removes a flag with a value, given as "--name value" or
+// "--name=value", from args
+func takeValue(args []string, name string) (string, []string, error) {
+	value := ""
+	rest := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == name:
+			if i+1 >= len(args) {
+				return "", nil, fmt.Errorf("missing value for %s", name)
+			}
+			value = args[i+1]
+			i++
+		case strings.HasPrefix(args[i], name+"="):
+			value = strings.TrimPrefix(args[i], name+"=")
+		default:
+			rest = append(rest, args[i])
+		}
+	}
+	return value, rest, nil
+}
+
+// loadPlayers reads the Master Player Pool from a local CSV export when
+// sheetFile is set, and from Google Sheets otherwise
+func loadPlayers(cfg *config.Config, sheetFile string) (models.PlayerList, error) {
+	if sheetFile != "" {
+		return sheets.LoadPlayerPoolFile(sheetFile)
+	}
+	if cfg.GoogleSheetsID == "" {
+		return nil, errors.New("GOOGLE_SHEETS_ID is not set; use --sheet <file> to read a CSV export of the Master Player Pool")
+	}
+
+	client, err := sheets.NewClient(cfg.GoogleSheetsID)
+	if err != nil {
+		return nil, err
+	}
+	return client.LoadMasterPlayerPool()
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// printEmbeds writes embeds to stdout as plain text, separated by blank lines
+func printEmbeds(embeds ...*discordgo.MessageEmbed) {
+	for i, embed := range embeds {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Print(command.EmbedText(embed))
+	}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/pmurley/ulb-bot/internal/budget"
+	"github.com/pmurley/ulb-bot/internal/config"
+	"github.com/pmurley/ulb-bot/internal/digest"
+	"github.com/pmurley/ulb-bot/internal/fantrax"
+	"github.com/pmurley/ulb-bot/internal/notify"
+	"github.com/pmurley/ulb-bot/internal/ratelimit"
+	"github.com/pmurley/ulb-bot/internal/routing"
+	"github.com/pmurley/ulb-bot/internal/storage"
+)
+
+// configCheck is the outcome of checking one part of the configuration
+type configCheck struct {
+	Name   string
+	OK     bool
+	Detail string `json:",omitempty"`
+}
+
+// runConfig dispatches the config subcommands
+func runConfig(cfg *config.Config, args []string) error {
+	asJSON, args := takeFlag(args, "--json")
+	if len(args) != 1 || args[0] != "check" {
+		return errUsage
+	}
+
+	checks := checkConfig(cfg)
+	failed := 0
+	for _, check := range checks {
+		if !check.OK {
+			failed++
+		}
+	}
+
+	if asJSON {
+		if err := printJSON(checks); err != nil {
+			return err
+		}
+	} else {
+		for _, check := range checks {
+			status := "ok  "
+			if !check.OK {
+				status = "FAIL"
+			}
+			fmt.Printf("%s  %-20s %s\n", status, check.Name, check.Detail)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", failed, len(checks))
+	}
+	return nil
+}
+
+// checkConfig validates the environment and loads every config file the bot
+// loads at startup, with the same loaders
+func checkConfig(cfg *config.Config) []configCheck {
+	var checks []configCheck
+	add := func(name, detail string, err error) {
+		if err != nil {
+			checks = append(checks, configCheck{Name: name, Detail: err.Error()})
+			return
+		}
+		checks = append(checks, configCheck{Name: name, OK: true, Detail: detail})
+	}
+	required := func(name, value string) {
+		if value == "" {
+			add(name, "", errors.New("not set"))
+		} else {
+			add(name, "set", nil)
+		}
+	}
+	file := func(path string) string {
+		if path == "" {
+			return "defaults"
+		}
+		return path
+	}
+
+	required("DISCORD_TOKEN", cfg.DiscordToken)
+	required("GOOGLE_SHEETS_ID", cfg.GoogleSheetsID)
+
+	if cfg.FantraxScript != "" {
+		_, err := fantrax.NewScriptSource(cfg.FantraxScript, cfg.FantraxScriptSpeed)
+		add("FANTRAX_SCRIPT", cfg.FantraxScript, err)
+	} else {
+		required("FANTRAX_LEAGUE_ID", cfg.FantraxLeagueID)
+	}
+
+	_, err := routing.LoadConfig(cfg.RoutingConfig)
+	add("ROUTING_CONFIG", file(cfg.RoutingConfig), err)
+	_, err = notify.LoadConfig(cfg.NotifyConfig)
+	add("NOTIFY_CONFIG", file(cfg.NotifyConfig), err)
+	_, err = digest.LoadTemplate(cfg.DigestTemplate)
+	add("DIGEST_TEMPLATE", file(cfg.DigestTemplate), err)
+	_, err = budget.LoadConfig(cfg.BudgetConfig)
+	add("BUDGET_CONFIG", file(cfg.BudgetConfig), err)
+	_, err = ratelimit.LoadConfig(cfg.RateLimitConfig)
+	add("RATE_LIMIT_CONFIG", file(cfg.RateLimitConfig), err)
+
+	switch cfg.StorageBackend {
+	case storage.BackendCSV, storage.BackendBolt:
+		add("STORAGE_BACKEND", cfg.StorageBackend, nil)
+	default:
+		add("STORAGE_BACKEND", "", fmt.Errorf("unknown backend %q (use %s or %s)", cfg.StorageBackend, storage.BackendCSV, storage.BackendBolt))
+	}
+
+	// A running bot holds the lock, which is fine; anything else is not
+	dir := cfg.StorageDir()
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		add("DATA_DIR", dir+" (created on first start)", nil)
+		return checks
+	}
+	unlock, err := storage.LockDir(dir)
+	switch {
+	case errors.Is(err, storage.ErrDataDirLocked):
+		add("DATA_DIR", dir+" (in use by a running bot)", nil)
+	case err != nil:
+		add("DATA_DIR", "", err)
+	default:
+		unlock()
+		add("DATA_DIR", dir, nil)
+	}
+
+	return checks
+}
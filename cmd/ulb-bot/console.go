@@ -0,0 +1,41 @@
+package main
+
+import (
+	"os"
+
+	"github.com/pmurley/ulb-bot/internal/bot"
+	"github.com/pmurley/ulb-bot/internal/config"
+)
+
+// runConsole starts an interactive prompt that runs bot commands locally
+func runConsole(cfg *config.Config, args []string) error {
+	sheetFile, args, err := takeValue(args, "--sheet")
+	if err != nil {
+		return err
+	}
+	username, args, err := takeValue(args, "--as")
+	if err != nil {
+		return err
+	}
+	fileDir, args, err := takeValue(args, "--files")
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return errUsage
+	}
+	if username == "" {
+		username = "console"
+	}
+	if fileDir == "" {
+		fileDir = "."
+	}
+
+	b, err := bot.New(cfg, cliLogger(cfg))
+	if err != nil {
+		return err
+	}
+	defer b.Stop()
+
+	return b.RunConsole(os.Stdin, os.Stdout, username, sheetFile, fileDir)
+}
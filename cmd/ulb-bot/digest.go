@@ -0,0 +1,19 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/pmurley/ulb-bot/internal/config"
+	"github.com/pmurley/ulb-bot/internal/digest"
+)
+
+// runDigest dispatches the digest subcommands
+func runDigest(cfg *config.Config, args []string) error {
+	if len(args) != 1 || args[0] != "template" {
+		return errUsage
+	}
+
+	// The built-in layout is the starting point for a custom DIGEST_TEMPLATE
+	fmt.Print(digest.DefaultTemplate)
+	return nil
+}
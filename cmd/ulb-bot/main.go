@@ -22,6 +22,11 @@ func main() {
 		log.Fatal("Failed to load configuration:", err)
 	}
 
+	// Subcommands work on the data directory and sheet without connecting to Discord
+	if len(os.Args) > 1 {
+		os.Exit(runCommand(cfg, os.Args[1], os.Args[2:]))
+	}
+
 	log := logger.New(cfg.LogLevel)
 
 	log.Info("Starting ulb-bot")
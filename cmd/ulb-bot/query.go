@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pmurley/ulb-bot/internal/config"
+	"github.com/pmurley/ulb-bot/internal/discord"
+	"github.com/pmurley/ulb-bot/internal/models"
+)
+
+// runPlayer looks up a player like !player
+func runPlayer(cfg *config.Config, args []string) error {
+	asJSON, args := takeFlag(args, "--json")
+	sheetFile, args, err := takeValue(args, "--sheet")
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	players, err := loadPlayers(cfg, sheetFile)
+	if err != nil {
+		return err
+	}
+
+	matches, err := discord.LookupPlayer(players, strings.Join(args, " "))
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		return printJSON(matches)
+	}
+
+	var embeds []*discordgo.MessageEmbed
+	for i := range matches {
+		embeds = append(embeds, discord.BuildPlayerEmbed(&matches[i]))
+	}
+	printEmbeds(embeds...)
+	return nil
+}
+
+// teamResult is the JSON output of the team command
+type teamResult struct {
+	Team    string
+	Filters discord.TeamFilters
+	Players models.PlayerList
+}
+
+// runTeam shows a team's roster like !team, with the same filter options
+func runTeam(cfg *config.Config, args []string) error {
+	asJSON, args := takeFlag(args, "--json")
+	sheetFile, args, err := takeValue(args, "--sheet")
+	if err != nil {
+		return err
+	}
+
+	teamName, filters := discord.ParseTeamArgs(args)
+	if teamName == "" {
+		return errUsage
+	}
+
+	players, err := loadPlayers(cfg, sheetFile)
+	if err != nil {
+		return err
+	}
+
+	teamName, teamPlayers, err := discord.FindTeam(players, teamName)
+	if err != nil {
+		return err
+	}
+
+	filtered := discord.ApplyTeamFilters(teamPlayers, filters)
+	if len(filtered) == 0 {
+		return fmt.Errorf("No players found for %s with the specified filters", teamName)
+	}
+
+	if asJSON {
+		return printJSON(teamResult{Team: teamName, Filters: filters, Players: filtered})
+	}
+
+	printEmbeds(discord.BuildTeamRosterEmbed(teamName, filtered, filters))
+	return nil
+}
+
+// runTrade analyzes a trade like !trade
+func runTrade(cfg *config.Config, args []string) error {
+	asJSON, args := takeFlag(args, "--json")
+	sheetFile, args, err := takeValue(args, "--sheet")
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	request, err := discord.ParseTradeArgs(args)
+	if err != nil {
+		return err
+	}
+
+	players, err := loadPlayers(cfg, sheetFile)
+	if err != nil {
+		return err
+	}
+
+	analysis, err := discord.AnalyzeTrade(players, request)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		return printJSON(analysis)
+	}
+
+	printEmbeds(discord.BuildTradeEmbed(analysis, request.Verbose))
+	return nil
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	fantraxmodels "github.com/pmurley/go-fantrax/models"
+	"github.com/pmurley/ulb-bot/internal/bot"
+	"github.com/pmurley/ulb-bot/internal/command"
+	"github.com/pmurley/ulb-bot/internal/config"
+	"github.com/pmurley/ulb-bot/internal/discord"
+	"github.com/pmurley/ulb-bot/internal/fantrax"
+	"github.com/pmurley/ulb-bot/internal/storage"
+	"github.com/pmurley/ulb-bot/pkg/logger"
+)
+
+// runTransactions dispatches the transactions subcommands
+func runTransactions(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	switch args[0] {
+	case "import":
+		return runTransactionsImport(cfg, args[1:])
+	case "list":
+		return runTransactionsList(cfg, args[1:])
+	default:
+		return errUsage
+	}
+}
+
+// runTransactionsImport stores transactions from Fantrax, or from a
+// transaction script, that are not stored yet. Nothing is announced; a bot
+// started afterwards treats them as already seen.
+func runTransactionsImport(cfg *config.Config, args []string) error {
+	file, args, err := takeValue(args, "--file")
+	if err != nil {
+		return err
+	}
+	if len(args) > 0 {
+		return errUsage
+	}
+
+	var source fantrax.TransactionSource
+	if file != "" {
+		// An infinite speed releases the whole script at once
+		if source, err = fantrax.NewScriptSource(file, math.Inf(1)); err != nil {
+			return err
+		}
+	} else {
+		if cfg.FantraxLeagueID == "" {
+			return errors.New("FANTRAX_LEAGUE_ID is not set; use --file <script> to import from a file")
+		}
+		source = fantrax.NewLiveSource(cfg.FantraxLeagueID)
+	}
+
+	transactions, err := source.GetTransactionsFromFantrax()
+	if err != nil {
+		return fmt.Errorf("failed to fetch transactions: %w", err)
+	}
+
+	store, err := bot.OpenStore(cfg, cliLogger(cfg))
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	known, err := store.Transactions().GetTransactionIDs()
+	if err != nil {
+		return fmt.Errorf("failed to read stored transactions: %w", err)
+	}
+
+	var added []fantraxmodels.Transaction
+	for _, tx := range transactions {
+		if !known[tx.ID] {
+			added = append(added, tx)
+			known[tx.ID] = true
+		}
+	}
+	if len(added) > 0 {
+		if err := store.Transactions().AddTransactions(added); err != nil {
+			return fmt.Errorf("failed to store transactions: %w", err)
+		}
+	}
+
+	fmt.Printf("Imported %d new transactions (%d already stored)\n", len(added), len(transactions)-len(added))
+	return nil
+}
+
+// runTransactionsList searches stored transactions with the !transactions
+// filters. Text output lists every match unless --page picks one page.
+func runTransactionsList(cfg *config.Config, args []string) error {
+	asJSON, args := takeFlag(args, "--json")
+	paged := false
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--page") {
+			paged = true
+		}
+	}
+
+	query, err := discord.ParseTransactionQuery(args)
+	if err != nil {
+		return err
+	}
+
+	// Read without the storage lock so listing works while the bot runs
+	allTransactions, err := storage.ReadTransactions(cfg.StorageBackend, cfg.StorageDir())
+	if err != nil {
+		return fmt.Errorf("failed to read transactions: %w", err)
+	}
+	matches := storage.FilterTransactions(allTransactions, query.Filter)
+
+	switch {
+	case asJSON:
+		return printJSON(matches)
+	case query.CSV:
+		return storage.WriteTransactionsCSV(os.Stdout, matches)
+	case len(matches) == 0:
+		return errors.New("No transactions found matching those filters.")
+	case paged:
+		totalPages := (len(matches) + discord.TransactionsPerPage - 1) / discord.TransactionsPerPage
+		if query.Page > totalPages {
+			return fmt.Errorf("Page %d is out of range, there are only %d pages.", query.Page, totalPages)
+		}
+		printEmbeds(discord.BuildTransactionsEmbed(matches, query, totalPages))
+	default:
+		for _, tx := range matches {
+			fmt.Println(command.PlainText(discord.FormatTransactionLine(tx)))
+		}
+		fmt.Printf("\n%d transactions\n", len(matches))
+	}
+	return nil
+}
+
+// cliLogger logs warnings to stderr, keeping stdout for command output
+func cliLogger(cfg *config.Config) *logger.Logger {
+	level := cfg.LogLevel
+	if level == "" || level == "info" {
+		level = "warn"
+	}
+	return logger.NewWithOutput(level, os.Stderr)
+}
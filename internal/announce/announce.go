@@ -0,0 +1,128 @@
+package announce
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pmurley/go-fantrax/models"
+	"github.com/pmurley/ulb-bot/internal/storage"
+)
+
+// Announcements finds the Discord messages that announced a transaction or
+// trade and acts on them, so features can follow up on an announcement
+// without re-posting it
+type Announcements struct {
+	session  *discordgo.Session
+	messages storage.MessageStore
+
+	// threadArchiveMinutes is the auto-archive duration for discussion
+	// threads; 0 disables them
+	threadArchiveMinutes int
+}
+
+// maxThreadNameLength is Discord's limit for a thread name
+const maxThreadNameLength = 100
+
+// New creates an announcement lookup backed by the given message store
+func New(session *discordgo.Session, messages storage.MessageStore, threadArchiveMinutes int) *Announcements {
+	return &Announcements{
+		session:              session,
+		messages:             messages,
+		threadArchiveMinutes: threadArchiveMinutes,
+	}
+}
+
+// Record stores that message announced the given transactions. Pieces of a
+// trade share the message and are also found by their trade group ID.
+func (a *Announcements) Record(message *discordgo.Message, transactions ...models.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	refs := make([]storage.MessageRef, 0, len(transactions))
+	for _, tx := range transactions {
+		refs = append(refs, storage.MessageRef{
+			TransactionID: tx.ID,
+			TradeGroupID:  tx.TradeGroupID,
+			ChannelID:     message.ChannelID,
+			MessageID:     message.ID,
+			PostedAt:      time.Now(),
+		})
+	}
+
+	return a.messages.AddMessageRefs(refs)
+}
+
+// ForTransaction returns every message that announced a transaction
+func (a *Announcements) ForTransaction(transactionID string) ([]storage.MessageRef, error) {
+	return a.messages.GetMessageRefs(transactionID)
+}
+
+// ForTradeGroup returns every message that announced a trade, one per channel
+func (a *Announcements) ForTradeGroup(tradeGroupID string) ([]storage.MessageRef, error) {
+	return a.messages.GetTradeGroupRefs(tradeGroupID)
+}
+
+// Reply posts a message in the announcement's channel as a reply to it
+func (a *Announcements) Reply(ref storage.MessageRef, message *discordgo.MessageSend) (*discordgo.Message, error) {
+	message.Reference = &discordgo.MessageReference{
+		MessageID: ref.MessageID,
+		ChannelID: ref.ChannelID,
+	}
+
+	reply, err := a.session.ChannelMessageSendComplex(ref.ChannelID, message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reply to announcement %s: %w", ref.MessageID, err)
+	}
+	return reply, nil
+}
+
+// EditEmbed fetches the announcement, lets edit change its first embed, and
+// saves the result. Announcements without an embed are left alone.
+func (a *Announcements) EditEmbed(ref storage.MessageRef, edit func(*discordgo.MessageEmbed)) error {
+	message, err := a.session.ChannelMessage(ref.ChannelID, ref.MessageID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch announcement %s: %w", ref.MessageID, err)
+	}
+	if len(message.Embeds) == 0 {
+		return nil
+	}
+
+	embed := message.Embeds[0]
+	edit(embed)
+
+	if _, err := a.session.ChannelMessageEditEmbed(ref.ChannelID, ref.MessageID, embed); err != nil {
+		return fmt.Errorf("failed to edit announcement %s: %w", ref.MessageID, err)
+	}
+	return nil
+}
+
+// React adds an emoji reaction to the announcement
+func (a *Announcements) React(ref storage.MessageRef, emoji string) error {
+	if err := a.session.MessageReactionAdd(ref.ChannelID, ref.MessageID, emoji); err != nil {
+		return fmt.Errorf("failed to react to announcement %s: %w", ref.MessageID, err)
+	}
+	return nil
+}
+
+// StartThread opens a discussion thread on a message and returns the
+// thread's channel ID. It returns "" without error when threads are disabled.
+func (a *Announcements) StartThread(channelID, messageID, name string) (string, error) {
+	if a.threadArchiveMinutes == 0 {
+		return "", nil
+	}
+
+	if runes := []rune(name); len(runes) > maxThreadNameLength {
+		name = string(runes[:maxThreadNameLength-1]) + "…"
+	}
+
+	thread, err := a.session.MessageThreadStartComplex(channelID, messageID, &discordgo.ThreadStart{
+		Name:                name,
+		AutoArchiveDuration: a.threadArchiveMinutes,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to start thread on message %s: %w", messageID, err)
+	}
+	return thread.ID, nil
+}
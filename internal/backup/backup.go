@@ -0,0 +1,262 @@
+package backup
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pmurley/ulb-bot/internal/storage"
+)
+
+// manifestName is the first entry of every archive
+const manifestName = "manifest.json"
+
+// manifestVersion is bumped when the archive layout changes
+const manifestVersion = 1
+
+// Manifest describes the contents of a backup archive
+type Manifest struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	Files     []File    `json:"files"`
+}
+
+// File is one state file in a backup archive
+type File struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// Size returns the total size of the backed up files
+func (m *Manifest) Size() int64 {
+	var total int64
+	for _, f := range m.Files {
+		total += f.Size
+	}
+	return total
+}
+
+// Write archives the state files in dataDir to w as a gzipped tar with a
+// manifest of checksums. When store is a running bolt store its database is
+// read through a transaction; otherwise the database must not be open
+// elsewhere. CSV files are always replaced atomically, so reading them while
+// the bot runs sees a complete file.
+func Write(w io.Writer, dataDir string, store storage.Store) (*Manifest, error) {
+	manifest := &Manifest{Version: manifestVersion, CreatedAt: time.Now()}
+	contents := make(map[string][]byte)
+
+	for _, name := range storage.StateFiles() {
+		data, err := readStateFile(dataDir, name, store)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		sum := sha256.Sum256(data)
+		manifest.Files = append(manifest.Files, File{Name: name, Size: int64(len(data)), SHA256: hex.EncodeToString(sum[:])})
+		contents[name] = data
+	}
+	if len(manifest.Files) == 0 {
+		return nil, fmt.Errorf("no storage files found in %s", dataDir)
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	if err := writeEntry(tw, manifestName, manifestData, manifest.CreatedAt); err != nil {
+		return nil, err
+	}
+	for _, f := range manifest.Files {
+		if err := writeEntry(tw, f.Name, contents[f.Name], manifest.CreatedAt); err != nil {
+			return nil, err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+
+	return manifest, nil
+}
+
+// readStateFile reads one state file, snapshotting the bolt database
+func readStateFile(dataDir, name string, store storage.Store) ([]byte, error) {
+	path := filepath.Join(dataDir, name)
+	if name != storage.BoltFileName {
+		data, err := os.ReadFile(path)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		return data, err
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	var err error
+	if bolt, ok := store.(*storage.BoltStore); ok {
+		err = bolt.Snapshot(&buf)
+	} else {
+		err = storage.SnapshotBolt(dataDir, &buf)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to snapshot %s: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeEntry adds a regular file to the archive
+func writeEntry(tw *tar.Writer, name string, data []byte, modTime time.Time) error {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	}
+	if err := tw.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", name, err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", name, err)
+	}
+	return nil
+}
+
+// Read loads an archive and checks every file against its manifest. Nothing
+// is returned unless the whole archive is intact.
+func Read(r io.Reader) (*Manifest, map[string][]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer gz.Close()
+
+	known := make(map[string]bool)
+	for _, name := range storage.StateFiles() {
+		known[name] = true
+	}
+
+	var manifest *Manifest
+	contents := make(map[string][]byte)
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s from archive: %w", header.Name, err)
+		}
+
+		switch {
+		case header.Name == manifestName:
+			manifest = &Manifest{}
+			if err := json.Unmarshal(data, manifest); err != nil {
+				return nil, nil, fmt.Errorf("failed to parse manifest: %w", err)
+			}
+		case known[header.Name]:
+			contents[header.Name] = data
+		default:
+			return nil, nil, fmt.Errorf("archive contains unexpected file %q", header.Name)
+		}
+	}
+
+	if manifest == nil {
+		return nil, nil, errors.New("archive has no manifest")
+	}
+	if manifest.Version > manifestVersion {
+		return nil, nil, fmt.Errorf("archive version %d is newer than this build supports (%d)", manifest.Version, manifestVersion)
+	}
+	if len(contents) != len(manifest.Files) {
+		return nil, nil, fmt.Errorf("archive has %d files but its manifest lists %d", len(contents), len(manifest.Files))
+	}
+	for _, f := range manifest.Files {
+		data, ok := contents[f.Name]
+		if !ok {
+			return nil, nil, fmt.Errorf("archive is missing %s", f.Name)
+		}
+		sum := sha256.Sum256(data)
+		if int64(len(data)) != f.Size || hex.EncodeToString(sum[:]) != f.SHA256 {
+			return nil, nil, fmt.Errorf("checksum mismatch for %s", f.Name)
+		}
+	}
+
+	return manifest, contents, nil
+}
+
+// Restore replaces the state files in dataDir with those in the archive at
+// path. The data directory is locked for the duration, so it fails while the
+// bot is running. The current state is saved to backupDir first; files the
+// archive does not contain are left alone.
+func Restore(path, dataDir, backupDir string) (*Manifest, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	manifest, contents, err := Read(file)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, "", fmt.Errorf("failed to create data directory: %w", err)
+	}
+	unlock, err := storage.LockDir(dataDir)
+	if err != nil {
+		return nil, "", err
+	}
+	defer unlock()
+
+	// Keep what is being replaced, unless there is nothing yet
+	var previous string
+	if hasStateFiles(dataDir) {
+		if previous, _, err = Save(backupDir, "pre-restore", dataDir, nil); err != nil {
+			return nil, "", fmt.Errorf("failed to back up current data: %w", err)
+		}
+	}
+
+	for _, f := range manifest.Files {
+		if err := storage.ReplaceFile(filepath.Join(dataDir, f.Name), contents[f.Name]); err != nil {
+			return nil, previous, fmt.Errorf("failed to restore %s: %w", f.Name, err)
+		}
+	}
+
+	return manifest, previous, nil
+}
+
+// hasStateFiles reports whether dataDir holds any state files
+func hasStateFiles(dataDir string) bool {
+	for _, name := range storage.StateFiles() {
+		if _, err := os.Stat(filepath.Join(dataDir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pmurley/ulb-bot/internal/storage"
+)
+
+// Labels for archives saved by the bot itself
+const (
+	LabelScheduled  = "scheduled"
+	LabelManual     = "manual"
+	LabelPreRestore = "pre-restore"
+)
+
+const (
+	archivePrefix = "ulb-"
+	archiveSuffix = ".tar.gz"
+	archiveTime   = "20060102-150405"
+)
+
+// FileName returns the archive name for a backup with the given label made at t
+func FileName(label string, t time.Time) string {
+	return archivePrefix + label + "-" + t.Format(archiveTime) + archiveSuffix
+}
+
+// Save writes a backup of dataDir into dir and returns its path. The archive
+// only appears under its final name once it is complete.
+func Save(dir, label, dataDir string, store storage.Store) (string, *Manifest, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, ".backup-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create backup file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	manifest, err := Write(tmp, dataDir, store)
+	if err != nil {
+		tmp.Close()
+		return "", nil, err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return "", nil, fmt.Errorf("failed to sync backup file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", nil, fmt.Errorf("failed to close backup file: %w", err)
+	}
+
+	path := filepath.Join(dir, FileName(label, manifest.CreatedAt))
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", nil, fmt.Errorf("failed to save backup: %w", err)
+	}
+
+	return path, manifest, nil
+}
+
+// List returns the archives in dir with the given label, oldest first
+func List(dir, label string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	prefix := archivePrefix + label + "-"
+	var paths []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, archiveSuffix) {
+			continue
+		}
+		if _, err := time.Parse(archiveTime, strings.TrimSuffix(strings.TrimPrefix(name, prefix), archiveSuffix)); err != nil {
+			continue
+		}
+		paths = append(paths, filepath.Join(dir, name))
+	}
+
+	// The timestamp is fixed width, so names sort chronologically
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Rotate deletes all but the newest keep archives with the given label and
+// returns the paths it removed. Archives with other labels are never touched,
+// and keep <= 0 keeps everything.
+func Rotate(dir, label string, keep int) ([]string, error) {
+	if keep <= 0 {
+		return nil, nil
+	}
+
+	paths, err := List(dir, label)
+	if err != nil || len(paths) <= keep {
+		return nil, err
+	}
+
+	var removed []string
+	for _, path := range paths[:len(paths)-keep] {
+		if err := os.Remove(path); err != nil {
+			return removed, fmt.Errorf("failed to remove old backup: %w", err)
+		}
+		removed = append(removed, path)
+	}
+	return removed, nil
+}
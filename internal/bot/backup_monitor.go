@@ -0,0 +1,55 @@
+package bot
+
+import (
+	"time"
+
+	"github.com/pmurley/ulb-bot/internal/backup"
+)
+
+// startBackupMonitor starts scheduled local backups of the storage files
+func (b *Bot) startBackupMonitor() {
+	if b.config.BackupInterval <= 0 {
+		b.logger.Info("Scheduled backups disabled")
+		return
+	}
+	go b.backupMonitorLoop()
+}
+
+// backupMonitorLoop backs up the storage files every interval, starting with
+// one at startup
+func (b *Bot) backupMonitorLoop() {
+	b.logger.Info("Starting backup monitor, saving to ", b.config.BackupsDir())
+	b.runBackup()
+
+	ticker := time.NewTicker(b.config.ScaleDuration(b.config.BackupInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.runBackup()
+		case <-b.stopChan:
+			b.logger.Info("Stopping backup monitor")
+			return
+		}
+	}
+}
+
+// runBackup saves one scheduled backup and deletes those past the retention limit
+func (b *Bot) runBackup() {
+	dir := b.config.BackupsDir()
+	path, manifest, err := backup.Save(dir, backup.LabelScheduled, b.config.StorageDir(), b.store)
+	if err != nil {
+		b.logger.Error("Scheduled backup failed:", err)
+		return
+	}
+	b.logger.Info("Saved backup of ", len(manifest.Files), " files to ", path)
+
+	removed, err := backup.Rotate(dir, backup.LabelScheduled, b.config.BackupKeep)
+	if err != nil {
+		b.logger.Error("Failed to rotate backups:", err)
+	}
+	if len(removed) > 0 {
+		b.logger.Info("Removed ", len(removed), " old backups")
+	}
+}
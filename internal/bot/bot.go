@@ -1,15 +1,28 @@
 package bot
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/pmurley/ulb-bot/internal/announce"
+	"github.com/pmurley/ulb-bot/internal/budget"
 	"github.com/pmurley/ulb-bot/internal/cache"
+	"github.com/pmurley/ulb-bot/internal/command"
 	"github.com/pmurley/ulb-bot/internal/config"
+	"github.com/pmurley/ulb-bot/internal/digest"
 	"github.com/pmurley/ulb-bot/internal/discord"
+	"github.com/pmurley/ulb-bot/internal/fantrax"
+	"github.com/pmurley/ulb-bot/internal/notify"
+	"github.com/pmurley/ulb-bot/internal/poll"
+	"github.com/pmurley/ulb-bot/internal/ratelimit"
+	"github.com/pmurley/ulb-bot/internal/reconcile"
+	"github.com/pmurley/ulb-bot/internal/routing"
 	"github.com/pmurley/ulb-bot/internal/sheets"
 	"github.com/pmurley/ulb-bot/internal/spotrac"
+	"github.com/pmurley/ulb-bot/internal/storage"
 	"github.com/pmurley/ulb-bot/pkg/logger"
 )
 
@@ -21,7 +34,24 @@ type Bot struct {
 	sheetsClient  *sheets.Client
 	spotracClient *spotrac.Client
 	handlers      *discord.HandlerManager
+	channelCache  *routing.ChannelCache
+	router        *routing.Router
+	notifier      *notify.Dispatcher
+	reconciler    *reconcile.Reconciler
+	announcements *announce.Announcements
+	store         storage.Store
+	auditLog      *command.AuditLog
 	stopChan      chan struct{}
+
+	// Where the transaction monitor reads transactions from, and its health
+	transactionSource fantrax.TransactionSource
+	transactionPoller *poll.Poller
+	// Transaction monitor state, only touched by the monitor goroutine
+	txState *transactionState
+	// Sinks still to be told about each expired waiver, by waiverRetryKey;
+	// empty once delivered. Only touched by the waiver monitor goroutine;
+	// lost on restart, when every sink is tried again.
+	waiverRetries map[string][]string
 }
 
 func New(cfg *config.Config, log *logger.Logger) (*Bot, error) {
@@ -30,8 +60,10 @@ func New(cfg *config.Config, log *logger.Logger) (*Bot, error) {
 		return nil, fmt.Errorf("failed to create Discord session: %w", err)
 	}
 
-	// Set intents - we need these for DMs and message content
-	session.Identify.Intents = discordgo.IntentsGuildMessages |
+	// Set intents - we need these for DMs and message content, plus guild
+	// events to keep the channel cache current
+	session.Identify.Intents = discordgo.IntentsGuilds |
+		discordgo.IntentsGuildMessages |
 		discordgo.IntentsDirectMessages |
 		discordgo.IntentsDirectMessageReactions |
 		discordgo.IntentsMessageContent
@@ -45,6 +77,36 @@ func New(cfg *config.Config, log *logger.Logger) (*Bot, error) {
 	log.Info("Creating Spotrac client")
 	spotracClient := spotrac.NewClient()
 
+	log.Info("Loading channel routing")
+	routingConfig, err := routing.LoadConfig(cfg.RoutingConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load routing config: %w", err)
+	}
+	channelCache := routing.NewChannelCache()
+
+	log.Info("Opening ", cfg.StorageBackend, " storage in ", cfg.StorageDir())
+	store, err := OpenStore(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	auditLog, err := command.OpenAuditLog(cfg.AuditLogPath())
+	if err != nil {
+		store.Close()
+		return nil, err
+	}
+
+	// Release the storage, audit log and any sinks if a later step fails
+	notifier := notify.NewDispatcher(log)
+	ready := false
+	defer func() {
+		if !ready {
+			notifier.Close()
+			auditLog.Close()
+			store.Close()
+		}
+	}()
+
 	log.Info("Creating bot")
 	b := &Bot{
 		session:       session,
@@ -53,15 +115,110 @@ func New(cfg *config.Config, log *logger.Logger) (*Bot, error) {
 		dataCache:     cache.New(cfg.CacheDuration),
 		sheetsClient:  sheetsClient,
 		spotracClient: spotracClient,
+		channelCache:  channelCache,
+		router:        routing.NewRouter(session, channelCache, routingConfig),
+		notifier:      notifier,
+		reconciler:    reconcile.NewReconciler(cfg.FantraxLeagueID),
+		announcements: announce.New(session, store.Messages(), cfg.ThreadArchiveMinutes),
+		store:         store,
+		auditLog:      auditLog,
 		stopChan:      make(chan struct{}),
+		waiverRetries: make(map[string][]string),
 	}
 
-	b.handlers = discord.NewHandlerManager(b.session, cfg, log, b.dataCache, sheetsClient, spotracClient)
+	b.transactionPoller = newTransactionPoller(cfg)
+	if cfg.FantraxScript != "" {
+		log.Warn("Replaying transactions from ", cfg.FantraxScript, " instead of Fantrax")
+		source, err := fantrax.NewScriptSource(cfg.FantraxScript, cfg.FantraxScriptSpeed)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load transaction script: %w", err)
+		}
+		b.transactionSource = source
+	} else {
+		b.transactionSource = fantrax.NewLiveSource(cfg.FantraxLeagueID)
+	}
 
+	log.Info("Setting up notification sinks")
+	notifyConfig, err := notify.LoadConfig(cfg.NotifyConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load notification config: %w", err)
+	}
+	b.notifier.Subscribe(&discordSink{bot: b}, notifyConfig.DiscordFilter())
+	if err := notifyConfig.Subscribe(b.notifier, log, cfg.StorageDir(), cfg.StagingMode); err != nil {
+		return nil, fmt.Errorf("failed to create notification sinks: %w", err)
+	}
+
+	// Fail at startup rather than on Monday if the digest template is broken
+	if _, err := digest.LoadTemplate(cfg.DigestTemplate); err != nil {
+		return nil, err
+	}
+	if _, err := budget.LoadConfig(cfg.BudgetConfig); err != nil {
+		return nil, err
+	}
+	rateLimits, err := ratelimit.LoadConfig(cfg.RateLimitConfig)
+	if err != nil {
+		return nil, err
+	}
+	b.dataCache.OnPlayersChanged(b.recordSheetChanges)
+
+	if cfg.StagingMode {
+		log.Warn("Staging mode enabled: all output goes to ", cfg.StagingChannel, ", data in ", cfg.StorageDir())
+		b.router.RedirectAll(cfg.StagingChannel)
+	}
+
+	b.handlers = discord.NewHandlerManager(b.session, cfg, log, b.dataCache, sheetsClient, spotracClient, b.router, b.reconciler, b.announcements, b.store, b.auditLog, ratelimit.New(rateLimits), b.transactionPoller)
+
+	ready = true
 	return b, nil
 }
 
+// OpenStore opens the configured storage backend. The first time the bolt
+// backend is used, records from the CSV files in the same directory are
+// imported into it.
+func OpenStore(cfg *config.Config, log *logger.Logger) (storage.Store, error) {
+	store, recoveries, err := storage.Open(cfg.StorageBackend, cfg.StorageDir())
+	for _, r := range recoveries {
+		log.Warn("Restored ", r.File, " from its backup: ", r.Problem)
+		if r.Corrupt != "" {
+			log.Warn("Corrupt copy of ", r.File, " kept at ", r.Corrupt)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage: %w", err)
+	}
+
+	if cfg.StorageBackend != storage.BackendBolt || !storage.HasCSVData(cfg.StorageDir()) {
+		return store, nil
+	}
+	imported, err := storage.CSVImported(store)
+	if err != nil {
+		store.Close()
+		return nil, fmt.Errorf("failed to check for an earlier CSV import: %w", err)
+	}
+	if imported {
+		return store, nil
+	}
+
+	result, err := storage.ImportCSV(cfg.StorageDir(), store)
+	switch {
+	case errors.Is(err, storage.ErrStoreNotEmpty):
+		// The database was filled before imports were recorded, or without
+		// the CSV files; importing now would duplicate records
+		log.Warn("Not importing CSV storage: the database already has records")
+		if err := storage.MarkCSVImported(store); err != nil {
+			log.Error("Failed to record CSV import:", err)
+		}
+	case err != nil:
+		store.Close()
+		return nil, fmt.Errorf("failed to import CSV storage: %w", err)
+	default:
+		log.Info("Imported CSV storage: ", result)
+	}
+	return store, nil
+}
+
 func (b *Bot) Start() error {
+	b.channelCache.RegisterHandlers(b.session)
 	b.handlers.RegisterHandlers()
 
 	if err := b.session.Open(); err != nil {
@@ -82,11 +239,29 @@ func (b *Bot) Start() error {
 	// Start transaction monitor
 	b.startTransactionMonitor()
 
+	// Start roster reconciliation
+	b.startReconcileMonitor()
+
+	// Start weekly digest
+	b.startDigestMonitor()
+
+	// Start scheduled backups
+	b.startBackupMonitor()
+
 	return nil
 }
 
 func (b *Bot) Stop() error {
 	close(b.stopChan)
+	if err := b.notifier.Close(); err != nil {
+		b.logger.Error("Failed to close notification sinks:", err)
+	}
+	if err := b.store.Close(); err != nil {
+		b.logger.Error("Failed to close storage:", err)
+	}
+	if err := b.auditLog.Close(); err != nil {
+		b.logger.Error("Failed to close audit log:", err)
+	}
 	return b.session.Close()
 }
 
@@ -126,3 +301,22 @@ func (b *Bot) loadData() error {
 	// Perform the actual load
 	return b.sheetsClient.LoadInitialData(b.dataCache)
 }
+
+// stagingMarker is prefixed to everything the bot posts in staging mode
+const stagingMarker = "🧪 [STAGING]"
+
+// markStagingEmbed prefixes an embed title with the staging marker when running in staging mode
+func (b *Bot) markStagingEmbed(embed *discordgo.MessageEmbed) *discordgo.MessageEmbed {
+	if b.config.StagingMode && embed != nil && !strings.HasPrefix(embed.Title, stagingMarker) {
+		embed.Title = stagingMarker + " " + embed.Title
+	}
+	return embed
+}
+
+// markStagingText prefixes a message with the staging marker when running in staging mode
+func (b *Bot) markStagingText(message string) string {
+	if b.config.StagingMode {
+		return stagingMarker + " " + message
+	}
+	return message
+}
@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"unicode/utf8"
+
+	"github.com/pmurley/ulb-bot/internal/command"
+	"github.com/pmurley/ulb-bot/internal/console"
+	"github.com/pmurley/ulb-bot/internal/sheets"
+)
+
+// RunConsole runs commands typed in a terminal through the same handlers as
+// Discord, without connecting to Discord or starting the monitors. Players
+// are read from sheetFile when it is set, and from Google Sheets otherwise.
+// Files sent by commands are saved in fileDir.
+//
+// The console is a dry run for Discord: reads still reach Discord, but every
+// post, edit or delete a command would make (editing an announcement, say)
+// is printed instead of sent.
+func (b *Bot) RunConsole(in io.Reader, out io.Writer, username, sheetFile, fileDir string) error {
+	b.session.Client = &http.Client{Transport: &dryRunTransport{out: out, next: http.DefaultTransport}}
+
+	if sheetFile != "" {
+		players, err := sheets.LoadPlayerPoolFile(sheetFile)
+		if err != nil {
+			return err
+		}
+		b.dataCache.SetPlayers(players)
+	} else if err := b.loadData(); err != nil {
+		return fmt.Errorf("failed to load player data: %w", err)
+	}
+
+	fmt.Fprintf(out, "ULB bot console as %s. Type commands with or without %q, \"exit\" to quit.\n", username, b.config.CommandPrefix)
+	fmt.Fprintln(out, "Discord is read only: posts, edits and deletes are printed, not sent.")
+	user := command.User{ID: "console", Name: username}
+	return console.Run(in, out, b.config.CommandPrefix, user, fileDir, b.handlers.Execute)
+}
+
+// maxDryRunBody is how much of a request body a dry run prints
+const maxDryRunBody = 500
+
+// dryRunTransport passes Discord reads through and prints every write
+// instead of sending it, answering with an empty object
+type dryRunTransport struct {
+	out  io.Writer
+	next http.RoundTripper
+}
+
+func (t *dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return t.next.RoundTrip(req)
+	}
+
+	fmt.Fprintf(t.out, "[dry run] %s %s\n", req.Method, req.URL.Path)
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		mediaType, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		switch {
+		case len(body) == 0:
+		case mediaType == "application/json":
+			text := string(body)
+			if utf8.RuneCountInString(text) > maxDryRunBody {
+				text = string([]rune(text)[:maxDryRunBody]) + "…"
+			}
+			fmt.Fprintln(t.out, "[dry run]   "+text)
+		default:
+			fmt.Fprintf(t.out, "[dry run]   (%d byte %s body)\n", len(body), mediaType)
+		}
+	}
+
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"application/json"}},
+		Body:       io.NopCloser(bytes.NewReader([]byte(`{"id":"dry-run"}`))),
+		Request:    req,
+	}, nil
+}
@@ -0,0 +1,91 @@
+package bot
+
+import (
+	"time"
+
+	"github.com/pmurley/ulb-bot/internal/discord"
+	"github.com/pmurley/ulb-bot/internal/models"
+	"github.com/pmurley/ulb-bot/internal/routing"
+)
+
+// startDigestMonitor starts the weekly digest schedule
+func (b *Bot) startDigestMonitor() {
+	if b.config.DigestHour < 0 {
+		b.logger.Info("Weekly digest disabled")
+		return
+	}
+	go b.digestMonitorLoop()
+}
+
+// digestMonitorLoop posts the digest every Monday at the configured hour
+func (b *Bot) digestMonitorLoop() {
+	for {
+		next := nextDigestTime(time.Now(), b.config.DigestHour)
+		b.logger.Info("Next weekly digest at ", next.Format(time.RFC1123))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+			b.postDigest(next)
+		case <-b.stopChan:
+			timer.Stop()
+			b.logger.Info("Stopping digest monitor")
+			return
+		}
+	}
+}
+
+// nextDigestTime returns the first Monday at hour:00 strictly after now
+func nextDigestTime(now time.Time, hour int) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, now.Location())
+	for next.Weekday() != time.Monday || !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// postDigest renders the week ending at end and posts it to the digest channel
+func (b *Bot) postDigest(end time.Time) {
+	// Without sheet data the digest still covers transactions and waivers
+	players, _ := b.dataCache.GetPlayers()
+
+	embed, err := discord.RenderDigest(b.store, b.config.DigestTemplate, players, end)
+	if err != nil {
+		b.logger.Error("Failed to build weekly digest:", err)
+		return
+	}
+
+	channelIDs := b.router.NamedChannels(routing.ChannelDigest)
+	if len(channelIDs) == 0 {
+		b.logger.Warn("No digest channel configured for the weekly digest")
+		return
+	}
+
+	embed = b.markStagingEmbed(embed)
+	for _, channelID := range channelIDs {
+		if _, err := b.session.ChannelMessageSendEmbed(channelID, embed); err != nil {
+			b.logger.Error("Failed to post weekly digest:", err)
+		}
+	}
+}
+
+// recordSheetChanges stores what changed for rostered players when the sheet
+// is reloaded, for the weekly digest
+func (b *Bot) recordSheetChanges(oldPlayers, newPlayers models.PlayerList) {
+	// An empty load means the sheet could not be read, not that everyone left
+	if len(newPlayers) == 0 {
+		return
+	}
+
+	now := time.Now()
+	changes := models.DiffPlayers(oldPlayers, newPlayers, now.Year())
+	if len(changes) == 0 {
+		return
+	}
+
+	if err := b.store.SheetChanges().AddChanges(now, changes); err != nil {
+		b.logger.Error("Failed to store sheet changes:", err)
+		return
+	}
+	b.logger.Info("Detected ", len(changes), " sheet changes")
+}
@@ -0,0 +1,35 @@
+package bot
+
+import (
+	"fmt"
+
+	"github.com/pmurley/ulb-bot/internal/notify"
+)
+
+// discordSink delivers notifications as the bot's Discord posts
+type discordSink struct {
+	bot *Bot
+}
+
+func (d *discordSink) Name() string {
+	return notify.TypeDiscord
+}
+
+// Notify posts the event to the channels it is routed to
+func (d *discordSink) Notify(event notify.Event) error {
+	switch event.Kind {
+	case notify.EventTransaction:
+		for _, tx := range event.Transactions {
+			if err := d.bot.postTransactionToDiscord(tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	case notify.EventTrade:
+		return d.bot.postTradeToDiscord(event.Transactions)
+	case notify.EventWaiverExpired:
+		return d.bot.postWaiverExpiredToDiscord(event.Waiver)
+	default:
+		return fmt.Errorf("unsupported event kind: %s", event.Kind)
+	}
+}
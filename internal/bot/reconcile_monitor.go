@@ -0,0 +1,78 @@
+package bot
+
+import (
+	"time"
+
+	"github.com/pmurley/ulb-bot/internal/discord"
+	"github.com/pmurley/ulb-bot/internal/routing"
+)
+
+// reconcileStartupDelay gives the sheet time to load before the first check
+const reconcileStartupDelay = 5 * time.Minute
+
+// startReconcileMonitor starts the scheduled Fantrax/sheet reconciliation
+func (b *Bot) startReconcileMonitor() {
+	if b.config.ReconcileInterval <= 0 {
+		b.logger.Info("Roster reconciliation disabled")
+		return
+	}
+	go b.reconcileMonitorLoop()
+}
+
+// reconcileMonitorLoop periodically compares Fantrax rosters with the sheet
+func (b *Bot) reconcileMonitorLoop() {
+	b.logger.Info("Starting roster reconciliation monitor")
+
+	select {
+	case <-time.After(b.config.ScaleDuration(reconcileStartupDelay)):
+		b.runReconciliation()
+	case <-b.stopChan:
+		return
+	}
+
+	ticker := time.NewTicker(b.config.ScaleDuration(b.config.ReconcileInterval))
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.runReconciliation()
+		case <-b.stopChan:
+			b.logger.Info("Stopping roster reconciliation monitor")
+			return
+		}
+	}
+}
+
+// runReconciliation compares rosters and posts any mismatches to the commissioner channel
+func (b *Bot) runReconciliation() {
+	players, found := b.dataCache.GetPlayers()
+	if !found {
+		b.logger.Warn("Skipping reconciliation: player data not loaded")
+		return
+	}
+
+	report, err := b.reconciler.Run(players)
+	if err != nil {
+		b.logger.Error("Reconciliation failed:", err)
+		return
+	}
+
+	b.logger.Info("Reconciliation found ", len(report.Mismatches), " mismatches")
+	if len(report.Mismatches) == 0 {
+		return
+	}
+
+	channelIDs := b.router.NamedChannels(routing.ChannelCommissioner)
+	if len(channelIDs) == 0 {
+		b.logger.Warn("No commissioner channel configured for reconciliation reports")
+		return
+	}
+
+	embed := b.markStagingEmbed(discord.BuildReconcileEmbed(report))
+	for _, channelID := range channelIDs {
+		if _, err := b.session.ChannelMessageSendEmbed(channelID, embed); err != nil {
+			b.logger.Error("Failed to post reconciliation report:", err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package bot
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pmurley/go-fantrax/models"
+	ulbmodels "github.com/pmurley/ulb-bot/internal/models"
+)
+
+// startThread opens a discussion thread on an announcement and returns its
+// ID, or "" if threads are disabled or Discord refused
+func (b *Bot) startThread(message *discordgo.Message, name string) string {
+	threadID, err := b.announcements.StartThread(message.ChannelID, message.ID, name)
+	if err != nil {
+		b.logger.Error("Failed to start discussion thread:", err)
+		return ""
+	}
+	return threadID
+}
+
+// tradeThreadName names a trade's thread after the teams involved, e.g.
+// "Trade: Berries ⇄ Ultra Athletes"
+func tradeThreadName(tradeTransactions []models.Transaction) string {
+	teamSet := make(map[string]bool)
+	for _, tx := range tradeTransactions {
+		teamSet[tx.FromTeamName] = true
+		teamSet[tx.ToTeamName] = true
+	}
+	delete(teamSet, "")
+
+	teams := make([]string, 0, len(teamSet))
+	for team := range teamSet {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+
+	if len(teams) == 2 {
+		return fmt.Sprintf("Trade: %s ⇄ %s", teams[0], teams[1])
+	}
+	return "Trade: " + strings.Join(teams, ", ")
+}
+
+// postClaimToWaiverThreads tells the threads of a player's DFA that they were
+// claimed, when the claim came in during the waiver period
+func (b *Bot) postClaimToWaiverThreads(tx models.Transaction) {
+	waivers, err := b.store.Waivers().GetAllWaivers()
+	if err != nil {
+		b.logger.Error("Failed to get waivers:", err)
+		return
+	}
+
+	name := ulbmodels.NormalizeName(tx.PlayerName)
+	posted := make(map[string]bool)
+	for _, waiver := range waivers {
+		if waiver.ThreadID == "" || posted[waiver.ThreadID] || ulbmodels.NormalizeName(waiver.PlayerName) != name {
+			continue
+		}
+		if tx.ProcessedDate.Before(waiver.StartTime) || tx.ProcessedDate.After(waiver.EndTime) {
+			continue
+		}
+		posted[waiver.ThreadID] = true
+
+		message := fmt.Sprintf("📋 **%s** was claimed by **%s**", tx.PlayerName, tx.TeamName)
+		if tx.BidAmount != "" {
+			message += fmt.Sprintf(" for $%s", tx.BidAmount)
+		}
+		if _, err := b.session.ChannelMessageSend(waiver.ThreadID, b.markStagingText(message)); err != nil {
+			b.logger.Error("Failed to post claim to waiver thread:", err)
+		}
+	}
+}
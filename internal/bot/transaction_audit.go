@@ -0,0 +1,191 @@
+package bot
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pmurley/go-fantrax/models"
+	"github.com/pmurley/ulb-bot/internal/storage"
+)
+
+// transactionAuditInterval is how often stored transactions are compared with
+// the full Fantrax history to catch reversals and edits
+const transactionAuditInterval = 15 * time.Minute
+
+// Markers added to announcements of transactions Fantrax later changed
+const (
+	reversedMarker = "↩️ REVERSED"
+	amendedMarker  = "✏️ AMENDED"
+	reversedColor  = 0x95a5a6 // Grey
+)
+
+// auditTransactions fetches the full history, records reversed and amended
+// transactions in storage and marks their Discord announcements
+func (b *Bot) auditTransactions(transactionStorage storage.TransactionStore) error {
+	fetched, err := b.transactionSource.GetTransactionsFromFantrax()
+	if err != nil {
+		return fmt.Errorf("failed to fetch transactions for audit: %w", err)
+	}
+
+	stored, err := transactionStorage.GetAllTransactions()
+	if err != nil {
+		return fmt.Errorf("failed to read stored transactions for audit: %w", err)
+	}
+
+	// A truncated response would make most of the history look reversed
+	if len(fetched) < len(stored)/2 {
+		b.logger.Warn("Skipping transaction audit: Fantrax returned ", len(fetched), " transactions but ", len(stored), " are stored")
+		return nil
+	}
+
+	diff := storage.DiffTransactions(stored, fetched)
+
+	if len(diff.Backfilled) > 0 {
+		if err := transactionStorage.UpdateTransactions(diff.Backfilled, ""); err != nil {
+			return fmt.Errorf("failed to backfill transactions: %w", err)
+		}
+		b.logger.Info("Backfilled ", len(diff.Backfilled), " stored transactions")
+	}
+
+	if len(diff.Amended) > 0 {
+		updates := make([]models.Transaction, 0, len(diff.Amended))
+		for _, a := range diff.Amended {
+			updates = append(updates, a.New)
+		}
+		if err := transactionStorage.UpdateTransactions(updates, storage.TransactionStatusAmended); err != nil {
+			return fmt.Errorf("failed to store amended transactions: %w", err)
+		}
+		for _, a := range diff.Amended {
+			b.logger.Info("Transaction ", a.Old.ID, " (", a.Old.PlayerName, ") was amended in Fantrax")
+			b.markAnnouncements(a.Old.ID, amendedMarker, 0, "Amended in Fantrax: "+describeChanges(a.Changes))
+		}
+	}
+
+	if len(diff.Reversed) > 0 {
+		ids := make([]string, 0, len(diff.Reversed))
+		for _, tx := range diff.Reversed {
+			ids = append(ids, tx.ID)
+		}
+		if err := transactionStorage.MarkTransactions(ids, storage.TransactionStatusReversed); err != nil {
+			return fmt.Errorf("failed to store reversed transactions: %w", err)
+		}
+
+		// Trade pieces share one announcement, so only mark it once
+		marked := make(map[string]bool)
+		for _, tx := range diff.Reversed {
+			b.logger.Info("Transaction ", tx.ID, " (", tx.Type, " ", tx.PlayerName, ") was reversed in Fantrax")
+			key := tx.ID
+			if tx.TradeGroupID != "" {
+				key = tx.TradeGroupID
+			}
+			if marked[key] {
+				continue
+			}
+			marked[key] = true
+			b.markAnnouncements(tx.ID, reversedMarker, reversedColor, "Reversed in Fantrax")
+		}
+		b.cancelWaivers(diff.Reversed)
+	}
+
+	return nil
+}
+
+// cancelWaivers cancels the waivers opened by reversed DROPs, so no expiry
+// notice goes out for a player who was never dropped. Automatic
+// waivers are keyed by the message that announced the DROP.
+func (b *Bot) cancelWaivers(reversed []models.Transaction) {
+	announced := make(map[string]bool)
+	for _, tx := range reversed {
+		if tx.Type != "DROP" {
+			continue
+		}
+		refs, err := b.announcements.ForTransaction(tx.ID)
+		if err != nil {
+			b.logger.Error("Failed to look up announcement for transaction", tx.ID, ":", err)
+			continue
+		}
+		for _, ref := range refs {
+			announced[ref.MessageID] = true
+		}
+	}
+	if len(announced) == 0 {
+		return
+	}
+
+	waivers, err := b.store.Waivers().GetActiveWaivers()
+	if err != nil {
+		b.logger.Error("Failed to get active waivers:", err)
+		return
+	}
+	cancelled := make(map[string]bool)
+	for _, waiver := range waivers {
+		if !announced[waiver.MessageID] || cancelled[waiver.MessageID] {
+			continue
+		}
+		cancelled[waiver.MessageID] = true
+		if err := b.store.Waivers().CancelWaiver(waiver.MessageID); err != nil {
+			b.logger.Error("Failed to cancel waiver for reversed drop of", waiver.PlayerName, ":", err)
+			continue
+		}
+		b.logger.Info("Cancelled waiver for ", waiver.PlayerName, ": the drop was reversed in Fantrax")
+	}
+}
+
+// markAnnouncements edits every Discord message that announced a transaction,
+// prefixing the title with a marker and adding a note. A zero color keeps the
+// embed's color.
+func (b *Bot) markAnnouncements(transactionID, marker string, color int, note string) {
+	refs, err := b.announcements.ForTransaction(transactionID)
+	if err != nil {
+		b.logger.Error("Failed to look up announcement for transaction", transactionID, ":", err)
+		return
+	}
+	if len(refs) == 0 {
+		b.logger.Debug("No stored announcement for transaction ", transactionID)
+		return
+	}
+
+	note = fmt.Sprintf("%s (detected <t:%d:f>)", note, time.Now().Unix())
+	for _, ref := range refs {
+		err := b.announcements.EditEmbed(ref, func(embed *discordgo.MessageEmbed) {
+			if !strings.Contains(embed.Title, marker) {
+				embed.Title = marker + " " + embed.Title
+			}
+			if color != 0 {
+				embed.Color = color
+			}
+			embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+				Name:   "Update",
+				Value:  note,
+				Inline: false,
+			})
+		})
+		if err != nil {
+			b.logger.Error("Failed to mark announcement:", err)
+		}
+	}
+}
+
+// describeChanges formats field changes as "Bid: $5 → $7"-style text
+func describeChanges(changes []storage.FieldChange) string {
+	parts := make([]string, 0, len(changes))
+	for _, c := range changes {
+		oldValue, newValue := formatChangeValue(c.Field, c.Old), formatChangeValue(c.Field, c.New)
+		parts = append(parts, fmt.Sprintf("%s: %s → %s", c.Field, oldValue, newValue))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatChangeValue formats one side of a field change
+func formatChangeValue(field, value string) string {
+	switch {
+	case value == "":
+		return "none"
+	case field == "Bid":
+		return "$" + value
+	default:
+		return value
+	}
+}
@@ -2,52 +2,105 @@ package bot
 
 import (
 	"fmt"
-	"os"
 	"strings"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/pmurley/go-fantrax/models"
+	"github.com/pmurley/ulb-bot/internal/budget"
+	"github.com/pmurley/ulb-bot/internal/config"
+	"github.com/pmurley/ulb-bot/internal/discord"
 	"github.com/pmurley/ulb-bot/internal/fantrax"
 	ulbmodels "github.com/pmurley/ulb-bot/internal/models"
-	"github.com/pmurley/ulb-bot/internal/storage"
+	"github.com/pmurley/ulb-bot/internal/notify"
+	"github.com/pmurley/ulb-bot/internal/poll"
 )
 
 const (
 	transactionCheckInterval = 1 * time.Minute
-	waiverChannelName        = "dfa-waivers"
-	tradeChannelName         = "trades"
-	promotionsChannelName    = "40-man-promotions"
-	signingsChannelName      = "signings"
 	waiverDuration           = 8 * 24 * time.Hour // 8 days
-	//waiverDuration        = 8 * 24 * time.Hour // 8 days
-	//transactionCheckInterval = 11 * time.Second
-	//waiverChannelName        = "bot-testing"
-	//tradeChannelName         = "bot-testing"
-	//promotionsChannelName    = "bot-testing"
-	//signingsChannelName      = "bot-testing"
-	//waiverDuration           = 30 * time.Second // 8 days
+
+	// Backoff and circuit breaker settings for Fantrax polling
+	transactionMaxBackoff       = 30 * time.Minute
+	transactionBreakerThreshold = 5
+	transactionBreakerCooldown  = 15 * time.Minute
 )
 
+// transactionState is the transaction monitor's in-memory view of what has
+// already been processed, so polls never re-read the storage file
+type transactionState struct {
+	initialized      bool // Storage held history when the monitor started, or has been seeded since
+	knownTxIDs       map[string]bool
+	knownTradeGroups map[string]bool
+	lastAudit        time.Time // Last full comparison against Fantrax
+}
+
+// newTransactionPoller creates the health tracker for Fantrax polling
+func newTransactionPoller(cfg *config.Config) *poll.Poller {
+	return poll.New("Fantrax transactions", poll.Config{
+		Interval:         cfg.ScaleDuration(transactionCheckInterval),
+		MaxBackoff:       cfg.ScaleDuration(transactionMaxBackoff),
+		BreakerThreshold: transactionBreakerThreshold,
+		BreakerCooldown:  cfg.ScaleDuration(transactionBreakerCooldown),
+	})
+}
+
 // startTransactionMonitor starts the background transaction monitoring process
 func (b *Bot) startTransactionMonitor() {
+	b.migrateMessageRefs()
 	go b.transactionMonitorLoop()
 }
 
-// transactionMonitorLoop runs in the background and checks for new transactions
+// migrateMessageRefs upgrades the stored announcement references, linking
+// DROP announcements posted before they were recorded via their waivers
+func (b *Bot) migrateMessageRefs() {
+	transactions, err := b.store.Transactions().GetAllTransactions()
+	if err != nil {
+		b.logger.Error("Failed to read transactions for message migration:", err)
+		return
+	}
+
+	waivers, err := b.store.Waivers().GetAllWaivers()
+	if err != nil {
+		b.logger.Error("Failed to read waivers for message migration:", err)
+		return
+	}
+
+	migrated, err := b.store.Messages().Migrate(transactions, waivers)
+	if err != nil {
+		b.logger.Error("Failed to migrate message references:", err)
+		return
+	}
+	if migrated > 0 {
+		b.logger.Info("Migrated ", migrated, " announcement message references")
+	}
+}
+
+// transactionMonitorLoop runs in the background and checks for new transactions,
+// backing off when Fantrax polls fail
 func (b *Bot) transactionMonitorLoop() {
 	b.logger.Info("Starting transaction monitor")
 
-	// Initial check on startup
-	b.checkNewTransactions()
-
-	ticker := time.NewTicker(transactionCheckInterval)
-	defer ticker.Stop()
+	timer := time.NewTimer(0)
+	defer timer.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
-			b.checkNewTransactions()
+		case <-timer.C:
+			err := b.checkNewTransactions()
+			if err != nil {
+				b.logger.Error("Transaction poll failed:", err)
+			}
+
+			delay, circuitChanged := b.transactionPoller.Record(err)
+			if circuitChanged {
+				if b.transactionPoller.Health().CircuitOpen {
+					b.logger.Warn("Fantrax polling circuit opened after ", transactionBreakerThreshold, " failures, retrying in ", delay)
+				} else {
+					b.logger.Info("Fantrax polling recovered, circuit closed")
+				}
+			}
+			timer.Reset(delay)
 		case <-b.stopChan:
 			b.logger.Info("Stopping transaction monitor")
 			return
@@ -55,141 +108,221 @@ func (b *Bot) transactionMonitorLoop() {
 	}
 }
 
-// checkNewTransactions fetches transactions from Fantrax and posts new ones to Discord
-func (b *Bot) checkNewTransactions() {
-	// Create transaction storage instance
-	transactionStorage, err := storage.NewTransactionStorage()
-	if err != nil {
-		b.logger.Error("Failed to create transaction storage:", err)
-		return
-	}
-
-	// Check if this is the first run (empty CSV)
-	existingTransactions, err := transactionStorage.GetAllTransactions()
-	if err != nil {
-		b.logger.Error("Failed to get existing transactions:", err)
-		return
+// checkNewTransactions fetches transactions newer than the last processed ones
+// from Fantrax and notifies subscribers about them
+func (b *Bot) checkNewTransactions() error {
+	if b.txState == nil {
+		state, err := b.loadTransactionState()
+		if err != nil {
+			return err
+		}
+		b.txState = state
 	}
+	state := b.txState
 
-	isFirstRun := len(existingTransactions) == 0
-	if isFirstRun {
+	// First run (empty storage): record the history without notifying anyone
+	if !state.initialized {
 		b.logger.Info("First run detected - initializing transaction storage without Discord notifications")
-		b.initializeTransactionStorage(transactionStorage)
-		return
-	}
-
-	// Get existing transaction IDs and trade group IDs for quick lookup
-	existingTxIDs, err := transactionStorage.GetTransactionIDs()
-	if err != nil {
-		b.logger.Error("Failed to get existing transaction IDs:", err)
-		return
+		return b.initializeTransactionStorage(state)
 	}
 
-	existingTradeGroupIDs, err := transactionStorage.GetTradeGroupIDs()
+	claimsDrops, err := b.transactionSource.GetTransactionsUntil(fantrax.ViewClaimDrop, func(tx models.Transaction) bool {
+		return state.knownTxIDs[tx.ID]
+	})
 	if err != nil {
-		b.logger.Error("Failed to get existing trade group IDs:", err)
-		return
-	}
-
-	// Create Fantrax client
-	fantraxClient, err := fantrax.NewFantraxClient(os.Getenv("FANTRAX_LEAGUE_ID"), false)
-	if err != nil {
-		b.logger.Error("Failed to create Fantrax client:", err)
-		return
+		return err
 	}
 
-	// Fetch all transactions from Fantrax
-	allTransactions, err := fantraxClient.GetTransactionsFromFantrax()
+	trades, err := b.transactionSource.GetTransactionsUntil(fantrax.ViewTrade, func(tx models.Transaction) bool {
+		return state.knownTxIDs[tx.ID] || (tx.TradeGroupID != "" && state.knownTradeGroups[tx.TradeGroupID])
+	})
 	if err != nil {
-		b.logger.Error("Failed to fetch transactions from Fantrax:", err)
-		return
+		return err
 	}
 
 	// Filter for new transactions
 	var newTransactions []models.Transaction
 	var newTradeGroups = make(map[string][]models.Transaction)
+	var tradeGroupOrder []string
 
-	for _, tx := range allTransactions {
-		// For non-trade transactions, check if we've seen this transaction ID
-		if tx.Type != "TRADE" {
-			if !existingTxIDs[tx.ID] {
-				newTransactions = append(newTransactions, tx)
-			}
-		} else {
-			// For trade transactions, group by TradeGroupID and check if we've seen the group
-			if tx.TradeGroupID != "" && !existingTradeGroupIDs[tx.TradeGroupID] {
-				// Add to newTradeGroups - this automatically handles grouping multiple transactions
-				// with the same TradeGroupID together
-				newTradeGroups[tx.TradeGroupID] = append(newTradeGroups[tx.TradeGroupID], tx)
+	for _, tx := range claimsDrops {
+		if tx.Type != "TRADE" && !state.knownTxIDs[tx.ID] {
+			newTransactions = append(newTransactions, tx)
+		}
+	}
+	for _, tx := range trades {
+		// Group trade transactions by TradeGroupID so each deal is announced once
+		if tx.TradeGroupID != "" {
+			if _, exists := newTradeGroups[tx.TradeGroupID]; !exists {
+				tradeGroupOrder = append(tradeGroupOrder, tx.TradeGroupID)
 			}
+			newTradeGroups[tx.TradeGroupID] = append(newTradeGroups[tx.TradeGroupID], tx)
 		}
 	}
 
 	// Add individual new transactions (non-trades)
 	if len(newTransactions) > 0 {
-		if err := transactionStorage.AddTransactions(newTransactions); err != nil {
-			b.logger.Error("Failed to store new transactions:", err)
-			return
+		if err := b.store.Transactions().AddTransactions(newTransactions); err != nil {
+			return fmt.Errorf("failed to store new transactions: %w", err)
 		}
+		state.remember(newTransactions)
 
-		// Post each new transaction to Discord
+		// Notify subscribers of each new transaction
 		for _, tx := range newTransactions {
-			b.postTransactionToDiscord(tx)
+			b.notifier.Dispatch(notify.Event{
+				Kind:         notify.EventTransaction,
+				Time:         tx.ProcessedDate,
+				Transactions: []models.Transaction{tx},
+			})
 		}
 	}
 
 	// Add new trade groups
-	for tradeGroupID, tradeTransactions := range newTradeGroups {
-		if err := transactionStorage.AddTransactions(tradeTransactions); err != nil {
+	for _, tradeGroupID := range tradeGroupOrder {
+		tradeTransactions := newTradeGroups[tradeGroupID]
+		if err := b.store.Transactions().AddTransactions(tradeTransactions); err != nil {
 			b.logger.Error("Failed to store new trade transactions for group", tradeGroupID, ":", err)
 			continue
 		}
+		state.remember(tradeTransactions)
 
-		// Post the trade group to Discord
-		b.postTradeToDiscord(tradeTransactions)
+		// Notify subscribers of the trade group
+		b.notifier.Dispatch(notify.Event{
+			Kind:         notify.EventTrade,
+			Time:         tradeTransactions[0].ProcessedDate,
+			Transactions: tradeTransactions,
+		})
 	}
 
 	if len(newTransactions) > 0 || len(newTradeGroups) > 0 {
 		b.logger.Info("Processed ", len(newTransactions), " new transactions and ", len(newTradeGroups), " new trades")
 	}
-}
 
-// postTransactionToDiscord posts a single transaction to the appropriate channel
-func (b *Bot) postTransactionToDiscord(tx models.Transaction) {
-	channelName := b.getChannelForTransaction(tx)
-	channelID := b.findChannelByName(channelName)
-	if channelID == "" {
-		b.logger.Error("Could not find channel:", channelName)
-		return
+	// Incremental polls never see old transactions change, so compare the
+	// full history every so often. The new transactions are already handled,
+	// so a failed audit waits for the next interval rather than failing the
+	// poll.
+	if time.Since(state.lastAudit) >= b.config.ScaleDuration(transactionAuditInterval) {
+		state.lastAudit = time.Now()
+		if err := b.auditTransactions(b.store.Transactions()); err != nil {
+			b.logger.Error("Transaction audit failed:", err)
+		}
 	}
 
-	embed := b.createTransactionEmbed(tx)
+	return nil
+}
 
-	message, err := b.session.ChannelMessageSendEmbed(channelID, embed)
+// loadTransactionState reads the processed transaction and trade group IDs
+// from storage once, when the monitor starts
+func (b *Bot) loadTransactionState() (*transactionState, error) {
+	existingTxIDs, err := b.store.Transactions().GetTransactionIDs()
 	if err != nil {
-		b.logger.Error("Failed to send transaction message to Discord:", err)
-		return
+		return nil, fmt.Errorf("failed to get existing transaction IDs: %w", err)
+	}
+
+	existingTradeGroupIDs, err := b.store.Transactions().GetTradeGroupIDs()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get existing trade group IDs: %w", err)
+	}
+
+	return &transactionState{
+		initialized:      len(existingTxIDs) > 0,
+		knownTxIDs:       existingTxIDs,
+		knownTradeGroups: existingTradeGroupIDs,
+	}, nil
+}
+
+// remember marks transactions as processed
+func (s *transactionState) remember(txs []models.Transaction) {
+	for _, tx := range txs {
+		s.knownTxIDs[tx.ID] = true
+		if tx.Type == "TRADE" && tx.TradeGroupID != "" {
+			s.knownTradeGroups[tx.TradeGroupID] = true
+		}
+	}
+}
+
+// postTransactionToDiscord posts a single transaction to every channel it is routed to
+func (b *Bot) postTransactionToDiscord(tx models.Transaction) error {
+	channelIDs := b.router.TransactionChannels(tx)
+	if len(channelIDs) == 0 {
+		return fmt.Errorf("no channel routed for transaction %s (%s)", tx.ID, tx.Type)
 	}
 
-	// If this is a DROP transaction, create automatic waiver entries
+	embed := b.markStagingEmbed(b.createTransactionEmbed(tx))
+
+	var firstMessage *discordgo.Message
+	var firstThreadID string
+	var lastErr error
+	for _, channelID := range channelIDs {
+		message, err := b.session.ChannelMessageSendEmbed(channelID, embed)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to send transaction message to Discord: %w", err)
+			continue
+		}
+		b.recordAnnouncement(message, tx)
+
+		// Drops are DFAs, so each post gets a thread for the waiver period
+		threadID := ""
+		if tx.Type == "DROP" {
+			threadID = b.startThread(message, "DFA: "+tx.PlayerName)
+		}
+		if firstMessage == nil {
+			firstMessage = message
+			firstThreadID = threadID
+		}
+	}
+
+	if firstMessage == nil {
+		return lastErr
+	}
+
+	// If this is a DROP transaction, create automatic waiver entries that
+	// reply to the first announcement
 	if tx.Type == "DROP" {
-		b.createAutomaticWaiverEntries(tx, message.ID, channelID)
+		b.createAutomaticWaiverEntries(tx, firstMessage.ID, firstMessage.ChannelID, firstThreadID)
+	}
+
+	// A claim may end a DFA that is being discussed in a thread
+	if tx.Type == "CLAIM" {
+		b.postClaimToWaiverThreads(tx)
 	}
+
+	return lastErr
 }
 
-// postTradeToDiscord posts a trade group to the bot-testing channel
-func (b *Bot) postTradeToDiscord(tradeTransactions []models.Transaction) {
-	channelID := b.findChannelByName(tradeChannelName)
-	if channelID == "" {
-		b.logger.Error("Could not find bot-testing channel")
-		return
+// postTradeToDiscord posts a trade group to every channel it is routed to
+func (b *Bot) postTradeToDiscord(tradeTransactions []models.Transaction) error {
+	if len(tradeTransactions) == 0 {
+		return nil
 	}
 
-	embed := b.createTradeEmbed(tradeTransactions)
+	channelIDs := b.router.TransactionChannels(tradeTransactions...)
+	if len(channelIDs) == 0 {
+		return fmt.Errorf("no channel routed for trade group %s", tradeTransactions[0].TradeGroupID)
+	}
 
-	_, err := b.session.ChannelMessageSendEmbed(channelID, embed)
-	if err != nil {
-		b.logger.Error("Failed to send trade message to Discord:", err)
+	embed := b.markStagingEmbed(b.createTradeEmbed(tradeTransactions))
+
+	var lastErr error
+	for _, channelID := range channelIDs {
+		message, err := b.session.ChannelMessageSendEmbed(channelID, embed)
+		if err != nil {
+			lastErr = fmt.Errorf("failed to send trade message to Discord: %w", err)
+			continue
+		}
+		// Every piece of the trade points at the same announcement
+		b.recordAnnouncement(message, tradeTransactions...)
+		b.startThread(message, tradeThreadName(tradeTransactions))
+	}
+
+	return lastErr
+}
+
+// recordAnnouncement remembers which Discord message announced which transactions
+func (b *Bot) recordAnnouncement(message *discordgo.Message, transactions ...models.Transaction) {
+	if err := b.announcements.Record(message, transactions...); err != nil {
+		b.logger.Error("Failed to store message references:", err)
 	}
 }
 
@@ -218,6 +351,9 @@ func (b *Bot) createTransactionEmbed(tx models.Transaction) *discordgo.MessageEm
 					tx.TeamName, tx.PlayerName, tx.PlayerPosition, tx.PlayerTeam))
 				if tx.BidAmount != "" {
 					description.WriteString(fmt.Sprintf("\n💰 Bid Amount: $%s", tx.BidAmount))
+					if remaining, ok := b.remainingBudget(tx); ok {
+						description.WriteString(fmt.Sprintf("\n💼 Remaining Budget: %s", remaining))
+					}
 				}
 			}
 		case "WW": // Waiver Wire
@@ -295,133 +431,40 @@ func (b *Bot) createTradeEmbed(tradeTransactions []models.Transaction) *discordg
 		return nil
 	}
 
-	// Group players by team
-	teamPlayers := make(map[string][]models.Transaction)
-	for _, tx := range tradeTransactions {
-		teamPlayers[tx.FromTeamName] = append(teamPlayers[tx.FromTeamName], tx)
-	}
-
-	var description strings.Builder
-	description.WriteString("\n")
-
-	// Build trade description
-	teams := make([]string, 0, len(teamPlayers))
-	for team := range teamPlayers {
-		teams = append(teams, team)
-	}
-
-	for i, team := range teams {
-		if i > 0 {
-			description.WriteString("\n**↓ ↑**\n\n")
-		}
-
-		description.WriteString(fmt.Sprintf("**%s** traded:\n", team))
-		for _, tx := range teamPlayers[team] {
-			description.WriteString(fmt.Sprintf("• %s (%s - %s)\n",
-				tx.PlayerName, tx.PlayerPosition, tx.PlayerTeam))
-		}
-	}
-
-	embed := &discordgo.MessageEmbed{
-		Title:       "🔄 Trade Executed",
-		Description: description.String(),
-		Color:       0xffa500, // Orange
-		Timestamp:   tradeTransactions[0].ProcessedDate.Format(time.RFC3339),
-		Footer: &discordgo.MessageEmbedFooter{
-			Text: fmt.Sprintf("Period %d • %d players involved",
-				tradeTransactions[0].Period, len(tradeTransactions)),
-		},
-	}
-
-	if tradeTransactions[0].ExecutedBy != "" {
-		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
-			Name:   "Executed By",
-			Value:  tradeTransactions[0].ExecutedBy,
-			Inline: true,
-		})
-	}
+	// Without sheet data the announcement still lists the players
+	players, _ := b.dataCache.GetPlayers()
 
-	return embed
-}
-
-// findChannelByName finds a channel ID by name
-func (b *Bot) findChannelByName(channelName string) string {
-	for _, guild := range b.session.State.Guilds {
-		channels, err := b.session.GuildChannels(guild.ID)
-		if err != nil {
-			continue
-		}
-
-		for _, channel := range channels {
-			if channel.Name == channelName && channel.Type == discordgo.ChannelTypeGuildText {
-				return channel.ID
-			}
-		}
+	retentions, err := b.store.Retentions().GetRetentions(tradeTransactions[0].TradeGroupID)
+	if err != nil {
+		b.logger.Error("Failed to read trade retention:", err)
 	}
-	return ""
-}
-
-// getChannelForTransaction determines the appropriate Discord channel for a transaction
-func (b *Bot) getChannelForTransaction(tx models.Transaction) string {
-	// Check if executed by commissioner (case-insensitive)
-	isCommissioner := strings.ToLower(tx.ExecutedBy) == "commissioner"
 
-	switch tx.Type {
-	case "CLAIM":
-		switch tx.ClaimType {
-		case "FA": // Free Agent
-			if isCommissioner {
-				return promotionsChannelName // 40-man promotion
-			}
-			return signingsChannelName // Real free agent signing
-		case "WW": // Waiver Wire
-			if isCommissioner {
-				return promotionsChannelName // 40-man promotion
-			}
-			return waiverChannelName // True waiver claim
-		default:
-			// For backwards compatibility with old records without ClaimType
-			return waiverChannelName
-		}
-	case "DROP":
-		// All drops go to the same channel regardless of claim type
-		return waiverChannelName
-	case "TRADE":
-		return tradeChannelName
-	default:
-		// Default to waivers for unknown transaction types
-		return waiverChannelName
-	}
+	return discord.BuildTradeAnnouncementEmbed(tradeTransactions, players, retentions)
 }
 
-// initializeTransactionStorage populates the CSV with all historical transactions without posting to Discord
-func (b *Bot) initializeTransactionStorage(transactionStorage *storage.TransactionStorage) {
+// initializeTransactionStorage populates storage with all historical transactions without posting to Discord
+func (b *Bot) initializeTransactionStorage(state *transactionState) error {
 	b.logger.Info("Initializing transaction storage with historical data...")
 
-	// Create Fantrax client
-	fantraxClient, err := fantrax.NewFantraxClient(os.Getenv("FANTRAX_LEAGUE_ID"), false)
-	if err != nil {
-		b.logger.Error("Failed to create Fantrax client during initialization:", err)
-		return
-	}
-
 	// Fetch all historical transactions
-	allTransactions, err := fantraxClient.GetTransactionsFromFantrax()
+	allTransactions, err := b.transactionSource.GetTransactionsFromFantrax()
 	if err != nil {
-		b.logger.Error("Failed to fetch transactions during initialization:", err)
-		return
+		return fmt.Errorf("failed to fetch transactions during initialization: %w", err)
 	}
 
 	if len(allTransactions) == 0 {
 		b.logger.Info("No transactions found during initialization")
-		return
+		state.initialized = true
+		return nil
 	}
 
 	// Store all transactions without posting to Discord
-	if err := transactionStorage.AddTransactions(allTransactions); err != nil {
-		b.logger.Error("Failed to store transactions during initialization:", err)
-		return
+	if err := b.store.Transactions().AddTransactions(allTransactions); err != nil {
+		return fmt.Errorf("failed to store transactions during initialization: %w", err)
 	}
+	state.remember(allTransactions)
+	state.initialized = true
+	state.lastAudit = time.Now()
 
 	// Log summary of what was initialized
 	typeCount := make(map[string]int)
@@ -440,10 +483,12 @@ func (b *Bot) initializeTransactionStorage(transactionStorage *storage.Transacti
 	}
 	b.logger.Info("  Trade groups:", len(tradeGroups))
 	b.logger.Info("Future transaction monitoring will only post new transactions to Discord")
+
+	return nil
 }
 
 // createAutomaticWaiverEntries creates waiver entries for all owners of the team that dropped a player
-func (b *Bot) createAutomaticWaiverEntries(tx models.Transaction, messageID, channelID string) {
+func (b *Bot) createAutomaticWaiverEntries(tx models.Transaction, messageID, channelID, threadID string) {
 	// Get team owners (usernames)
 	teamOwnerUsernames := ulbmodels.GetTeamOwners(tx.TeamName)
 	if len(teamOwnerUsernames) == 0 {
@@ -451,13 +496,6 @@ func (b *Bot) createAutomaticWaiverEntries(tx models.Transaction, messageID, cha
 		return
 	}
 
-	// Create waiver storage instance
-	waiverStorage, err := storage.NewWaiverStorage()
-	if err != nil {
-		b.logger.Error("Failed to create waiver storage for automatic DFA:", err)
-		return
-	}
-
 	// Create waiver entries for each team owner
 	now := time.Now()
 	for _, ownerUsername := range teamOwnerUsernames {
@@ -469,16 +507,47 @@ func (b *Bot) createAutomaticWaiverEntries(tx models.Transaction, messageID, cha
 			TeamName:   tx.TeamName,
 			UserID:     userID,
 			StartTime:  now,
-			EndTime:    now.Add(waiverDuration),
+			EndTime:    now.Add(b.config.ScaleDuration(waiverDuration)),
 			MessageID:  messageID,
 			ChannelID:  channelID,
 			Processed:  false,
+			ThreadID:   threadID,
 		}
 
-		if err := waiverStorage.AddWaiver(waiver); err != nil {
+		if err := b.store.Waivers().AddWaiver(waiver); err != nil {
 			b.logger.Error("Failed to create automatic waiver entry for player", tx.PlayerName, "and owner", ownerUsername, ":", err)
 		} else {
 			b.logger.Info("Created automatic waiver entry for", tx.PlayerName, "owned by", ownerUsername)
 		}
 	}
 }
+
+// remainingBudget returns the signing team's bid budget left after a claim
+func (b *Bot) remainingBudget(tx models.Transaction) (string, bool) {
+	cfg, err := budget.LoadConfig(b.config.BudgetConfig)
+	if err != nil {
+		b.logger.Error("Failed to load budget config:", err)
+		return "", false
+	}
+
+	transactions, err := b.store.Transactions().GetAllTransactions()
+	if err != nil {
+		b.logger.Error("Failed to read transactions for budget:", err)
+		return "", false
+	}
+
+	// The claim is normally stored before it is announced, but count it once either way
+	stored := false
+	for _, existing := range transactions {
+		if existing.ID == tx.ID {
+			stored = true
+			break
+		}
+	}
+	if !stored {
+		transactions = append(transactions, tx)
+	}
+
+	teamBudget := cfg.ForTeam(transactions, cfg.CurrentSeason(), tx.TeamName)
+	return budget.Format(teamBudget.Remaining), true
+}
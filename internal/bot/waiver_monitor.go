@@ -1,12 +1,13 @@
 package bot
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/pmurley/ulb-bot/internal/models"
-	"github.com/pmurley/ulb-bot/internal/storage"
+	"github.com/pmurley/ulb-bot/internal/notify"
 )
 
 const waiverCheckInterval = 2 * time.Minute
@@ -23,7 +24,7 @@ func (b *Bot) waiverMonitorLoop() {
 	// Initial check on startup
 	b.checkExpiredWaivers()
 
-	ticker := time.NewTicker(waiverCheckInterval)
+	ticker := time.NewTicker(b.config.ScaleDuration(waiverCheckInterval))
 	defer ticker.Stop()
 
 	for {
@@ -41,35 +42,111 @@ func (b *Bot) waiverMonitorLoop() {
 func (b *Bot) checkExpiredWaivers() {
 	b.logger.Debug("Checking for expired waivers")
 
-	// Create waiver storage instance
-	waiverStorage, err := storage.NewWaiverStorage()
-	if err != nil {
-		b.logger.Error("Failed to create waiver storage:", err)
-		return
-	}
-
 	// Get all active waivers
-	activeWaivers, err := waiverStorage.GetActiveWaivers()
+	activeWaivers, err := b.store.Waivers().GetActiveWaivers()
 	if err != nil {
 		b.logger.Error("Failed to get active waivers:", err)
 		return
 	}
 
-	// Check each waiver
+	// Forget retries for waivers processed elsewhere, e.g. cancelled
+	active := make(map[string]bool, len(activeWaivers))
 	for _, waiver := range activeWaivers {
-		if waiver.IsExpired() {
-			b.processExpiredWaiver(waiver, waiverStorage)
+		active[waiverRetryKey(waiver)] = true
+	}
+	for key := range b.waiverRetries {
+		if !active[key] {
+			delete(b.waiverRetries, key)
+		}
+	}
+
+	// Check each waiver. Every owner of a team has a waiver for the same DFA
+	// message, and marking one processed marks them all, so that waits until
+	// every owner's notice is out.
+	pending := make(map[string]bool)
+	var expired []*models.Waiver
+	for _, waiver := range activeWaivers {
+		if !waiver.IsExpired() {
+			continue
+		}
+		expired = append(expired, waiver)
+		if !b.processExpiredWaiver(waiver) {
+			pending[waiver.MessageID] = true
+		}
+	}
+
+	marked := make(map[string]bool)
+	for _, waiver := range expired {
+		if pending[waiver.MessageID] {
+			continue
+		}
+		delete(b.waiverRetries, waiverRetryKey(waiver))
+		if marked[waiver.MessageID] {
+			continue
+		}
+		marked[waiver.MessageID] = true
+		if err := b.store.Waivers().MarkWaiverProcessed(waiver.MessageID); err != nil {
+			b.logger.Error("Failed to mark waiver as processed:", err)
 		}
 	}
 }
 
-// processExpiredWaiver handles an expired waiver
-func (b *Bot) processExpiredWaiver(waiver *models.Waiver, waiverStorage *storage.WaiverStorage) {
+// processExpiredWaiver tells the sinks a waiver has expired and reports
+// whether every sink has the notice. Sinks that fail are remembered, and the
+// next check retries just those, so the others are not notified twice.
+func (b *Bot) processExpiredWaiver(waiver *models.Waiver) bool {
+	key := waiverRetryKey(waiver)
+	sinks, retrying := b.waiverRetries[key]
+	if retrying && len(sinks) == 0 {
+		// Delivered on an earlier check, waiting for the other owners
+		return true
+	}
+
 	b.logger.Info("Processing expired waiver for player", waiver.PlayerName)
 
+	event := notify.Event{
+		Kind:   notify.EventWaiverExpired,
+		Time:   waiver.EndTime,
+		Waiver: waiver,
+	}
+
+	// Sink failures are logged by the dispatcher
+	var err error
+	if retrying {
+		err = b.notifier.DispatchTo(event, sinks)
+	} else {
+		err = b.notifier.Dispatch(event)
+	}
+	var delivery *notify.DeliveryError
+	if errors.As(err, &delivery) {
+		b.waiverRetries[key] = delivery.Sinks()
+		return false
+	}
+	b.waiverRetries[key] = []string{}
+	return true
+}
+
+// waiverRetryKey identifies one owner's waiver notice. Every owner of a team
+// shares the DFA message, so the message ID alone is not enough.
+func waiverRetryKey(waiver *models.Waiver) string {
+	return waiver.MessageID + "/" + waiver.UserID
+}
+
+// postWaiverExpiredToDiscord tells the owner the waiver period is over, in the
+// player's thread or as a reply to the original DFA message
+func (b *Bot) postWaiverExpiredToDiscord(waiver *models.Waiver) error {
 	// Create the notification message
-	message := fmt.Sprintf("<@%s> The waiver period has expired for %s -- Would you like to assign them to the minors?",
-		waiver.UserID, waiver.PlayerName)
+	message := b.markStagingText(fmt.Sprintf("<@%s> The waiver period has expired for %s -- Would you like to assign them to the minors?",
+		waiver.UserID, waiver.PlayerName))
+
+	// Keep the discussion in the player's thread when there is one
+	if waiver.ThreadID != "" {
+		_, err := b.session.ChannelMessageSend(waiver.ThreadID, message)
+		if err == nil {
+			return nil
+		}
+		b.logger.Warn("Failed to post waiver expiration to thread, replying instead:", err)
+	}
 
 	// Create a reference to the original message
 	reference := &discordgo.MessageReference{
@@ -79,12 +156,7 @@ func (b *Bot) processExpiredWaiver(waiver *models.Waiver, waiverStorage *storage
 
 	// Send the notification as a reply
 	if _, err := b.session.ChannelMessageSendReply(waiver.ChannelID, message, reference); err != nil {
-		b.logger.Error("Failed to send waiver expiration notification:", err)
-		return
-	}
-
-	// Mark the waiver as processed
-	if err := waiverStorage.MarkWaiverProcessed(waiver.MessageID); err != nil {
-		b.logger.Error("Failed to mark waiver as processed:", err)
+		return fmt.Errorf("failed to send waiver expiration notification: %w", err)
 	}
+	return nil
 }
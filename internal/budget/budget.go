@@ -0,0 +1,187 @@
+package budget
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pmurley/go-fantrax/models"
+)
+
+// DefaultStartingBudget is each team's budget when no config sets one
+const DefaultStartingBudget = 100
+
+// SeasonConfig is the bid budget for one season
+type SeasonConfig struct {
+	StartingBudget float64            `json:"starting_budget"`
+	Teams          map[string]float64 `json:"teams,omitempty"` // Per-team starting budgets that differ from the default
+}
+
+// Config holds the bid budget for every season, keyed by year
+type Config struct {
+	Seasons map[int]SeasonConfig `json:"seasons"`
+}
+
+// LoadConfig reads a budget config file. An empty path gives every team
+// DefaultStartingBudget.
+func LoadConfig(path string) (*Config, error) {
+	if path == "" {
+		return &Config{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read budget config: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse budget config: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+// CurrentSeason returns the latest season in the config, or the current year
+// when it lists none. Announcements and !budget both use it, so they always
+// agree on a team's budget.
+func (c *Config) CurrentSeason() int {
+	season := 0
+	for year := range c.Seasons {
+		if year > season {
+			season = year
+		}
+	}
+	if season == 0 {
+		return time.Now().Year()
+	}
+	return season
+}
+
+// StartingBudget returns a team's budget at the start of a season
+func (c *Config) StartingBudget(season int, team string) float64 {
+	sc, exists := c.Seasons[season]
+	if !exists {
+		return DefaultStartingBudget
+	}
+	for name, amount := range sc.Teams {
+		if strings.EqualFold(name, team) {
+			return amount
+		}
+	}
+	if sc.StartingBudget == 0 {
+		return DefaultStartingBudget
+	}
+	return sc.StartingBudget
+}
+
+// TeamBudget is a team's bid budget for a season
+type TeamBudget struct {
+	Team      string
+	Starting  float64
+	Spent     float64
+	Remaining float64
+	Claims    []models.Transaction // Claims with a bid, newest first
+}
+
+// Bid returns the numeric bid of a claim, or 0 if it has none
+func Bid(tx models.Transaction) float64 {
+	if tx.Type != "CLAIM" || tx.BidAmount == "" {
+		return 0
+	}
+	bid, err := strconv.ParseFloat(strings.TrimPrefix(tx.BidAmount, "$"), 64)
+	if err != nil {
+		return 0
+	}
+	return bid
+}
+
+// seasonBids returns the claims with a bid processed during a season
+func seasonBids(transactions []models.Transaction, season int) []models.Transaction {
+	var bids []models.Transaction
+	for _, tx := range transactions {
+		if tx.ProcessedDate.Year() == season && Bid(tx) > 0 {
+			bids = append(bids, tx)
+		}
+	}
+	return bids
+}
+
+// Compute returns the budget of every team that has a starting budget
+// override or made a bid, plus any extra teams given, sorted by name
+func (c *Config) Compute(transactions []models.Transaction, season int, teams ...string) []TeamBudget {
+	budgets := make(map[string]*TeamBudget)
+	budgetFor := func(team string) *TeamBudget {
+		key := strings.ToLower(team)
+		if b, exists := budgets[key]; exists {
+			return b
+		}
+		b := &TeamBudget{Team: team, Starting: c.StartingBudget(season, team)}
+		budgets[key] = b
+		return b
+	}
+
+	for _, team := range teams {
+		budgetFor(team)
+	}
+	for team := range c.Seasons[season].Teams {
+		budgetFor(team)
+	}
+	for _, tx := range seasonBids(transactions, season) {
+		b := budgetFor(tx.TeamName)
+		b.Spent += Bid(tx)
+		b.Claims = append(b.Claims, tx)
+	}
+
+	result := make([]TeamBudget, 0, len(budgets))
+	for _, b := range budgets {
+		b.Remaining = b.Starting - b.Spent
+		sort.SliceStable(b.Claims, func(i, j int) bool {
+			return b.Claims[i].ProcessedDate.After(b.Claims[j].ProcessedDate)
+		})
+		result = append(result, *b)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Team < result[j].Team
+	})
+	return result
+}
+
+// ForTeam returns one team's budget for a season
+func (c *Config) ForTeam(transactions []models.Transaction, season int, team string) TeamBudget {
+	for _, b := range c.Compute(transactions, season, team) {
+		if strings.EqualFold(b.Team, team) {
+			return b
+		}
+	}
+	return TeamBudget{Team: team, Starting: c.StartingBudget(season, team), Remaining: c.StartingBudget(season, team)}
+}
+
+// LargestBids returns the n biggest winning bids of a season, largest first
+func LargestBids(transactions []models.Transaction, season, n int) []models.Transaction {
+	bids := seasonBids(transactions, season)
+	sort.SliceStable(bids, func(i, j int) bool {
+		return Bid(bids[i]) > Bid(bids[j])
+	})
+	if len(bids) > n {
+		bids = bids[:n]
+	}
+	return bids
+}
+
+// Format formats a budget amount, e.g. 12.5 -> "$12.50" and 12 -> "$12"
+func Format(amount float64) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	if amount == float64(int64(amount)) {
+		return fmt.Sprintf("%s$%d", sign, int64(amount))
+	}
+	return fmt.Sprintf("%s$%.2f", sign, amount)
+}
@@ -13,6 +13,9 @@ type Cache struct {
 	mu           sync.RWMutex
 	isLoading    bool
 	lastLoadTime time.Time
+
+	// onPlayersChanged is called after a reload replaces earlier player data
+	onPlayersChanged func(oldPlayers, newPlayers models.PlayerList)
 }
 
 func New(duration time.Duration) *Cache {
@@ -22,18 +25,37 @@ func New(duration time.Duration) *Cache {
 }
 
 func (c *Cache) SetPlayers(players []models.Player) {
+	// Read the old players and swap in the new ones under one lock, so
+	// concurrent reloads each diff against the list they replaced
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
+	old, hadPlayers := c.players()
 	c.cache.Set("players", players, gocache.NoExpiration)
 	c.lastLoadTime = time.Now()
 	c.isLoading = false
+	onPlayersChanged := c.onPlayersChanged
+	c.mu.Unlock()
+
+	if hadPlayers && onPlayersChanged != nil {
+		onPlayersChanged(old, models.PlayerList(players))
+	}
+}
+
+// OnPlayersChanged registers a function called with the old and new players
+// whenever a reload replaces loaded player data
+func (c *Cache) OnPlayersChanged(fn func(oldPlayers, newPlayers models.PlayerList)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onPlayersChanged = fn
 }
 
 func (c *Cache) GetPlayers() (models.PlayerList, bool) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
+	return c.players()
+}
 
+// players returns the cached players; the caller holds c.mu
+func (c *Cache) players() (models.PlayerList, bool) {
 	if players, found := c.cache.Get("players"); found {
 		return models.PlayerList(players.([]models.Player)), true
 	}
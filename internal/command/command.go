@@ -0,0 +1,63 @@
+package command
+
+import (
+	"io"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Request is one command invocation, independent of the transport it arrived on
+type Request struct {
+	Name      string   // Command name without the prefix, lower case
+	Args      []string // Words after the command name
+	User      User     // Who sent the command
+	ChannelID string   // Discord channel the command was sent in; empty elsewhere
+	GuildID   string   // Discord server the command was sent in; empty elsewhere
+	MessageID string   // Discord message that contained the command; empty elsewhere
+}
+
+// User identifies who sent a command
+type User struct {
+	ID   string
+	Name string // Username, which commissioner and team owner checks use
+}
+
+// Responder sends a command's output back where the request came from. Each
+// call is delivered immediately, so long running commands can report progress.
+type Responder interface {
+	// Send sends a text message
+	Send(text string) error
+	// Reply sends a text message as a reply to the request
+	Reply(text string) error
+	// SendEmbeds sends one or more embeds
+	SendEmbeds(embeds ...*discordgo.MessageEmbed) error
+	// SendFile sends a file with an optional text message
+	SendFile(name, text string, r io.Reader) error
+	// SendPages sends output split into pages the user can page through
+	SendPages(pages []Page) error
+}
+
+// Page is one page of paged output
+type Page []*discordgo.MessageEmbed
+
+// Handler runs one command
+type Handler func(req *Request, res Responder)
+
+// Parse parses a message into a command request, or returns nil when the
+// message is not a command
+func Parse(prefix, content string) *Request {
+	if !strings.HasPrefix(content, prefix) {
+		return nil
+	}
+
+	parts := strings.Fields(strings.TrimPrefix(content, prefix))
+	if len(parts) == 0 {
+		return nil
+	}
+
+	return &Request{
+		Name: strings.ToLower(parts[0]),
+		Args: parts[1:],
+	}
+}
@@ -0,0 +1,201 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pmurley/ulb-bot/pkg/logger"
+)
+
+// Middleware wraps a handler with behaviour shared by every command
+type Middleware func(next Handler) Handler
+
+// Chain wraps h in middleware. The first middleware is the outermost, so it
+// sees the request first and the outcome last.
+func Chain(h Handler, middleware ...Middleware) Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+	return h
+}
+
+// Outcomes of a command, as recorded in the audit log
+const (
+	OutcomeOK    = "ok"
+	OutcomeError = "error"
+	OutcomePanic = "panic"
+	// OutcomeLimited is a command refused by a rate limit
+	OutcomeLimited = "limited"
+)
+
+// ErrRateLimited is recorded for commands refused by a rate limit
+var ErrRateLimited = errors.New("rate limited")
+
+// ErrorMessage formats a failure the same way for every command
+func ErrorMessage(message string, err error) string {
+	if err == nil {
+		return "❌ " + message
+	}
+	return fmt.Sprintf("❌ %s: %v", message, err)
+}
+
+// Fail tells the user a command failed and records the error as the
+// command's outcome
+func Fail(res Responder, message string, err error) {
+	Record(res, fmt.Errorf("%s: %w", message, err))
+	res.Send(ErrorMessage(message, err))
+}
+
+// Record sets the command's outcome to err without replying. Only the first
+// error of a command is kept.
+func Record(res Responder, err error) {
+	if t, ok := res.(*tracker); ok {
+		t.fail(err)
+	}
+}
+
+// panicError is recorded when a handler panics
+type panicError struct {
+	value interface{}
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
+
+// Recover turns a panicking handler into a friendly reply, logging the stack
+func Recover(log *logger.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request, res Responder) {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Error("Panic in command ", req.Name, ": ", r, "\n", string(debug.Stack()))
+					Record(res, &panicError{value: r})
+					res.Send(ErrorMessage("Something went wrong running that command. The error has been logged.", nil))
+				}
+			}()
+			next(req, res)
+		}
+	}
+}
+
+// slowCommand is how long a command may take before its latency is logged as
+// a warning
+const slowCommand = 5 * time.Second
+
+// Timing logs how long each command took
+func Timing(log *logger.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request, res Responder) {
+			start := time.Now()
+			next(req, res)
+			elapsed := time.Since(start).Round(time.Millisecond)
+			if elapsed >= slowCommand {
+				log.Warn("Command ", req.Name, " was slow: ", elapsed)
+			} else {
+				log.Debug("Command ", req.Name, " took ", elapsed)
+			}
+		}
+	}
+}
+
+// AuditEntry records who ran a command, with which arguments, and how it went
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	UserID    string    `json:"user_id"`
+	User      string    `json:"user"`
+	ChannelID string    `json:"channel_id,omitempty"`
+	Command   string    `json:"command"`
+	Args      []string  `json:"args"`
+	Duration  int64     `json:"duration_ms"`
+	Outcome   string    `json:"outcome"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Auditor records audit entries
+type Auditor interface {
+	Record(entry AuditEntry) error
+}
+
+// Audit records every command and its outcome. The outcome is an error when
+// the handler called Fail or a send failed, and limited or panic when a rate
+// limit refused the command or the handler panicked.
+func Audit(auditor Auditor, log *logger.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(req *Request, res Responder) {
+			t := &tracker{Responder: res}
+			start := time.Now()
+			next(req, t)
+
+			entry := AuditEntry{
+				Time:      start,
+				UserID:    req.User.ID,
+				User:      req.User.Name,
+				ChannelID: req.ChannelID,
+				Command:   req.Name,
+				Args:      req.Args,
+				Duration:  time.Since(start).Milliseconds(),
+				Outcome:   OutcomeOK,
+			}
+			var panicErr *panicError
+			switch {
+			case errors.As(t.err, &panicErr):
+				entry.Outcome = OutcomePanic
+				entry.Error = t.err.Error()
+			case errors.Is(t.err, ErrRateLimited):
+				entry.Outcome = OutcomeLimited
+				entry.Error = t.err.Error()
+			case t.err != nil:
+				entry.Outcome = OutcomeError
+				entry.Error = t.err.Error()
+			}
+
+			if err := auditor.Record(entry); err != nil {
+				log.Error("Failed to record command audit entry:", err)
+			}
+		}
+	}
+}
+
+// tracker passes responses through, remembering the first failure
+type tracker struct {
+	Responder
+	err error
+}
+
+func (t *tracker) fail(err error) {
+	if t.err == nil {
+		t.err = err
+	}
+}
+
+func (t *tracker) track(err error) error {
+	if err != nil {
+		t.fail(fmt.Errorf("failed to send response: %w", err))
+	}
+	return err
+}
+
+func (t *tracker) Send(text string) error {
+	return t.track(t.Responder.Send(text))
+}
+
+func (t *tracker) Reply(text string) error {
+	return t.track(t.Responder.Reply(text))
+}
+
+func (t *tracker) SendEmbeds(embeds ...*discordgo.MessageEmbed) error {
+	return t.track(t.Responder.SendEmbeds(embeds...))
+}
+
+func (t *tracker) SendFile(name, text string, r io.Reader) error {
+	return t.track(t.Responder.SendFile(name, text, r))
+}
+
+func (t *tracker) SendPages(pages []Page) error {
+	return t.track(t.Responder.SendPages(pages))
+}
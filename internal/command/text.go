@@ -0,0 +1,43 @@
+package command
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+var (
+	markdownMarkers = strings.NewReplacer("**", "", "__", "", "~~", "", "`", "")
+	markdownItalic  = regexp.MustCompile(`\*([^*\n]+)\*`)
+	channelMention  = regexp.MustCompile(`<#(\d+)>`)
+)
+
+// PlainText strips the Discord markdown used in command output
+func PlainText(s string) string {
+	s = channelMention.ReplaceAllString(s, "#$1")
+	return markdownItalic.ReplaceAllString(markdownMarkers.Replace(s), "$1")
+}
+
+// EmbedText renders an embed as plain text for terminals
+func EmbedText(embed *discordgo.MessageEmbed) string {
+	var b strings.Builder
+	if embed.Title != "" {
+		title := PlainText(embed.Title)
+		b.WriteString(title + "\n")
+		b.WriteString(strings.Repeat("=", len([]rune(title))) + "\n")
+	}
+	if embed.Description != "" {
+		b.WriteString(PlainText(embed.Description) + "\n")
+	}
+	for _, field := range embed.Fields {
+		b.WriteString("\n" + PlainText(field.Name) + ":\n")
+		for _, line := range strings.Split(strings.TrimRight(PlainText(field.Value), "\n"), "\n") {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+	if embed.Footer != nil && embed.Footer.Text != "" {
+		b.WriteString("\n" + PlainText(embed.Footer.Text) + "\n")
+	}
+	return b.String()
+}
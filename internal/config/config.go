@@ -2,10 +2,15 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 )
 
+// minStagingInterval keeps scaled timers from turning into busy loops
+const minStagingInterval = 10 * time.Second
+
 type Config struct {
 	DiscordToken   string
 	GoogleSheetsID string
@@ -13,6 +18,46 @@ type Config struct {
 	CacheDuration  time.Duration
 	CommandPrefix  string
 	LogLevel       string
+	RoutingConfig  string // Path to the channel routing rules (JSON); empty uses the defaults
+	NotifyConfig   string // Path to the notification subscribers (JSON); empty means Discord only
+	DataDir        string // Directory for the bot's own storage files
+	StorageBackend string // "csv" (default) or "bolt"
+
+	// Scheduled backups of the storage files; an interval of 0 disables them
+	BackupInterval time.Duration
+	BackupDir      string // Where archives are saved; empty means a backups directory in the storage directory
+	BackupKeep     int    // Scheduled archives kept before the oldest is deleted; 0 keeps all
+
+	AuditLog string // Where every command run is recorded (JSON lines); empty means commands.jsonl in the storage directory
+
+	FantraxLeagueID    string
+	FantraxScript      string  // Replay transactions from this JSON/CSV file instead of calling Fantrax
+	FantraxScriptSpeed float64 // Script delays are divided by this
+
+	// ReconcileInterval is how often Fantrax rosters are compared with the sheet; 0 disables
+	ReconcileInterval time.Duration
+
+	// ThreadArchiveMinutes is how long trade and DFA threads stay open after
+	// the last message; 0 disables threads
+	ThreadArchiveMinutes int
+
+	// Weekly digest, posted on Mondays at DigestHour local time; -1 disables
+	DigestHour     int
+	DigestTemplate string // Path to a text/template file; empty uses the built-in layout
+
+	BudgetConfig string // Path to the bid budget config (JSON); empty gives every team the default budget
+
+	RateLimitConfig string // Path to the command rate limits (JSON); empty uses the defaults
+
+	// Paged command output: how long pages stay available after the last
+	// button press, and whether anyone may page rather than only the invoking user
+	PaginationTTL    time.Duration
+	PaginationAnyone bool
+
+	// Staging mode redirects all bot output to a single test channel
+	StagingMode       bool
+	StagingChannel    string  // Channel name or ID that receives all output
+	StagingTimeFactor float64 // Waiver periods and monitor intervals are divided by this
 }
 
 func Load() (*Config, error) {
@@ -23,19 +68,159 @@ func Load() (*Config, error) {
 		}
 	}
 
+	scriptSpeed := 1.0
+	if f := os.Getenv("FANTRAX_SCRIPT_SPEED"); f != "" {
+		if speed, err := strconv.ParseFloat(f, 64); err == nil && speed > 0 {
+			scriptSpeed = speed
+		}
+	}
+
+	reconcileInterval := 24 * time.Hour
+	if h := os.Getenv("RECONCILE_INTERVAL_HOURS"); h != "" {
+		if hours, err := strconv.Atoi(h); err == nil && hours >= 0 {
+			reconcileInterval = time.Duration(hours) * time.Hour
+		}
+	}
+
+	threadArchiveMinutes := 1440
+	if m := os.Getenv("THREAD_ARCHIVE_MINUTES"); m != "" {
+		if minutes, err := strconv.Atoi(m); err == nil && minutes >= 0 {
+			threadArchiveMinutes = threadArchiveDuration(minutes)
+		}
+	}
+
+	backupInterval := 24 * time.Hour
+	if h := os.Getenv("BACKUP_INTERVAL_HOURS"); h != "" {
+		if hours, err := strconv.Atoi(h); err == nil && hours >= 0 {
+			backupInterval = time.Duration(hours) * time.Hour
+		}
+	}
+
+	backupKeep := 14
+	if k := os.Getenv("BACKUP_KEEP"); k != "" {
+		if keep, err := strconv.Atoi(k); err == nil && keep >= 0 {
+			backupKeep = keep
+		}
+	}
+
+	digestHour := 9
+	if h := os.Getenv("DIGEST_HOUR"); h != "" {
+		if hour, err := strconv.Atoi(h); err == nil && hour >= -1 && hour < 24 {
+			digestHour = hour
+		}
+	}
+
+	paginationTTL := 15 * time.Minute
+	if m := os.Getenv("PAGINATION_TTL_MINUTES"); m != "" {
+		if minutes, err := strconv.Atoi(m); err == nil && minutes > 0 {
+			paginationTTL = time.Duration(minutes) * time.Minute
+		}
+	}
+
+	stagingTimeFactor := 1.0
+	if f := os.Getenv("STAGING_TIME_FACTOR"); f != "" {
+		if factor, err := strconv.ParseFloat(f, 64); err == nil && factor > 0 {
+			stagingTimeFactor = factor
+		}
+	}
+
 	return &Config{
-		DiscordToken:   os.Getenv("DISCORD_TOKEN"),
-		GoogleSheetsID: os.Getenv("GOOGLE_SHEETS_ID"),
-		GoogleAPIKey:   os.Getenv("GOOGLE_API_KEY"),
-		CacheDuration:  cacheDuration,
-		CommandPrefix:  getEnvOrDefault("COMMAND_PREFIX", "!"),
-		LogLevel:       getEnvOrDefault("LOG_LEVEL", "info"),
+		DiscordToken:         os.Getenv("DISCORD_TOKEN"),
+		GoogleSheetsID:       os.Getenv("GOOGLE_SHEETS_ID"),
+		GoogleAPIKey:         os.Getenv("GOOGLE_API_KEY"),
+		CacheDuration:        cacheDuration,
+		CommandPrefix:        getEnvOrDefault("COMMAND_PREFIX", "!"),
+		LogLevel:             getEnvOrDefault("LOG_LEVEL", "info"),
+		RoutingConfig:        os.Getenv("ROUTING_CONFIG"),
+		NotifyConfig:         os.Getenv("NOTIFY_CONFIG"),
+		DataDir:              getEnvOrDefault("DATA_DIR", "./data"),
+		StorageBackend:       getEnvOrDefault("STORAGE_BACKEND", "csv"),
+		BackupInterval:       backupInterval,
+		BackupDir:            os.Getenv("BACKUP_DIR"),
+		BackupKeep:           backupKeep,
+		AuditLog:             os.Getenv("AUDIT_LOG"),
+		FantraxLeagueID:      os.Getenv("FANTRAX_LEAGUE_ID"),
+		FantraxScript:        os.Getenv("FANTRAX_SCRIPT"),
+		FantraxScriptSpeed:   scriptSpeed,
+		ReconcileInterval:    reconcileInterval,
+		ThreadArchiveMinutes: threadArchiveMinutes,
+		DigestHour:           digestHour,
+		DigestTemplate:       os.Getenv("DIGEST_TEMPLATE"),
+		BudgetConfig:         os.Getenv("BUDGET_CONFIG"),
+		RateLimitConfig:      os.Getenv("RATE_LIMIT_CONFIG"),
+		PaginationTTL:        paginationTTL,
+		PaginationAnyone:     parseBool(os.Getenv("PAGINATION_ANYONE")),
+		StagingMode:          parseBool(os.Getenv("STAGING_MODE")),
+		StagingChannel:       getEnvOrDefault("STAGING_CHANNEL", "bot-testing"),
+		StagingTimeFactor:    stagingTimeFactor,
 	}, nil
 }
 
+// threadArchiveDuration rounds minutes up to an auto-archive duration Discord
+// accepts (1 hour, 1 day, 3 days or 1 week), keeping 0 as disabled
+func threadArchiveDuration(minutes int) int {
+	if minutes == 0 {
+		return 0
+	}
+	for _, allowed := range []int{60, 1440, 4320} {
+		if minutes <= allowed {
+			return allowed
+		}
+	}
+	return 10080
+}
+
+// StorageDir returns the directory storage files live in. Staging keeps its
+// data in a subdirectory so it never touches production records.
+func (c *Config) StorageDir() string {
+	if c.StagingMode {
+		return filepath.Join(c.DataDir, "staging")
+	}
+	return c.DataDir
+}
+
+// BackupsDir returns the directory backup archives are saved in
+func (c *Config) BackupsDir() string {
+	if c.BackupDir != "" {
+		return c.BackupDir
+	}
+	return filepath.Join(c.StorageDir(), "backups")
+}
+
+// AuditLogPath returns the file commands are recorded in
+func (c *Config) AuditLogPath() string {
+	if c.AuditLog != "" {
+		return c.AuditLog
+	}
+	return filepath.Join(c.StorageDir(), "commands.jsonl")
+}
+
+// ScaleDuration shortens a timer by the staging time factor. Outside staging
+// mode the duration is returned unchanged.
+func (c *Config) ScaleDuration(d time.Duration) time.Duration {
+	if !c.StagingMode || c.StagingTimeFactor <= 1 {
+		return d
+	}
+
+	scaled := time.Duration(float64(d) / c.StagingTimeFactor)
+	if scaled < minStagingInterval {
+		return minStagingInterval
+	}
+	return scaled
+}
+
 func getEnvOrDefault(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
+
+func parseBool(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "true", "yes", "on":
+		return true
+	default:
+		return false
+	}
+}
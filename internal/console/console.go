@@ -0,0 +1,109 @@
+package console
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pmurley/ulb-bot/internal/command"
+)
+
+// Responder prints command output to a terminal, rendering embeds as text
+type Responder struct {
+	out     io.Writer
+	fileDir string
+}
+
+// NewResponder creates a responder that writes to out and saves files sent
+// by commands in fileDir
+func NewResponder(out io.Writer, fileDir string) *Responder {
+	return &Responder{out: out, fileDir: fileDir}
+}
+
+func (r *Responder) Send(text string) error {
+	_, err := fmt.Fprintln(r.out, command.PlainText(text))
+	return err
+}
+
+func (r *Responder) Reply(text string) error {
+	_, err := fmt.Fprintln(r.out, "> "+command.PlainText(text))
+	return err
+}
+
+func (r *Responder) SendEmbeds(embeds ...*discordgo.MessageEmbed) error {
+	for _, embed := range embeds {
+		if _, err := fmt.Fprintln(r.out, command.EmbedText(embed)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Responder) SendFile(name, text string, reader io.Reader) error {
+	if text != "" {
+		if err := r.Send(text); err != nil {
+			return err
+		}
+	}
+
+	path := filepath.Join(r.fileDir, filepath.Base(name))
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", path, err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, reader); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	_, err = fmt.Fprintln(r.out, "[file saved to "+path+"]")
+	return err
+}
+
+// SendPages prints every page, since a terminal can scroll back
+func (r *Responder) SendPages(pages []command.Page) error {
+	for i, page := range pages {
+		if len(pages) > 1 {
+			fmt.Fprintf(r.out, "--- Page %d/%d ---\n", i+1, len(pages))
+		}
+		if err := r.SendEmbeds(page...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prompt is printed before each command is read
+const prompt = "ulb> "
+
+// Run reads commands from in, one per line, until EOF or "exit". The command
+// prefix is optional, so "player Judge" and "!player Judge" both work.
+func Run(in io.Reader, out io.Writer, prefix string, user command.User, fileDir string, execute command.Handler) error {
+	res := NewResponder(out, fileDir)
+	scanner := bufio.NewScanner(in)
+
+	fmt.Fprint(out, prompt)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch line {
+		case "":
+		case "exit", "quit":
+			return nil
+		default:
+			if !strings.HasPrefix(line, prefix) {
+				line = prefix + line
+			}
+			if req := command.Parse(prefix, line); req != nil {
+				req.User = user
+				execute(req, res)
+			}
+		}
+		fmt.Fprint(out, prompt)
+	}
+	fmt.Fprintln(out)
+	return scanner.Err()
+}
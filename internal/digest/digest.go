@@ -0,0 +1,192 @@
+package digest
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	fantraxmodels "github.com/pmurley/go-fantrax/models"
+	"github.com/pmurley/ulb-bot/internal/budget"
+	"github.com/pmurley/ulb-bot/internal/models"
+	"github.com/pmurley/ulb-bot/internal/storage"
+)
+
+// Period is how much history one digest covers
+const Period = 7 * 24 * time.Hour
+
+// topCount is how many teams the activity and payroll rankings show
+const topCount = 5
+
+// TypeCount is how many transactions of one type happened. Trades are
+// counted once per deal rather than per player.
+type TypeCount struct {
+	Type  string
+	Count int
+}
+
+// TeamCount is how many moves a team made
+type TeamCount struct {
+	Team  string
+	Count int
+}
+
+// TeamPayroll is a team's payroll for the digest's year
+type TeamPayroll struct {
+	Team    string
+	Payroll int
+}
+
+// Data is everything a digest template can use
+type Data struct {
+	Start            time.Time
+	End              time.Time
+	Year             int
+	TransactionCount int
+	Transactions     []TypeCount // By type, most common first
+	FASpend          float64     // Sum of BidAmount over claims
+	ActiveTeams      []TeamCount // Most active first
+	ExpiredWaivers   []*models.Waiver
+	SheetChanges     []storage.SheetChange
+	PayrollLeaders   []TeamPayroll // Highest payroll first
+}
+
+// Collect reads the week ending at end from the store and builds its digest
+func Collect(store storage.Store, players models.PlayerList, end time.Time) (Data, error) {
+	start := end.Add(-Period)
+
+	transactions, err := store.Transactions().GetAllTransactions()
+	if err != nil {
+		return Data{}, fmt.Errorf("failed to read transactions: %w", err)
+	}
+
+	waivers, err := store.Waivers().GetAllWaivers()
+	if err != nil {
+		return Data{}, fmt.Errorf("failed to read waivers: %w", err)
+	}
+
+	changes, err := store.SheetChanges().GetChangesBetween(start, end)
+	if err != nil {
+		return Data{}, fmt.Errorf("failed to read sheet changes: %w", err)
+	}
+
+	return Build(start, end, transactions, waivers, changes, players), nil
+}
+
+// Build summarises the transactions, expired waivers and sheet changes in
+// [start, end), plus the payroll leaders for the year the week ends in
+func Build(start, end time.Time, transactions []fantraxmodels.Transaction, waivers []*models.Waiver, changes []storage.SheetChange, players models.PlayerList) Data {
+	data := Data{
+		Start:        start,
+		End:          end,
+		Year:         end.Year(),
+		SheetChanges: changes,
+	}
+
+	typeCounts := make(map[string]int)
+	teamCounts := make(map[string]int)
+	countedTrades := make(map[string]bool)
+
+	for _, tx := range transactions {
+		if tx.ProcessedDate.Before(start) || !tx.ProcessedDate.Before(end) {
+			continue
+		}
+
+		if tx.Type == "TRADE" && tx.TradeGroupID != "" {
+			if countedTrades[tx.TradeGroupID] {
+				continue
+			}
+			countedTrades[tx.TradeGroupID] = true
+			for _, team := range tradeTeams(transactions, tx.TradeGroupID) {
+				teamCounts[team]++
+			}
+		} else if tx.TeamName != "" {
+			teamCounts[tx.TeamName]++
+		}
+
+		typeCounts[tx.Type]++
+		data.TransactionCount++
+
+		data.FASpend += budget.Bid(tx)
+	}
+
+	for txType, count := range typeCounts {
+		data.Transactions = append(data.Transactions, TypeCount{Type: txType, Count: count})
+	}
+	sort.Slice(data.Transactions, func(i, j int) bool {
+		if data.Transactions[i].Count != data.Transactions[j].Count {
+			return data.Transactions[i].Count > data.Transactions[j].Count
+		}
+		return data.Transactions[i].Type < data.Transactions[j].Type
+	})
+
+	for team, count := range teamCounts {
+		data.ActiveTeams = append(data.ActiveTeams, TeamCount{Team: team, Count: count})
+	}
+	sort.Slice(data.ActiveTeams, func(i, j int) bool {
+		if data.ActiveTeams[i].Count != data.ActiveTeams[j].Count {
+			return data.ActiveTeams[i].Count > data.ActiveTeams[j].Count
+		}
+		return data.ActiveTeams[i].Team < data.ActiveTeams[j].Team
+	})
+	if len(data.ActiveTeams) > topCount {
+		data.ActiveTeams = data.ActiveTeams[:topCount]
+	}
+
+	// Waivers are stored once per notified owner, so collapse on message ID.
+	// Cancelled waivers, such as those of reversed drops, never expired.
+	seen := make(map[string]bool)
+	for _, waiver := range waivers {
+		if waiver.Cancelled || waiver.EndTime.Before(start) || !waiver.EndTime.Before(end) || seen[waiver.MessageID] {
+			continue
+		}
+		seen[waiver.MessageID] = true
+		data.ExpiredWaivers = append(data.ExpiredWaivers, waiver)
+	}
+	sort.Slice(data.ExpiredWaivers, func(i, j int) bool {
+		return data.ExpiredWaivers[i].EndTime.Before(data.ExpiredWaivers[j].EndTime)
+	})
+
+	data.PayrollLeaders = payrollLeaders(players, data.Year)
+
+	return data
+}
+
+// tradeTeams lists every team on either side of a trade group
+func tradeTeams(transactions []fantraxmodels.Transaction, tradeGroupID string) []string {
+	seen := make(map[string]bool)
+	var teams []string
+	for _, tx := range transactions {
+		if tx.TradeGroupID != tradeGroupID {
+			continue
+		}
+		for _, team := range []string{tx.FromTeamName, tx.ToTeamName} {
+			if team != "" && !seen[team] {
+				seen[team] = true
+				teams = append(teams, team)
+			}
+		}
+	}
+	return teams
+}
+
+// payrollLeaders ranks every ULB team by payroll
+func payrollLeaders(players models.PlayerList, year int) []TeamPayroll {
+	var leaders []TeamPayroll
+	for team := range players.GroupByTeam() {
+		if team == "" {
+			continue
+		}
+		leaders = append(leaders, TeamPayroll{Team: team, Payroll: players.GetTeamPayroll(team, year)})
+	}
+
+	sort.Slice(leaders, func(i, j int) bool {
+		if leaders[i].Payroll != leaders[j].Payroll {
+			return leaders[i].Payroll > leaders[j].Payroll
+		}
+		return leaders[i].Team < leaders[j].Team
+	})
+	if len(leaders) > topCount {
+		leaders = leaders[:topCount]
+	}
+	return leaders
+}
@@ -0,0 +1,142 @@
+package digest
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultTemplate is the digest layout used when DIGEST_TEMPLATE is not set.
+// Templates are rendered into the description of a Discord embed, so they
+// may use Discord markdown.
+const DefaultTemplate = `*{{date .Start}} – {{date .End}}*
+
+**📋 Transactions ({{.TransactionCount}})**
+{{- range .Transactions}}
+• {{.Type}}: {{.Count}}
+{{- else}}
+No transactions this week.
+{{- end}}
+{{- if .FASpend}}
+💰 FA spend: **{{bid .FASpend}}**
+{{- end}}
+
+**🔥 Most Active Teams**
+{{- range .ActiveTeams}}
+• {{.Team}} – {{.Count}} move{{plural .Count}}
+{{- else}}
+Nobody made a move.
+{{- end}}
+
+**⏰ Waivers Expired**
+{{- range .ExpiredWaivers}}
+• {{.PlayerName}} ({{.TeamName}})
+{{- else}}
+None this week.
+{{- end}}
+
+**📝 Sheet Changes**
+{{- range $i, $c := .SheetChanges}}{{if lt $i 15}}
+• {{$c.PlayerName}} ({{$c.Team}}): {{change $c.Field $c.Old $c.New}}
+{{- end}}{{else}}
+No changes detected.
+{{- end}}
+{{- if gt (len .SheetChanges) 15}}
+…and {{sub (len .SheetChanges) 15}} more
+{{- end}}
+
+**💵 Payroll Leaders ({{.Year}})**
+{{- range .PayrollLeaders}}
+• {{.Team}} – {{money .Payroll}}
+{{- end}}
+`
+
+// templateFuncs are the helpers available to digest templates
+var templateFuncs = template.FuncMap{
+	"date": func(t time.Time) string {
+		return t.Format("Jan 2")
+	},
+	"money": func(n int) string {
+		return "$" + formatNumber(n)
+	},
+	"bid": func(n float64) string {
+		return "$" + strconv.FormatFloat(n, 'f', -1, 64)
+	},
+	"plural": func(n int) string {
+		if n == 1 {
+			return ""
+		}
+		return "s"
+	},
+	"sub": func(a, b int) int {
+		return a - b
+	},
+	"change": formatChange,
+}
+
+// Template renders digests
+type Template struct {
+	tmpl *template.Template
+}
+
+// LoadTemplate parses the digest template at path, or the default when path is empty
+func LoadTemplate(path string) (*Template, error) {
+	text := DefaultTemplate
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read digest template: %w", err)
+		}
+		text = string(data)
+	}
+
+	tmpl, err := template.New("digest").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse digest template: %w", err)
+	}
+	return &Template{tmpl: tmpl}, nil
+}
+
+// Render executes the template with a digest's data
+func (t *Template) Render(data Data) (string, error) {
+	var buf bytes.Buffer
+	if err := t.tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render digest: %w", err)
+	}
+	return strings.TrimSpace(buf.String()), nil
+}
+
+// formatChange describes a sheet change, e.g. "Status: 40-Man → Minors"
+func formatChange(field, oldValue, newValue string) string {
+	switch field {
+	case "Added":
+		return "added to " + newValue
+	case "Removed":
+		return "removed from " + oldValue
+	}
+
+	if oldValue == "" {
+		oldValue = "none"
+	}
+	if newValue == "" {
+		newValue = "none"
+	}
+	return fmt.Sprintf("%s: %s → %s", field, oldValue, newValue)
+}
+
+// formatNumber adds thousands separators, e.g. 1234567 -> "1,234,567"
+func formatNumber(n int) string {
+	if n < 0 {
+		return "-" + formatNumber(-n)
+	}
+
+	str := strconv.Itoa(n)
+	for i := len(str) - 3; i > 0; i -= 3 {
+		str = str[:i] + "," + str[i:]
+	}
+	return str
+}
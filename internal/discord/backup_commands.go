@@ -0,0 +1,81 @@
+package discord
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pmurley/ulb-bot/internal/backup"
+	"github.com/pmurley/ulb-bot/internal/command"
+	"github.com/pmurley/ulb-bot/internal/routing"
+)
+
+// maxBackupUploadSize is the largest attachment every server accepts
+const maxBackupUploadSize = 8 * 1024 * 1024
+
+// handleBackup saves a backup of the storage files and uploads it
+func (hm *HandlerManager) handleBackup(req *command.Request, res command.Responder) {
+	if !isSuperUser(req.User.Name) {
+		res.Send("Only commissioners can take backups.")
+		return
+	}
+
+	// The archive holds Discord user IDs and every stored record, so it is
+	// only uploaded to the commissioner channel. The console saves it locally.
+	if req.ChannelID != "" {
+		commissionerChannelID := hm.router.NamedChannel(req.GuildID, routing.ChannelCommissioner)
+		if req.ChannelID != commissionerChannelID {
+			response := "The !backup command can only be used in the commissioner channel."
+			if commissionerChannelID != "" {
+				response = fmt.Sprintf("The !backup command can only be used in the <#%s> channel.", commissionerChannelID)
+			}
+			res.Reply(response)
+			return
+		}
+	}
+
+	path, manifest, err := backup.Save(hm.config.BackupsDir(), backup.LabelManual, hm.config.StorageDir(), hm.store)
+	if err != nil {
+		hm.logger.Error("Failed to create backup:", err)
+		command.Fail(res, "Failed to create backup", err)
+		return
+	}
+	hm.logger.Info("Backup saved to ", path)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		command.Fail(res, "Failed to read backup", err)
+		return
+	}
+
+	summary := fmt.Sprintf("💾 Backup of %d file%s (%s uncompressed), saved as `%s`",
+		len(manifest.Files), pluralize(len(manifest.Files)), formatBytes(manifest.Size()), filepath.Base(path))
+	if info.Size() > maxBackupUploadSize {
+		res.Send(summary + fmt.Sprintf("\nThe archive is %s, too large to upload. Copy it from the server.", formatBytes(info.Size())))
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		command.Fail(res, "Failed to open backup", err)
+		return
+	}
+	defer file.Close()
+
+	if err := res.SendFile(filepath.Base(path), summary, file); err != nil {
+		hm.logger.Error("Failed to upload backup:", err)
+		command.Fail(res, "Failed to upload backup", err)
+	}
+}
+
+// formatBytes formats a size for humans
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	case n >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
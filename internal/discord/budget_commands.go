@@ -0,0 +1,170 @@
+package discord
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	fantraxmodels "github.com/pmurley/go-fantrax/models"
+	"github.com/pmurley/ulb-bot/internal/budget"
+	"github.com/pmurley/ulb-bot/internal/command"
+	"github.com/pmurley/ulb-bot/internal/models"
+)
+
+const (
+	defaultTopBids   = 10
+	maxTopBids       = 25
+	maxBudgetClaims  = 10
+	budgetEmbedColor = 0x2ecc71
+)
+
+// handleBudget shows bid budgets: one team, every team, or the largest bids
+func (hm *HandlerManager) handleBudget(req *command.Request, res command.Responder) {
+	if len(req.Args) == 0 {
+		res.Send("Usage: `!budget <team>`, `!budget --all` or `!budget --top [count]`")
+		return
+	}
+
+	cfg, err := budget.LoadConfig(hm.config.BudgetConfig)
+	if err != nil {
+		command.Fail(res, "Failed to load budget config", err)
+		return
+	}
+
+	transactions, err := hm.store.Transactions().GetAllTransactions()
+	if err != nil {
+		command.Fail(res, "Failed to read transactions", err)
+		return
+	}
+
+	season := cfg.CurrentSeason()
+
+	switch req.Args[0] {
+	case "--all":
+		res.SendEmbeds(buildAllBudgetsEmbed(cfg.Compute(transactions, season, leagueTeams()...), season))
+	case "--top":
+		n := defaultTopBids
+		if len(req.Args) > 1 {
+			if parsed, err := strconv.Atoi(req.Args[1]); err == nil && parsed > 0 {
+				n = min(parsed, maxTopBids)
+			}
+		}
+		res.SendEmbeds(buildTopBidsEmbed(budget.LargestBids(transactions, season, n), season))
+	default:
+		search := strings.Join(req.Args, " ")
+		team, suggestions := resolveLeagueTeam(search)
+		if team == "" {
+			msg := fmt.Sprintf("Team '%s' not found.", search)
+			if len(suggestions) > 0 {
+				msg += "\nDid you mean: " + strings.Join(suggestions, ", ") + "?"
+			}
+			res.Send(msg)
+			return
+		}
+		res.SendEmbeds(buildTeamBudgetEmbed(cfg.ForTeam(transactions, season, team), season))
+	}
+}
+
+// leagueTeams returns every team in the league, sorted
+func leagueTeams() []string {
+	teams := make([]string, 0, len(models.TeamOwners))
+	for team := range models.TeamOwners {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+	return teams
+}
+
+// resolveLeagueTeam finds a team by exact or partial name. When the search
+// is ambiguous or unknown it returns suggestions instead.
+func resolveLeagueTeam(search string) (string, []string) {
+	teams := leagueTeams()
+	for _, team := range teams {
+		if strings.EqualFold(team, search) {
+			return team, nil
+		}
+	}
+
+	matches := findSimilarTeams(search, teams)
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+	return "", matches
+}
+
+// buildTeamBudgetEmbed shows one team's budget and its recent winning bids
+func buildTeamBudgetEmbed(b budget.TeamBudget, season int) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title: fmt.Sprintf("💼 %s Bid Budget (%d)", b.Team, season),
+		Color: budgetEmbedColor,
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Starting", Value: budget.Format(b.Starting), Inline: true},
+			{Name: "Spent", Value: budget.Format(b.Spent), Inline: true},
+			{Name: "Remaining", Value: budget.Format(b.Remaining), Inline: true},
+		},
+	}
+
+	if len(b.Claims) == 0 {
+		embed.Description = "No winning bids this season."
+		return embed
+	}
+
+	var lines []string
+	for i, tx := range b.Claims {
+		if i == maxBudgetClaims {
+			lines = append(lines, fmt.Sprintf("…and %d more", len(b.Claims)-maxBudgetClaims))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("%s **%s** – %s", tx.ProcessedDate.Format(transactionDateFmt), tx.PlayerName, budget.Format(budget.Bid(tx))))
+	}
+	embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+		Name:   fmt.Sprintf("Winning Bids (%d)", len(b.Claims)),
+		Value:  strings.Join(lines, "\n"),
+		Inline: false,
+	})
+
+	return embed
+}
+
+// buildAllBudgetsEmbed lists every team's remaining budget, most first
+func buildAllBudgetsEmbed(budgets []budget.TeamBudget, season int) *discordgo.MessageEmbed {
+	sort.SliceStable(budgets, func(i, j int) bool {
+		return budgets[i].Remaining > budgets[j].Remaining
+	})
+
+	var lines []string
+	for _, b := range budgets {
+		lines = append(lines, fmt.Sprintf("**%s** – %s left (%s spent, %d bid%s)",
+			b.Team, budget.Format(b.Remaining), budget.Format(b.Spent), len(b.Claims), pluralize(len(b.Claims))))
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("💼 Bid Budgets (%d)", season),
+		Description: truncateText(strings.Join(lines, "\n"), maxDigestLength),
+		Color:       budgetEmbedColor,
+	}
+}
+
+// buildTopBidsEmbed shows the leaderboard of the largest winning bids
+func buildTopBidsEmbed(bids []fantraxmodels.Transaction, season int) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title: fmt.Sprintf("🏆 Largest Bids (%d)", season),
+		Color: budgetEmbedColor,
+	}
+
+	if len(bids) == 0 {
+		embed.Description = "No winning bids this season."
+		return embed
+	}
+
+	var lines []string
+	for i, tx := range bids {
+		lines = append(lines, fmt.Sprintf("%d. %s – **%s** (%s, %s)",
+			i+1, budget.Format(budget.Bid(tx)), tx.PlayerName, tx.TeamName, tx.ProcessedDate.Format(transactionDateFmt)))
+	}
+	embed.Description = strings.Join(lines, "\n")
+
+	return embed
+}
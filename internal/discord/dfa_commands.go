@@ -5,43 +5,42 @@ import (
 	"strings"
 	"time"
 
-	"github.com/bwmarrin/discordgo"
+	"github.com/pmurley/ulb-bot/internal/command"
 	"github.com/pmurley/ulb-bot/internal/models"
-	"github.com/pmurley/ulb-bot/internal/storage"
+	"github.com/pmurley/ulb-bot/internal/routing"
 )
 
-const (
-	dfaWaiversChannelID = "1080917219204153395"
-	//dfaWaiversChannelID = "1376062221699776543"
-	waiverDuration = 8 * 24 * time.Hour // 7 days
-	//waiverDuration = time.Minute // 7 days
-)
+const waiverDuration = 8 * 24 * time.Hour // 8 days
 
 // handleDFA processes the !dfa command
-func (hm *HandlerManager) handleDFA(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+func (hm *HandlerManager) handleDFA(req *command.Request, res command.Responder) {
 	// Check if command is in the correct channel
-	if m.ChannelID != dfaWaiversChannelID {
-		response := fmt.Sprintf("The !dfa command can only be used in the <#%s> channel.", dfaWaiversChannelID)
-		if _, err := s.ChannelMessageSendReply(m.ChannelID, response, m.Reference()); err != nil {
+	dfaChannelID := hm.router.NamedChannel(req.GuildID, routing.ChannelDFA)
+	if req.ChannelID == "" || req.ChannelID != dfaChannelID {
+		response := "The !dfa command can only be used in the DFA waivers channel."
+		if dfaChannelID != "" {
+			response = fmt.Sprintf("The !dfa command can only be used in the <#%s> channel.", dfaChannelID)
+		}
+		if err := res.Reply(response); err != nil {
 			hm.logger.Error("Failed to send channel restriction message:", err)
 		}
 		return
 	}
 
 	// Parse the player name from the command
-	if len(args) == 0 {
-		if _, err := s.ChannelMessageSendReply(m.ChannelID, "Usage: !dfa <playerName>", m.Reference()); err != nil {
+	if len(req.Args) == 0 {
+		if err := res.Reply("Usage: !dfa <playerName>"); err != nil {
 			hm.logger.Error("Failed to send usage message:", err)
 		}
 		return
 	}
 
-	playerName := strings.Join(args, " ")
+	playerName := strings.Join(req.Args, " ")
 
 	// Get players from cache (auto-reload if needed)
 	players, err := hm.ensurePlayersLoaded()
 	if err != nil {
-		if _, err := s.ChannelMessageSendReply(m.ChannelID, "Failed to load player data: "+err.Error(), m.Reference()); err != nil {
+		if err := res.Reply("Failed to load player data: " + err.Error()); err != nil {
 			hm.logger.Error("Failed to send error message:", err)
 		}
 		return
@@ -53,18 +52,17 @@ func (hm *HandlerManager) handleDFA(s *discordgo.Session, m *discordgo.MessageCr
 	// Handle no matches
 	if len(matches) == 0 {
 		response := fmt.Sprintf("No player found with name: %s", playerName)
-		if _, err := s.ChannelMessageSendReply(m.ChannelID, response, m.Reference()); err != nil {
+		if err := res.Reply(response); err != nil {
 			hm.logger.Error("Failed to send no match message:", err)
 		}
 		return
 	}
 
 	// Get the user's teams
-	userTeams := models.GetTeamsForOwner(m.Author.Username)
+	userTeams := models.GetTeamsForOwner(req.User.Name)
 
 	// Check for super user powers
-	isSuperUser := strings.ToLower(m.Author.Username) == "tasm616" ||
-		strings.ToLower(m.Author.Username) == "cyclone852_19274"
+	isSuperUser := isSuperUser(req.User.Name)
 
 	// Filter matches to only players on user's teams (or all if super user)
 	var userPlayerMatches models.PlayerList
@@ -92,7 +90,7 @@ func (hm *HandlerManager) handleDFA(s *discordgo.Session, m *discordgo.MessageCr
 		} else {
 			response = fmt.Sprintf("Found %d players matching '%s', but none belong to your teams.", len(matches), playerName)
 		}
-		if _, err := s.ChannelMessageSendReply(m.ChannelID, response, m.Reference()); err != nil {
+		if err := res.Reply(response); err != nil {
 			hm.logger.Error("Failed to send no owned match message:", err)
 		}
 		return
@@ -101,32 +99,29 @@ func (hm *HandlerManager) handleDFA(s *discordgo.Session, m *discordgo.MessageCr
 	// Just pick the first match on user's teams
 	player := userPlayerMatches[0]
 
-	// Create waiver storage instance
-	waiverStorage, err := storage.NewWaiverStorage()
+	// Open a thread on the DFA for discussion and waiver updates
+	threadID, err := hm.announcements.StartThread(req.ChannelID, req.MessageID, "DFA: "+player.Name)
 	if err != nil {
-		hm.logger.Error("Failed to create waiver storage:", err)
-		if _, err := s.ChannelMessageSendReply(m.ChannelID, "Error processing DFA. Please try again later.", m.Reference()); err != nil {
-			hm.logger.Error("Failed to send storage creation error message:", err)
-		}
-		return
+		hm.logger.Error("Failed to start DFA thread:", err)
 	}
 
 	// Create waiver entry
 	waiver := &models.Waiver{
 		PlayerName: player.Name,
 		TeamName:   player.ULBTeam,
-		UserID:     m.Author.ID,
+		UserID:     req.User.ID,
 		StartTime:  time.Now(),
-		EndTime:    time.Now().Add(waiverDuration),
-		MessageID:  m.ID,
-		ChannelID:  m.ChannelID,
+		EndTime:    time.Now().Add(hm.config.ScaleDuration(waiverDuration)),
+		MessageID:  req.MessageID,
+		ChannelID:  req.ChannelID,
 		Processed:  false,
+		ThreadID:   threadID,
 	}
 
 	// Save to storage
-	if err := waiverStorage.AddWaiver(waiver); err != nil {
+	if err := hm.store.Waivers().AddWaiver(waiver); err != nil {
 		hm.logger.Error("Failed to save waiver:", err)
-		if _, err := s.ChannelMessageSendReply(m.ChannelID, "Error processing DFA. Please try again later.", m.Reference()); err != nil {
+		if err := res.Reply("Error processing DFA. Please try again later."); err != nil {
 			hm.logger.Error("Failed to send storage error message:", err)
 		}
 		return
@@ -134,7 +129,13 @@ func (hm *HandlerManager) handleDFA(s *discordgo.Session, m *discordgo.MessageCr
 
 	// Send confirmation message
 	response := fmt.Sprintf("%s has been designated for assignment and placed on waivers. I will notify you after 8 days when the waiver period has expired. Make sure you have dropped the player in Fantrax.", player.Name)
-	if _, err := s.ChannelMessageSendReply(m.ChannelID, response, m.Reference()); err != nil {
+	if err := res.Reply(response); err != nil {
 		hm.logger.Error("Failed to send DFA confirmation:", err)
 	}
 }
+
+// isSuperUser reports whether a user has commissioner powers
+func isSuperUser(username string) bool {
+	username = strings.ToLower(username)
+	return username == "tasm616" || username == "cyclone852_19274"
+}
@@ -0,0 +1,58 @@
+package discord
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pmurley/ulb-bot/internal/command"
+	"github.com/pmurley/ulb-bot/internal/digest"
+	"github.com/pmurley/ulb-bot/internal/models"
+	"github.com/pmurley/ulb-bot/internal/storage"
+)
+
+// maxDigestLength is Discord's limit for an embed description
+const maxDigestLength = 4096
+
+// handleDigest previews the weekly digest for the past seven days, using the
+// configured template so layout changes can be checked before Monday
+func (hm *HandlerManager) handleDigest(req *command.Request, res command.Responder) {
+	// Without sheet data the digest still covers transactions and waivers
+	players, _ := hm.cache.GetPlayers()
+
+	embed, err := RenderDigest(hm.store, hm.config.DigestTemplate, players, time.Now())
+	if err != nil {
+		command.Fail(res, "Failed to build digest", err)
+		return
+	}
+	res.SendEmbeds(embed)
+}
+
+// RenderDigest builds the digest for the week ending at end and renders it
+// with the template at templatePath (the built-in layout when empty)
+func RenderDigest(store storage.Store, templatePath string, players models.PlayerList, end time.Time) (*discordgo.MessageEmbed, error) {
+	tmpl, err := digest.LoadTemplate(templatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := digest.Collect(store, players, end)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := tmpl.Render(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       "📰 Weekly League Digest",
+		Description: truncateText(text, maxDigestLength),
+		Color:       0x3498db,
+		Timestamp:   end.Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("%s – %s", data.Start.Format(transactionDateFmt), data.End.Format(transactionDateFmt)),
+		},
+	}, nil
+}
@@ -7,11 +7,18 @@ import (
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/pmurley/ulb-bot/internal/announce"
 	"github.com/pmurley/ulb-bot/internal/cache"
+	"github.com/pmurley/ulb-bot/internal/command"
 	"github.com/pmurley/ulb-bot/internal/config"
 	"github.com/pmurley/ulb-bot/internal/models"
+	"github.com/pmurley/ulb-bot/internal/poll"
+	"github.com/pmurley/ulb-bot/internal/ratelimit"
+	"github.com/pmurley/ulb-bot/internal/reconcile"
+	"github.com/pmurley/ulb-bot/internal/routing"
 	"github.com/pmurley/ulb-bot/internal/sheets"
 	"github.com/pmurley/ulb-bot/internal/spotrac"
+	"github.com/pmurley/ulb-bot/internal/storage"
 	"github.com/pmurley/ulb-bot/pkg/logger"
 )
 
@@ -22,11 +29,18 @@ type HandlerManager struct {
 	cache         *cache.Cache
 	sheetsClient  *sheets.Client
 	spotracClient *spotrac.Client
-	commands      map[string]CommandHandler
+	router        *routing.Router
+	reconciler    *reconcile.Reconciler
+	announcements *announce.Announcements
+	store         storage.Store
+	pollers       []*poll.Poller
+	paginator     *Paginator
+	limiter       *ratelimit.Limiter
+	responses     *responseTracker
+	commands      map[string]command.Handler
+	middleware    []command.Middleware
 }
 
-type CommandHandler func(s *discordgo.Session, m *discordgo.MessageCreate, args []string)
-
 func NewHandlerManager(
 	session *discordgo.Session,
 	config *config.Config,
@@ -34,6 +48,13 @@ func NewHandlerManager(
 	cache *cache.Cache,
 	sheetsClient *sheets.Client,
 	spotracClient *spotrac.Client,
+	router *routing.Router,
+	reconciler *reconcile.Reconciler,
+	announcements *announce.Announcements,
+	store storage.Store,
+	auditor command.Auditor,
+	limiter *ratelimit.Limiter,
+	pollers ...*poll.Poller,
 ) *HandlerManager {
 	hm := &HandlerManager{
 		session:       session,
@@ -42,7 +63,24 @@ func NewHandlerManager(
 		cache:         cache,
 		sheetsClient:  sheetsClient,
 		spotracClient: spotracClient,
-		commands:      make(map[string]CommandHandler),
+		router:        router,
+		reconciler:    reconciler,
+		announcements: announcements,
+		store:         store,
+		pollers:       pollers,
+		paginator:     NewPaginator(session, logger, config.PaginationTTL, config.PaginationAnyone),
+		limiter:       limiter,
+		responses:     newResponseTracker(),
+		commands:      make(map[string]command.Handler),
+	}
+
+	// The audit record sees the outcome of everything inside it, including
+	// rate limited commands and panics turned into replies by Recover
+	hm.middleware = []command.Middleware{
+		command.Audit(auditor, logger),
+		command.Timing(logger),
+		ratelimit.Middleware(limiter, func(req *command.Request) bool { return isSuperUser(req.User.Name) }),
+		command.Recover(logger),
 	}
 
 	hm.registerCommands()
@@ -52,6 +90,14 @@ func NewHandlerManager(
 
 func (hm *HandlerManager) RegisterHandlers() {
 	hm.session.AddHandler(hm.messageCreate)
+	hm.session.AddHandler(hm.messageUpdate)
+	hm.session.AddHandler(hm.messageDelete)
+	hm.session.AddHandler(hm.interactionCreate)
+}
+
+// interactionCreate handles button presses and modals on the bot's messages
+func (hm *HandlerManager) interactionCreate(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	hm.paginator.HandleInteraction(s, i)
 }
 
 func (hm *HandlerManager) registerCommands() {
@@ -64,6 +110,16 @@ func (hm *HandlerManager) registerCommands() {
 	hm.commands["dfa"] = hm.handleDFA
 	hm.commands["spotrac"] = hm.handleSpotrac
 	hm.commands["getfile"] = hm.handleGetFile
+	hm.commands["transactions"] = hm.handleTransactions
+	hm.commands["history"] = hm.handleHistory
+	hm.commands["roster"] = hm.handleRoster
+	hm.commands["reconcile"] = hm.handleReconcile
+	hm.commands["status"] = hm.handleStatus
+	hm.commands["retention"] = hm.handleRetention
+	hm.commands["digest"] = hm.handleDigest
+	hm.commands["budget"] = hm.handleBudget
+	hm.commands["backup"] = hm.handleBackup
+	hm.commands["ratelimits"] = hm.handleRateLimits
 }
 
 func (hm *HandlerManager) messageCreate(s *discordgo.Session, m *discordgo.MessageCreate) {
@@ -71,28 +127,113 @@ func (hm *HandlerManager) messageCreate(s *discordgo.Session, m *discordgo.Messa
 		return
 	}
 
-	if !strings.HasPrefix(m.Content, hm.config.CommandPrefix) {
+	req := hm.parseRequest(m.Message)
+	if req == nil {
 		return
 	}
 
-	content := strings.TrimPrefix(m.Content, hm.config.CommandPrefix)
-	parts := strings.Fields(content)
-	if len(parts) == 0 {
+	res := newDiscordResponder(s, m.Message, hm.paginator)
+	if !hm.editable(req.Name) {
+		hm.Execute(req, res)
 		return
 	}
 
-	command := strings.ToLower(parts[0])
-	args := parts[1:]
+	tracked := hm.responses.start(m.Message)
+	defer tracked.mu.Unlock()
+	hm.Execute(req, res)
+	tracked.responses = res.finish()
+}
 
-	if handler, exists := hm.commands[command]; exists {
-		hm.logger.Info("Processing command: ", command, " with args: ", args)
-		handler(s, m, args)
+// messageUpdate re-runs an edited command, editing its responses in place
+func (hm *HandlerManager) messageUpdate(s *discordgo.Session, m *discordgo.MessageUpdate) {
+	// Updates without an author, like link previews loading, are not edits
+	if m.Author == nil || m.Author.ID == s.State.User.ID {
+		return
+	}
+
+	tracked := hm.responses.get(m.ID)
+	if tracked == nil {
+		return
+	}
+	tracked.mu.Lock()
+	defer tracked.mu.Unlock()
+
+	if m.Content == tracked.content {
+		return
+	}
+	tracked.content = m.Content
+
+	req := hm.parseRequest(m.Message)
+	if req != nil && !hm.editable(req.Name) {
+		return
+	}
+
+	// An edit that is no longer a command removes the responses
+	res := newDiscordResponder(s, m.Message, hm.paginator)
+	res.previous = tracked.responses
+	if req != nil {
+		hm.logger.Info("Re-running edited command: ", req.Name)
+		hm.Execute(req, res)
+	}
+	tracked.responses = res.finish()
+}
+
+// messageDelete removes the responses to a deleted command
+func (hm *HandlerManager) messageDelete(s *discordgo.Session, m *discordgo.MessageDelete) {
+	tracked := hm.responses.remove(m.ID)
+	if tracked == nil {
+		return
+	}
+	tracked.mu.Lock()
+	defer tracked.mu.Unlock()
+
+	for _, id := range tracked.responses {
+		if err := s.ChannelMessageDelete(tracked.channelID, id); err != nil {
+			hm.logger.Debug("Failed to delete response ", id, ": ", err)
+		}
+	}
+	tracked.responses = nil
+}
+
+// parseRequest builds the request for a message, or returns nil when the
+// message is not a command the bot should answer
+func (hm *HandlerManager) parseRequest(m *discordgo.Message) *command.Request {
+	req := command.Parse(hm.config.CommandPrefix, m.Content)
+	if req == nil {
+		return nil
+	}
+
+	// In staging mode only answer commands from the staging channel so every
+	// reply stays there
+	if hm.router.Redirected() && m.ChannelID != hm.router.RedirectChannel(m.GuildID) {
+		return nil
+	}
+
+	req.User = command.User{ID: m.Author.ID, Name: m.Author.Username}
+	req.ChannelID = m.ChannelID
+	req.GuildID = m.GuildID
+	req.MessageID = m.ID
+	return req
+}
+
+// editable reports whether a command is re-run when its message is edited.
+// Unknown commands are too, so fixing a typo in the name runs the command.
+func (hm *HandlerManager) editable(name string) bool {
+	_, known := hm.commands[name]
+	return editableCommands[name] || !known
+}
+
+// Execute runs a command request from any transport
+func (hm *HandlerManager) Execute(req *command.Request, res command.Responder) {
+	if handler, exists := hm.commands[req.Name]; exists {
+		hm.logger.Info("Processing command: ", req.Name, " with args: ", req.Args)
+		command.Chain(handler, hm.middleware...)(req, res)
 	} else {
-		hm.logger.Warn("Unknown command: ", command)
+		hm.logger.Warn("Unknown command: ", req.Name)
 	}
 }
 
-func (hm *HandlerManager) handleHelp(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+func (hm *HandlerManager) handleHelp(req *command.Request, res command.Responder) {
 	helpMessage := `**Ultra League Baseball Bot Commands:**
 ` + "```" + `
 !help          - Show this help message
@@ -114,15 +255,32 @@ func (hm *HandlerManager) handleHelp(s *discordgo.Session, m *discordgo.MessageC
     !trade Ohtani (retain 25%) for Judge
     !trade Judge, cash ($5M) for Soto
   Use -v for full contract details
+!transactions [options] - Search Fantrax transaction history
+  Options:
+    --team=<team> --player=<name> --type=<CLAIM|DROP|TRADE> --claim=<FA|WW>
+    --by=<executor> --period=<n> --from=YYYY-MM-DD --to=YYYY-MM-DD
+    --page=<n>     - Show another page of results
+    --csv          - Attach all matching transactions as a CSV file
+  Example: !transactions --team=Havana Bananas --type=CLAIM --csv
+!history <name> - Show a player's signings, trades, drops and waivers
+!roster <team> [--period=<n>|--date=YYYY-MM-DD] - Replay a team's roster from Fantrax transactions
+!roster [team] --check - Compare replayed rosters with the sheet
+!reconcile     - Compare Fantrax rosters with the sheet's teams and statuses
+!status        - Show the health of the Fantrax transaction monitor
+!retention <player> <percent> - Record salary retained in a player's latest trade (commissioners)
+!digest        - Preview this week's league digest
+!budget <team> - Show a team's bid budget (--all for every team, --top [n] for the largest bids)
+!backup        - Back up the bot's storage and upload the archive (commissioners, in #commissioner)
+!ratelimits    - Show command rate limits and current usage (commissioners)
 ` + "```"
 
-	s.ChannelMessageSend(m.ChannelID, helpMessage)
+	res.Send(helpMessage)
 }
 
-func (hm *HandlerManager) handleReload(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
+func (hm *HandlerManager) handleReload(req *command.Request, res command.Responder) {
 	// Check if already loading
 	if hm.cache.IsLoading() {
-		s.ChannelMessageSend(m.ChannelID, "Data reload already in progress...")
+		res.Send("Data reload already in progress...")
 		return
 	}
 
@@ -131,10 +289,10 @@ func (hm *HandlerManager) handleReload(s *discordgo.Session, m *discordgo.Messag
 	defer hm.cache.SetLoading(false)
 
 	if err := hm.sheetsClient.LoadInitialData(hm.cache); err != nil {
-		s.ChannelMessageSend(m.ChannelID, "Failed to reload data: "+err.Error())
+		command.Fail(res, "Failed to reload data", err)
 		return
 	}
-	s.ChannelMessageSend(m.ChannelID, "Data reloaded successfully!")
+	res.Send("Data reloaded successfully!")
 }
 
 // ensurePlayersLoaded returns cached player data without blocking
@@ -146,13 +304,13 @@ func (hm *HandlerManager) ensurePlayersLoaded() (models.PlayerList, error) {
 	return players, nil
 }
 
-func (hm *HandlerManager) handleGetFile(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
-	if len(args) < 1 {
-		s.ChannelMessageSend(m.ChannelID, "Usage: !getfile <filepath>")
+func (hm *HandlerManager) handleGetFile(req *command.Request, res command.Responder) {
+	if len(req.Args) < 1 {
+		res.Send("Usage: !getfile <filepath>")
 		return
 	}
 
-	filePath := strings.Join(args, " ")
+	filePath := strings.Join(req.Args, " ")
 
 	// Clean the path to prevent directory traversal
 	cleanPath := filepath.Clean(filePath)
@@ -161,39 +319,39 @@ func (hm *HandlerManager) handleGetFile(s *discordgo.Session, m *discordgo.Messa
 	fileInfo, err := os.Stat(cleanPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			s.ChannelMessageSend(m.ChannelID, "File not found: "+cleanPath)
+			res.Send("File not found: " + cleanPath)
 		} else {
-			s.ChannelMessageSend(m.ChannelID, "Error accessing file: "+err.Error())
+			command.Fail(res, "Error accessing file", err)
 		}
 		return
 	}
 
 	// Check if it's a directory
 	if fileInfo.IsDir() {
-		s.ChannelMessageSend(m.ChannelID, "Cannot send a directory")
+		res.Send("Cannot send a directory")
 		return
 	}
 
 	// Check file size (Discord has a limit of 8MB for free servers, 50MB for boosted)
 	const maxFileSize = 8 * 1024 * 1024 // 8MB
 	if fileInfo.Size() > maxFileSize {
-		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("File too large (%d bytes). Maximum size is %d bytes", fileInfo.Size(), maxFileSize))
+		res.Send(fmt.Sprintf("File too large (%d bytes). Maximum size is %d bytes", fileInfo.Size(), maxFileSize))
 		return
 	}
 
 	// Open the file
 	file, err := os.Open(cleanPath)
 	if err != nil {
-		s.ChannelMessageSend(m.ChannelID, "Failed to open file: "+err.Error())
+		command.Fail(res, "Failed to open file", err)
 		return
 	}
 	defer file.Close()
 
 	// Send the file
-	_, err = s.ChannelFileSend(m.ChannelID, filepath.Base(cleanPath), file)
+	err = res.SendFile(filepath.Base(cleanPath), "", file)
 	if err != nil {
 		hm.logger.Error("Failed to send file: ", err)
-		s.ChannelMessageSend(m.ChannelID, "Failed to send file: "+err.Error())
+		command.Fail(res, "Failed to send file", err)
 		return
 	}
 
@@ -0,0 +1,220 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	fantraxmodels "github.com/pmurley/go-fantrax/models"
+	"github.com/pmurley/ulb-bot/internal/command"
+	"github.com/pmurley/ulb-bot/internal/history"
+	"github.com/pmurley/ulb-bot/internal/models"
+)
+
+// maxHistoryLength keeps the timeline inside Discord's embed description limit
+const maxHistoryLength = 3800
+
+// handleHistory shows a player's chronological transaction timeline
+func (hm *HandlerManager) handleHistory(req *command.Request, res command.Responder) {
+	if len(req.Args) == 0 {
+		res.Send("Usage: `!history <player name>`")
+		return
+	}
+
+	search := strings.Join(req.Args, " ")
+
+	transactions, err := hm.store.Transactions().GetAllTransactions()
+	if err != nil {
+		hm.logger.Error("Failed to read transactions:", err)
+		res.Send("Failed to load transaction history.")
+		return
+	}
+
+	waivers, err := hm.store.Waivers().GetAllWaivers()
+	if err != nil {
+		hm.logger.Error("Failed to read waivers:", err)
+		res.Send("Failed to load waiver history.")
+		return
+	}
+
+	// The sheet is optional here: players who have left the pool still have history
+	var sheetEntry *models.Player
+	players, err := hm.ensurePlayersLoaded()
+	if err != nil {
+		hm.logger.Warn("Player data unavailable for history lookup:", err)
+	}
+
+	playerName := ""
+	if exactMatches := players.FindByExactName(search); len(exactMatches) > 0 {
+		sheetEntry = &exactMatches[0]
+		playerName = sheetEntry.Name
+	} else if matches := players.SearchByName(search); len(matches) == 1 {
+		sheetEntry = &matches[0]
+		playerName = sheetEntry.Name
+	} else if len(matches) > 1 {
+		msg := fmt.Sprintf("Multiple players found matching '%s':\n", search)
+		for i, p := range matches {
+			if i >= 10 {
+				msg += fmt.Sprintf("... and %d more\n", len(matches)-10)
+				break
+			}
+			msg += fmt.Sprintf("• %s (%s, %s)\n", p.Name, p.Position, p.MLBTeam)
+		}
+		msg += "\nPlease be more specific."
+		res.Send(msg)
+		return
+	} else {
+		// Not in the sheet, fall back to names seen in the transaction log
+		names := history.KnownNames(search, transactions, waivers)
+		switch {
+		case len(names) == 0:
+			res.Send(fmt.Sprintf("No player found matching '%s'", search))
+			return
+		case len(names) > 1:
+			res.Send(fmt.Sprintf("Multiple players found matching '%s':\n• %s\n\nPlease be more specific.",
+				search, strings.Join(names, "\n• ")))
+			return
+		}
+		playerName = names[0]
+	}
+
+	events := history.Timeline(playerName, transactions, waivers)
+	embed := buildHistoryEmbed(playerName, events, sheetEntry)
+	if err := res.SendEmbeds(embed); err != nil {
+		hm.logger.Error("Failed to send history embed: ", err)
+		command.Fail(res, "Failed to display history", err)
+	}
+}
+
+// buildHistoryEmbed creates an embed with a player's timeline and current sheet entry
+func buildHistoryEmbed(playerName string, events []history.Event, sheetEntry *models.Player) *discordgo.MessageEmbed {
+	lines := make([]string, 0, len(events))
+	for _, event := range events {
+		lines = append(lines, formatHistoryEvent(event))
+	}
+
+	// Keep the most recent events if the timeline is too long
+	omitted := 0
+	length := 0
+	for i := len(lines) - 1; i >= 0; i-- {
+		length += len(lines[i]) + 1
+		if length > maxHistoryLength {
+			omitted = i + 1
+			break
+		}
+	}
+
+	var description strings.Builder
+	if omitted > 0 {
+		description.WriteString(fmt.Sprintf("*... %d earlier event%s*\n", omitted, pluralize(omitted)))
+	}
+	for _, line := range lines[omitted:] {
+		description.WriteString(line)
+		description.WriteString("\n")
+	}
+	if len(events) == 0 {
+		description.WriteString("No recorded transactions for this player.")
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("📜 %s - History", playerName),
+		Description: description.String(),
+		Color:       0x3498db,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("%d event%s", len(events), pluralize(len(events))),
+		},
+	}
+
+	if sheetEntry != nil {
+		team := sheetEntry.ULBTeam
+		if team == "" {
+			team = "Free Agent"
+		}
+		current := fmt.Sprintf("**%s** • %s, %s", team, sheetEntry.Position, sheetEntry.MLBTeam)
+		if sheetEntry.Status != "" {
+			current += fmt.Sprintf(" • %s", sheetEntry.Status)
+		}
+		embed.Fields = append(embed.Fields,
+			&discordgo.MessageEmbedField{
+				Name:   "Current Sheet Entry",
+				Value:  current,
+				Inline: false,
+			},
+			&discordgo.MessageEmbedField{
+				Name:   "Contract",
+				Value:  buildContractInfo(sheetEntry),
+				Inline: false,
+			},
+		)
+	} else {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Current Sheet Entry",
+			Value:  "Not found in the Master Player Pool",
+			Inline: false,
+		})
+	}
+
+	return embed
+}
+
+// formatHistoryEvent formats a single timeline event as one line of text
+func formatHistoryEvent(event history.Event) string {
+	date := event.Time.Format(transactionDateFmt)
+	period := ""
+	if event.Period > 0 {
+		period = fmt.Sprintf(" (period %d)", event.Period)
+	}
+
+	switch event.Kind {
+	case history.EventSigning:
+		line := fmt.Sprintf("`%s` ✍️ Signed by **%s**", date, event.Team)
+		if event.BidAmount != "" {
+			line += fmt.Sprintf(" for $%s", event.BidAmount)
+		}
+		return line + period
+	case history.EventWaiverClaim:
+		line := fmt.Sprintf("`%s` 🔄 Claimed off waivers by **%s**", date, event.Team)
+		if event.BidAmount != "" {
+			line += fmt.Sprintf(" for $%s", event.BidAmount)
+		}
+		return line + period
+	case history.EventPromotion:
+		return fmt.Sprintf("`%s` ⬆️ Promoted to 40-man by **%s**%s", date, event.Team, period)
+	case history.EventDrop:
+		return fmt.Sprintf("`%s` ❌ Dropped by **%s**%s", date, event.Team, period)
+	case history.EventTrade:
+		line := fmt.Sprintf("`%s` 🤝 Traded **%s** → **%s**%s", date, event.FromTeam, event.Team, period)
+		if deal := formatOtherPieces(event.OtherPieces); deal != "" {
+			line += "\n　　" + deal
+		}
+		return line
+	case history.EventDFA:
+		return fmt.Sprintf("`%s` ⚠️ Designated for assignment by **%s**", date, event.Team)
+	case history.EventWaiverExpired:
+		return fmt.Sprintf("`%s` ⏰ Cleared waivers (%s)", date, event.Team)
+	default:
+		return fmt.Sprintf("`%s` %s %s", date, event.Kind, event.Team)
+	}
+}
+
+// formatOtherPieces describes the rest of a trade, grouped by receiving team
+func formatOtherPieces(pieces []fantraxmodels.Transaction) string {
+	if len(pieces) == 0 {
+		return ""
+	}
+
+	var teams []string
+	received := make(map[string][]string)
+	for _, piece := range pieces {
+		if _, exists := received[piece.ToTeamName]; !exists {
+			teams = append(teams, piece.ToTeamName)
+		}
+		received[piece.ToTeamName] = append(received[piece.ToTeamName], piece.PlayerName)
+	}
+
+	parts := make([]string, 0, len(teams))
+	for _, team := range teams {
+		parts = append(parts, fmt.Sprintf("%s also got %s", team, strings.Join(received[team], ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,355 @@
+package discord
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pmurley/ulb-bot/internal/command"
+	"github.com/pmurley/ulb-bot/pkg/logger"
+)
+
+// pagesPrefix starts the custom ID of every paginator button and modal
+const pagesPrefix = "pages:"
+
+// Paginator sends paged output with Prev, Jump and Next buttons. Pages are
+// held in memory until the list has not been used for the TTL, when a timer
+// removes them and the buttons. After a restart the buttons answer that the
+// list expired.
+type Paginator struct {
+	session *discordgo.Session
+	logger  *logger.Logger
+	ttl     time.Duration
+	anyone  bool // Whether anyone may page, rather than only the invoking user
+
+	mu    sync.Mutex
+	lists map[string]*pagedList
+}
+
+// pagedList is the state of one paged message
+type pagedList struct {
+	pages     []command.Page
+	page      int
+	userID    string
+	channelID string
+	messageID string
+	expires   time.Time
+	timer     *time.Timer // Fires at expires to remove the list
+}
+
+// NewPaginator creates a paginator for the session
+func NewPaginator(session *discordgo.Session, log *logger.Logger, ttl time.Duration, anyone bool) *Paginator {
+	return &Paginator{
+		session: session,
+		logger:  log,
+		ttl:     ttl,
+		anyone:  anyone,
+		lists:   make(map[string]*pagedList),
+	}
+}
+
+// Send posts the first page with navigation buttons through post, which
+// sends or edits a message in the channel
+func (p *Paginator) Send(channelID, userID string, pages []command.Page, post func(*discordgo.MessageSend) (*discordgo.Message, error)) error {
+	id, err := newListID()
+	if err != nil {
+		return err
+	}
+	list := &pagedList{
+		pages:     pages,
+		userID:    userID,
+		channelID: channelID,
+		expires:   time.Now().Add(p.ttl),
+	}
+
+	msg, err := post(&discordgo.MessageSend{
+		Embeds:     fitPage(list.pages[0]),
+		Components: list.components(id),
+	})
+	if err != nil {
+		return err
+	}
+	list.messageID = msg.ID
+
+	p.mu.Lock()
+	p.lists[id] = list
+	list.timer = time.AfterFunc(p.ttl, func() { p.expire(id) })
+	p.mu.Unlock()
+	return nil
+}
+
+// HandleInteraction handles paginator buttons and the jump modal. It reports
+// whether the interaction belonged to the paginator.
+func (p *Paginator) HandleInteraction(s *discordgo.Session, i *discordgo.InteractionCreate) bool {
+	var customID string
+	switch i.Type {
+	case discordgo.InteractionMessageComponent:
+		customID = i.MessageComponentData().CustomID
+	case discordgo.InteractionModalSubmit:
+		customID = i.ModalSubmitData().CustomID
+	default:
+		return false
+	}
+	if !strings.HasPrefix(customID, pagesPrefix) {
+		return false
+	}
+
+	id, action, _ := strings.Cut(strings.TrimPrefix(customID, pagesPrefix), ":")
+
+	p.mu.Lock()
+	list, ok := p.lists[id]
+	if ok && time.Now().After(list.expires) {
+		delete(p.lists, id)
+		ok = false
+	}
+	p.mu.Unlock()
+
+	if !ok {
+		p.respondEphemeral(s, i, "This list has expired. Run the command again to page through it.")
+		return true
+	}
+	if !p.anyone && interactionUserID(i) != list.userID {
+		p.respondEphemeral(s, i, fmt.Sprintf("Only <@%s> can page through this list. Run the command yourself to get your own copy.", list.userID))
+		return true
+	}
+
+	p.mu.Lock()
+	page := list.page
+	p.mu.Unlock()
+
+	switch action {
+	case "prev":
+		page--
+	case "next":
+		page++
+	case "jump":
+		p.respondJumpModal(s, i, id, len(list.pages))
+		return true
+	case "goto":
+		n, err := strconv.Atoi(strings.TrimSpace(modalValue(i.ModalSubmitData())))
+		if err != nil || n < 1 || n > len(list.pages) {
+			p.respondEphemeral(s, i, fmt.Sprintf("Enter a page number from 1 to %d.", len(list.pages)))
+			return true
+		}
+		page = n - 1
+	default:
+		return true
+	}
+	if page < 0 {
+		page = 0
+	}
+	if page >= len(list.pages) {
+		page = len(list.pages) - 1
+	}
+
+	p.mu.Lock()
+	list.page = page
+	list.expires = time.Now().Add(p.ttl)
+	list.timer.Reset(p.ttl)
+	components := list.components(id)
+	p.mu.Unlock()
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     fitPage(list.pages[page]),
+			Components: components,
+		},
+	})
+	if err != nil {
+		p.logger.Error("Failed to update page:", err)
+	}
+	return true
+}
+
+// expire forgets a list whose TTL has passed and removes its buttons. A list
+// used since its timer was set is given the rest of its new TTL.
+func (p *Paginator) expire(id string) {
+	p.mu.Lock()
+	list, ok := p.lists[id]
+	if !ok {
+		p.mu.Unlock()
+		return
+	}
+	if remaining := time.Until(list.expires); remaining > 0 {
+		list.timer.Reset(remaining)
+		p.mu.Unlock()
+		return
+	}
+	delete(p.lists, id)
+	page := list.pages[list.page]
+	p.mu.Unlock()
+
+	_, err := p.session.ChannelMessageEditComplex(&discordgo.MessageEdit{
+		ID:         list.messageID,
+		Channel:    list.channelID,
+		Components: []discordgo.MessageComponent{},
+		Embeds:     fitPage(page),
+	})
+	if err != nil {
+		p.logger.Debug("Failed to remove buttons from expired list: ", err)
+	}
+}
+
+func (p *Paginator) respondEphemeral(s *discordgo.Session, i *discordgo.InteractionCreate, text string) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: text,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		p.logger.Error("Failed to respond to interaction:", err)
+	}
+}
+
+func (p *Paginator) respondJumpModal(s *discordgo.Session, i *discordgo.InteractionCreate, id string, pages int) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseModal,
+		Data: &discordgo.InteractionResponseData{
+			CustomID: pagesPrefix + id + ":goto",
+			Title:    "Jump to page",
+			Components: []discordgo.MessageComponent{
+				discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+					discordgo.TextInput{
+						CustomID:    "page",
+						Label:       fmt.Sprintf("Page (1-%d)", pages),
+						Style:       discordgo.TextInputShort,
+						Placeholder: "1",
+						Required:    true,
+						MaxLength:   4,
+					},
+				}},
+			},
+		},
+	})
+	if err != nil {
+		p.logger.Error("Failed to open jump modal:", err)
+	}
+}
+
+// components returns the navigation buttons for the list's current page
+func (l *pagedList) components(id string) []discordgo.MessageComponent {
+	return []discordgo.MessageComponent{
+		discordgo.ActionsRow{Components: []discordgo.MessageComponent{
+			discordgo.Button{
+				Label:    "◀ Prev",
+				Style:    discordgo.SecondaryButton,
+				CustomID: pagesPrefix + id + ":prev",
+				Disabled: l.page == 0,
+			},
+			discordgo.Button{
+				Label:    fmt.Sprintf("Page %d/%d", l.page+1, len(l.pages)),
+				Style:    discordgo.SecondaryButton,
+				CustomID: pagesPrefix + id + ":jump",
+			},
+			discordgo.Button{
+				Label:    "Next ▶",
+				Style:    discordgo.SecondaryButton,
+				CustomID: pagesPrefix + id + ":next",
+				Disabled: l.page == len(l.pages)-1,
+			},
+		}},
+	}
+}
+
+// fitPage makes a page fit one message. Pages are built to fit, so this only
+// cuts pages that were not.
+func fitPage(page command.Page) []*discordgo.MessageEmbed {
+	return renderEmbeds(page)[0]
+}
+
+// modalValue returns the value of the first text input of a modal
+func modalValue(data discordgo.ModalSubmitInteractionData) string {
+	for _, component := range data.Components {
+		row, ok := component.(*discordgo.ActionsRow)
+		if !ok {
+			continue
+		}
+		for _, c := range row.Components {
+			if input, ok := c.(*discordgo.TextInput); ok {
+				return input.Value
+			}
+		}
+	}
+	return ""
+}
+
+// interactionUserID returns who triggered an interaction, in a server or a DM
+func interactionUserID(i *discordgo.InteractionCreate) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.ID
+	}
+	if i.User != nil {
+		return i.User.ID
+	}
+	return ""
+}
+
+// newListID returns a random ID, so buttons on messages from before a
+// restart never match a new list
+func newListID() (string, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate list ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// embedPages splits an embed's fields into pages of at most fieldsPerPage
+// fields, starting a page early when the next field would not fit. Every
+// page keeps the title, description and footer.
+func embedPages(embed *discordgo.MessageEmbed, fieldsPerPage int) []command.Page {
+	newPage := func() *discordgo.MessageEmbed {
+		page := *embed
+		page.Fields = nil
+		return &page
+	}
+
+	current := newPage()
+	pages := []command.Page{{current}}
+	for _, field := range splitFields(embed.Fields) {
+		size := utf8.RuneCountInString(field.Name) + utf8.RuneCountInString(field.Value)
+		if len(current.Fields) > 0 && (len(current.Fields) >= fieldsPerPage || embedLength(current)+size > maxEmbedsLength) {
+			current = newPage()
+			pages = append(pages, command.Page{current})
+		}
+		current.Fields = append(current.Fields, field)
+	}
+	return pages
+}
+
+// embedListPages puts perPage embeds on each page
+func embedListPages(embeds []*discordgo.MessageEmbed, perPage int) []command.Page {
+	var pages []command.Page
+	for start := 0; start < len(embeds); start += perPage {
+		end := start + perPage
+		if end > len(embeds) {
+			end = len(embeds)
+		}
+		pages = append(pages, command.Page(embeds[start:end]))
+	}
+	return pages
+}
+
+// linePages puts perPage lines on each page, after the embed's description
+func linePages(embed *discordgo.MessageEmbed, lines []string, perPage int) []command.Page {
+	var pages []command.Page
+	for start := 0; start == 0 || start < len(lines); start += perPage {
+		end := start + perPage
+		if end > len(lines) {
+			end = len(lines)
+		}
+		page := *embed
+		page.Description = embed.Description + strings.Join(lines[start:end], "\n")
+		pages = append(pages, command.Page{&page})
+	}
+	return pages
+}
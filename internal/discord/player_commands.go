@@ -1,69 +1,63 @@
 package discord
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/pmurley/ulb-bot/internal/command"
 	"github.com/pmurley/ulb-bot/internal/models"
 )
 
 // handlePlayer looks up a player by name and displays their info
-func (hm *HandlerManager) handlePlayer(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
-	if len(args) == 0 {
-		s.ChannelMessageSend(m.ChannelID, "Usage: `!player <player name>`")
+func (hm *HandlerManager) handlePlayer(req *command.Request, res command.Responder) {
+	if len(req.Args) == 0 {
+		res.Send("Usage: `!player <player name>`")
 		return
 	}
 
 	// Join args to handle multi-word names
-	playerName := strings.Join(args, " ")
+	playerName := strings.Join(req.Args, " ")
 
 	// Get players from cache (auto-reload if needed)
 	players, err := hm.ensurePlayersLoaded()
 	if err != nil {
-		s.ChannelMessageSend(m.ChannelID, "Failed to load player data: "+err.Error())
+		command.Fail(res, "Failed to load player data", err)
 		return
 	}
 
-	// Try exact match first
-	exactMatches := players.FindByExactName(playerName)
-
-	// If exact matches found, display all of them
-	if len(exactMatches) > 0 {
-		if len(exactMatches) == 1 {
-			// Single exact match
-			embed := buildPlayerEmbed(&exactMatches[0])
-			s.ChannelMessageSendEmbed(m.ChannelID, embed)
-		} else {
-			// Multiple exact matches - show all
-			var embeds []*discordgo.MessageEmbed
-			for _, player := range exactMatches {
-				p := player // capture for pointer
-				embed := buildPlayerEmbed(&p)
-				embeds = append(embeds, embed)
-			}
-			// Discord allows up to 10 embeds per message
-			for i := 0; i < len(embeds); i += 10 {
-				end := i + 10
-				if end > len(embeds) {
-					end = len(embeds)
-				}
-				s.ChannelMessageSendEmbeds(m.ChannelID, embeds[i:end])
-			}
-		}
+	matches, err := LookupPlayer(players, playerName)
+	if err != nil {
+		res.Send(err.Error())
 		return
 	}
 
+	var embeds []*discordgo.MessageEmbed
+	for i := range matches {
+		embeds = append(embeds, BuildPlayerEmbed(&matches[i]))
+	}
+
+	res.SendEmbeds(embeds...)
+}
+
+// LookupPlayer returns the players a name refers to: every exact match, or
+// else the only partial match. The error is a message for the user when
+// nothing or several partial matches are found.
+func LookupPlayer(players models.PlayerList, name string) ([]models.Player, error) {
+	// Try exact match first; several players can share a name
+	if exactMatches := players.FindByExactName(name); len(exactMatches) > 0 {
+		return exactMatches, nil
+	}
+
 	// No exact match, try partial search
-	matches := players.SearchByName(playerName)
+	matches := players.SearchByName(name)
 	if len(matches) == 0 {
-		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("No player found matching '%s'", playerName))
-		return
+		return nil, fmt.Errorf("No player found matching '%s'", name)
 	}
 
-	// If multiple matches, show them
 	if len(matches) > 1 {
-		msg := fmt.Sprintf("Multiple players found matching '%s':\n", playerName)
+		msg := fmt.Sprintf("Multiple players found matching '%s':\n", name)
 		for i, p := range matches {
 			if i >= 10 { // Limit to 10 results
 				msg += fmt.Sprintf("... and %d more\n", len(matches)-10)
@@ -72,17 +66,14 @@ func (hm *HandlerManager) handlePlayer(s *discordgo.Session, m *discordgo.Messag
 			msg += fmt.Sprintf("• %s (%s, %s)\n", p.Name, p.Position, p.MLBTeam)
 		}
 		msg += "\nPlease be more specific."
-		s.ChannelMessageSend(m.ChannelID, msg)
-		return
+		return nil, errors.New(msg)
 	}
 
-	// Single match found
-	embed := buildPlayerEmbed(&matches[0])
-	s.ChannelMessageSendEmbed(m.ChannelID, embed)
+	return matches, nil
 }
 
-// buildPlayerEmbed creates a rich embed for player information
-func buildPlayerEmbed(p *models.Player) *discordgo.MessageEmbed {
+// BuildPlayerEmbed creates a rich embed for player information
+func BuildPlayerEmbed(p *models.Player) *discordgo.MessageEmbed {
 	embed := &discordgo.MessageEmbed{
 		Title: p.Name,
 		Color: getTeamColor(p.ULBTeam),
@@ -183,21 +174,24 @@ func getTeamColor(team string) int {
 	return 0x3498db
 }
 
+// playersPerPage is how many players a !players page shows
+const playersPerPage = 5
+
 // handlePlayers looks up multiple players by name and displays their info
-func (hm *HandlerManager) handlePlayers(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
-	if len(args) == 0 {
-		s.ChannelMessageSend(m.ChannelID, "Usage: `!players <player1>, <player2>, <player3>, ...`")
+func (hm *HandlerManager) handlePlayers(req *command.Request, res command.Responder) {
+	if len(req.Args) == 0 {
+		res.Send("Usage: `!players <player1>, <player2>, <player3>, ...`")
 		return
 	}
 
 	// Join args and split by comma
-	playerList := strings.Join(args, " ")
+	playerList := strings.Join(req.Args, " ")
 	playerNames := strings.Split(playerList, ",")
 
 	// Get players from cache (auto-reload if needed)
 	players, err := hm.ensurePlayersLoaded()
 	if err != nil {
-		s.ChannelMessageSend(m.ChannelID, "Failed to load player data: "+err.Error())
+		command.Fail(res, "Failed to load player data", err)
 		return
 	}
 
@@ -236,23 +230,16 @@ func (hm *HandlerManager) handlePlayers(s *discordgo.Session, m *discordgo.Messa
 
 	// Send results
 	if len(embeds) == 0 {
-		s.ChannelMessageSend(m.ChannelID, "No players found.")
+		res.Send("No players found.")
 		return
 	}
 
-	// Discord has a limit of 10 embeds per message
-	for i := 0; i < len(embeds); i += 10 {
-		end := i + 10
-		if end > len(embeds) {
-			end = len(embeds)
-		}
-		s.ChannelMessageSendEmbeds(m.ChannelID, embeds[i:end])
-	}
+	res.SendPages(embedListPages(embeds, playersPerPage))
 
 	// Report not found players
 	if len(notFound) > 0 {
 		msg := fmt.Sprintf("\n**Not found:** %s", strings.Join(notFound, ", "))
-		s.ChannelMessageSend(m.ChannelID, msg)
+		res.Send(msg)
 	}
 }
 
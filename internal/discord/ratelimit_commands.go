@@ -0,0 +1,74 @@
+package discord
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pmurley/ulb-bot/internal/command"
+	"github.com/pmurley/ulb-bot/internal/ratelimit"
+)
+
+// handleRateLimits shows the configured limits and who is close to them
+func (hm *HandlerManager) handleRateLimits(req *command.Request, res command.Responder) {
+	if !isSuperUser(req.User.Name) {
+		res.Send("Only commissioners can view rate limits.")
+		return
+	}
+
+	cfg := hm.limiter.Config()
+	limits := []string{
+		"Global: " + formatRule(cfg.Global),
+		"Per user: " + formatRule(cfg.PerUser),
+	}
+	names := make([]string, 0, len(cfg.Commands))
+	for name := range cfg.Commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		limits = append(limits, fmt.Sprintf("!%s: %s", name, formatRule(cfg.Commands[name])))
+	}
+
+	var usage []string
+	for _, state := range hm.limiter.State() {
+		var who string
+		switch state.Scope {
+		case ratelimit.ScopeGlobal:
+			who = "Global"
+		case ratelimit.ScopeCommand:
+			who = "!" + state.Key
+		default:
+			who = "<@" + state.Key + ">"
+		}
+		line := fmt.Sprintf("%s: %.1f of %d left", who, math.Max(state.Tokens, 0), state.Burst)
+		if state.Wait > 0 {
+			line += fmt.Sprintf(", ⏳ %ds cooldown", int(math.Ceil(state.Wait.Seconds())))
+		}
+		usage = append(usage, line)
+	}
+	if len(usage) == 0 {
+		usage = append(usage, "Every limit is at full capacity.")
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Rate Limits",
+		Color:       0x3498db,
+		Description: "Commissioners are not limited.",
+		Fields: []*discordgo.MessageEmbedField{
+			{Name: "Limits", Value: strings.Join(limits, "\n")},
+			{Name: "Current usage", Value: strings.Join(usage, "\n")},
+		},
+	}
+	res.SendEmbeds(embed)
+}
+
+// formatRule describes a token bucket rule
+func formatRule(rule ratelimit.Rule) string {
+	if rule.Burst <= 0 || rule.PerMinute <= 0 {
+		return "off"
+	}
+	return fmt.Sprintf("burst of %d, %g per minute", rule.Burst, rule.PerMinute)
+}
@@ -0,0 +1,118 @@
+package discord
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pmurley/ulb-bot/internal/command"
+	"github.com/pmurley/ulb-bot/internal/reconcile"
+)
+
+// maxReconcileFieldLength is Discord's limit for an embed field value
+const maxReconcileFieldLength = 1024
+
+// reconcileSections lists each mismatch kind in display order with its heading
+var reconcileSections = []struct {
+	kind  reconcile.Kind
+	title string
+}{
+	{reconcile.KindUnownedInSheet, "Rostered in Fantrax, unowned in sheet"},
+	{reconcile.KindTeamMismatch, "Different team in Fantrax and sheet"},
+	{reconcile.KindNotOnFantrax, "Owned in sheet, not on any Fantrax roster"},
+	{reconcile.KindStatusMismatch, "40-man/minors status differs"},
+	{reconcile.KindMissingFromSheet, "Rostered in Fantrax, missing from the player pool"},
+}
+
+// handleReconcile compares the Fantrax rosters with the sheet on demand
+func (hm *HandlerManager) handleReconcile(req *command.Request, res command.Responder) {
+	players, err := hm.ensurePlayersLoaded()
+	if err != nil {
+		command.Fail(res, "Failed to load player data", err)
+		return
+	}
+
+	res.Send("Fetching Fantrax rosters, this can take a minute...")
+
+	report, err := hm.reconciler.Run(players)
+	if errors.Is(err, reconcile.ErrRunning) {
+		res.Send("A reconciliation is already running, please wait for it to finish.")
+		return
+	}
+	if err != nil {
+		hm.logger.Error("Reconciliation failed:", err)
+		command.Fail(res, "Reconciliation failed", err)
+		return
+	}
+
+	if err := res.SendEmbeds(BuildReconcileEmbed(report)); err != nil {
+		hm.logger.Error("Failed to send reconciliation report: ", err)
+		command.Fail(res, "Failed to display reconciliation report", err)
+	}
+}
+
+// BuildReconcileEmbed creates an embed summarising a Fantrax/sheet reconciliation
+func BuildReconcileEmbed(report *reconcile.Report) *discordgo.MessageEmbed {
+	embed := &discordgo.MessageEmbed{
+		Title:     "Fantrax vs Sheet Reconciliation",
+		Color:     0x2ecc71,
+		Timestamp: report.Time.Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("%d teams • %d rostered players checked", report.TeamsChecked, report.PlayersChecked),
+		},
+	}
+
+	if len(report.Mismatches) == 0 {
+		embed.Description = "✅ Fantrax rosters match the Master Player Pool."
+		return embed
+	}
+
+	embed.Color = 0xe74c3c
+	embed.Description = fmt.Sprintf("⚠️ Found %d mismatch%s between Fantrax and the sheet.",
+		len(report.Mismatches), pluralizeES(len(report.Mismatches)))
+
+	groups := report.ByKind()
+	for _, section := range reconcileSections {
+		mismatches := groups[section.kind]
+		if len(mismatches) == 0 {
+			continue
+		}
+
+		var value strings.Builder
+		for i, mismatch := range mismatches {
+			line := formatMismatch(mismatch) + "\n"
+			more := fmt.Sprintf("... and %d more", len(mismatches)-i)
+			if value.Len()+len(line)+len(more) > maxReconcileFieldLength {
+				value.WriteString(more)
+				break
+			}
+			value.WriteString(line)
+		}
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   fmt.Sprintf("%s (%d)", section.title, len(mismatches)),
+			Value:  value.String(),
+			Inline: false,
+		})
+	}
+
+	return embed
+}
+
+// formatMismatch formats a single mismatch as one line of text
+func formatMismatch(m reconcile.Mismatch) string {
+	switch m.Kind {
+	case reconcile.KindUnownedInSheet, reconcile.KindMissingFromSheet:
+		return fmt.Sprintf("• **%s** - Fantrax: %s (%s)", m.PlayerName, m.FantraxTeam, m.FantraxStatus)
+	case reconcile.KindTeamMismatch:
+		return fmt.Sprintf("• **%s** - Fantrax: %s, sheet: %s", m.PlayerName, m.FantraxTeam, m.SheetTeam)
+	case reconcile.KindNotOnFantrax:
+		return fmt.Sprintf("• **%s** - sheet: %s", m.PlayerName, m.SheetTeam)
+	case reconcile.KindStatusMismatch:
+		return fmt.Sprintf("• **%s** (%s) - Fantrax: %s, sheet: %s", m.PlayerName, m.FantraxTeam, m.FantraxStatus, m.SheetStatus)
+	default:
+		return "• **" + m.PlayerName + "**"
+	}
+}
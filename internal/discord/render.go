@@ -0,0 +1,244 @@
+package discord
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Discord's message and embed limits, counted in characters
+const (
+	maxMessageLength    = 2000
+	maxEmbedTitle       = 256
+	maxEmbedDescription = 4096
+	maxEmbedFields      = 25
+	maxFieldName        = 256
+	maxFieldValue       = 1024
+	maxFooterText       = 2048
+	maxAuthorName       = 256
+	maxEmbedsLength     = 6000 // All embeds of one message together
+	maxEmbedsPerMessage = 10
+)
+
+// maxSplitMessages is how many messages a response may be split into before
+// it is attached as a file instead
+const maxSplitMessages = 5
+
+// codeFence opens and closes Discord code blocks
+const codeFence = "```"
+
+// emptyValue stands in for the empty names and values Discord rejects
+const emptyValue = "\u200b"
+
+// splitText splits text into chunks of at most limit characters, preferring
+// line breaks. A code block cut in two is closed at the end of one chunk and
+// reopened at the start of the next.
+func splitText(text string, limit int) []string {
+	if utf8.RuneCountInString(text) <= limit {
+		return []string{text}
+	}
+
+	var chunks []string
+	var current strings.Builder
+	length := 0
+	inFence := false
+
+	flush := func() {
+		chunk := current.String()
+		if inFence {
+			if !strings.HasSuffix(chunk, "\n") {
+				chunk += "\n"
+			}
+			chunk += codeFence
+		}
+		chunks = append(chunks, strings.TrimRight(chunk, "\n"))
+
+		current.Reset()
+		length = 0
+		if inFence {
+			current.WriteString(codeFence + "\n")
+			length = len(codeFence) + 1
+		}
+	}
+
+	// Room is kept for closing a code block, and for reopening it in the
+	// next chunk
+	reserve := len(codeFence) + 1
+	for _, line := range strings.SplitAfter(text, "\n") {
+		for _, piece := range splitRunes(line, limit-2*reserve) {
+			n := utf8.RuneCountInString(piece)
+			if length > 0 && length+n > limit-reserve {
+				flush()
+			}
+			current.WriteString(piece)
+			length += n
+			if strings.Count(piece, codeFence)%2 == 1 {
+				inFence = !inFence
+			}
+		}
+	}
+	if strings.TrimSpace(current.String()) != "" {
+		inFence = false
+		flush()
+	}
+	return chunks
+}
+
+// splitRunes cuts s into pieces of at most limit characters, at spaces where
+// possible
+func splitRunes(s string, limit int) []string {
+	var pieces []string
+	for utf8.RuneCountInString(s) > limit {
+		runes := []rune(s)
+		cut := limit
+		prefix := string(runes[:limit])
+		if space := strings.LastIndex(prefix, " "); space > len(prefix)/2 {
+			cut = utf8.RuneCountInString(prefix[:space+1])
+		}
+		pieces = append(pieces, string(runes[:cut]))
+		s = string(runes[cut:])
+	}
+	return append(pieces, s)
+}
+
+// truncateText shortens s to at most limit characters, marking the cut
+func truncateText(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return string([]rune(s)[:limit-1]) + "…"
+}
+
+// splitEmbed splits an embed that breaks Discord's limits into several that
+// don't. Long field values continue in further fields, and fields that don't
+// fit continue in further embeds; the first embed keeps the title and the
+// last one the footer.
+func splitEmbed(embed *discordgo.MessageEmbed) []*discordgo.MessageEmbed {
+	base := *embed
+	base.Title = truncateText(base.Title, maxEmbedTitle)
+	if base.Author != nil {
+		author := *base.Author
+		author.Name = truncateText(author.Name, maxAuthorName)
+		base.Author = &author
+	}
+	var footer *discordgo.MessageEmbedFooter
+	if base.Footer != nil {
+		f := *base.Footer
+		f.Text = truncateText(f.Text, maxFooterText)
+		footer = &f
+	}
+
+	fields := splitFields(embed.Fields)
+	descriptions := splitText(base.Description, maxEmbedDescription)
+
+	first := base
+	first.Description = descriptions[0]
+	first.Fields = nil
+	first.Footer = nil
+	embeds := []*discordgo.MessageEmbed{&first}
+
+	// Further description parts go in embeds of their own
+	for _, description := range descriptions[1:] {
+		embeds = append(embeds, continuationEmbed(&base, description))
+	}
+
+	current := embeds[len(embeds)-1]
+	for _, field := range fields {
+		size := utf8.RuneCountInString(field.Name) + utf8.RuneCountInString(field.Value)
+		if len(current.Fields) >= maxEmbedFields || embedLength(current)+size > maxEmbedsLength {
+			current = continuationEmbed(&base, "")
+			embeds = append(embeds, current)
+		}
+		current.Fields = append(current.Fields, field)
+	}
+
+	if footer != nil {
+		if embedLength(current)+utf8.RuneCountInString(footer.Text) > maxEmbedsLength {
+			current = continuationEmbed(&base, "")
+			embeds = append(embeds, current)
+		}
+		current.Footer = footer
+	}
+	if len(embeds) > 1 {
+		current.Timestamp = base.Timestamp
+		embeds[0].Timestamp = ""
+	}
+	return embeds
+}
+
+// splitFields continues field values that are too long in further fields,
+// and fills in empty names and values
+func splitFields(fields []*discordgo.MessageEmbedField) []*discordgo.MessageEmbedField {
+	var split []*discordgo.MessageEmbedField
+	for _, field := range fields {
+		name := truncateText(field.Name, maxFieldName)
+		if strings.TrimSpace(name) == "" {
+			name = emptyValue
+		}
+		value := field.Value
+		if strings.TrimSpace(value) == "" {
+			value = emptyValue
+		}
+		for i, part := range splitText(value, maxFieldValue) {
+			partName := name
+			if i > 0 {
+				partName = truncateText(name+" (cont.)", maxFieldName)
+			}
+			split = append(split, &discordgo.MessageEmbedField{Name: partName, Value: part, Inline: field.Inline})
+		}
+	}
+	return split
+}
+
+// continuationEmbed starts an embed that continues base, in the same color
+func continuationEmbed(base *discordgo.MessageEmbed, description string) *discordgo.MessageEmbed {
+	return &discordgo.MessageEmbed{Color: base.Color, Description: description}
+}
+
+// embedLength counts the characters Discord counts against maxEmbedsLength
+func embedLength(embed *discordgo.MessageEmbed) int {
+	n := utf8.RuneCountInString(embed.Title) + utf8.RuneCountInString(embed.Description)
+	for _, field := range embed.Fields {
+		n += utf8.RuneCountInString(field.Name) + utf8.RuneCountInString(field.Value)
+	}
+	if embed.Footer != nil {
+		n += utf8.RuneCountInString(embed.Footer.Text)
+	}
+	if embed.Author != nil {
+		n += utf8.RuneCountInString(embed.Author.Name)
+	}
+	return n
+}
+
+// groupEmbeds splits embeds into messages within Discord's embed count and
+// total length limits
+func groupEmbeds(embeds []*discordgo.MessageEmbed) [][]*discordgo.MessageEmbed {
+	var groups [][]*discordgo.MessageEmbed
+	var group []*discordgo.MessageEmbed
+	length := 0
+	for _, embed := range embeds {
+		n := embedLength(embed)
+		if len(group) > 0 && (len(group) >= maxEmbedsPerMessage || length+n > maxEmbedsLength) {
+			groups = append(groups, group)
+			group = nil
+			length = 0
+		}
+		group = append(group, embed)
+		length += n
+	}
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// renderEmbeds splits embeds to fit Discord's limits and groups them into
+// messages
+func renderEmbeds(embeds []*discordgo.MessageEmbed) [][]*discordgo.MessageEmbed {
+	var split []*discordgo.MessageEmbed
+	for _, embed := range embeds {
+		split = append(split, splitEmbed(embed)...)
+	}
+	return groupEmbeds(split)
+}
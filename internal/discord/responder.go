@@ -0,0 +1,155 @@
+package discord
+
+import (
+	"io"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pmurley/ulb-bot/internal/command"
+)
+
+// discordResponder answers a command in the Discord channel it was sent in.
+// Output is split to fit Discord's limits, and attached as a file when it
+// would take more than maxSplitMessages messages.
+//
+// When a command is re-run after an edit, previous holds the responses to the
+// original message. They are edited in place, in order, before any new
+// message is sent.
+type discordResponder struct {
+	session   *discordgo.Session
+	message   *discordgo.Message
+	paginator *Paginator
+
+	previous []string // Responses from an earlier run still to be reused
+	sent     []string // Responses of this run
+}
+
+func newDiscordResponder(s *discordgo.Session, m *discordgo.Message, paginator *Paginator) *discordResponder {
+	return &discordResponder{session: s, message: m, paginator: paginator}
+}
+
+func (r *discordResponder) Send(text string) error {
+	chunks := splitText(text, maxMessageLength)
+	if len(chunks) > maxSplitMessages {
+		return r.sendAsFile(command.PlainText(text))
+	}
+	for _, chunk := range chunks {
+		if _, err := r.post(&discordgo.MessageSend{Content: chunk}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *discordResponder) Reply(text string) error {
+	chunks := splitText(text, maxMessageLength)
+	if len(chunks) > maxSplitMessages {
+		return r.sendAsFile(command.PlainText(text))
+	}
+	if _, err := r.post(&discordgo.MessageSend{Content: chunks[0], Reference: r.message.Reference()}); err != nil {
+		return err
+	}
+	for _, chunk := range chunks[1:] {
+		if _, err := r.post(&discordgo.MessageSend{Content: chunk}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *discordResponder) SendEmbeds(embeds ...*discordgo.MessageEmbed) error {
+	messages := renderEmbeds(embeds)
+	if len(messages) > maxSplitMessages {
+		var text []string
+		for _, embed := range embeds {
+			text = append(text, command.EmbedText(embed))
+		}
+		return r.sendAsFile(strings.Join(text, "\n"))
+	}
+	for _, message := range messages {
+		if _, err := r.post(&discordgo.MessageSend{Embeds: message}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *discordResponder) SendFile(name, text string, reader io.Reader) error {
+	// Only the last chunk of a long message goes with the file
+	chunks := splitText(text, maxMessageLength)
+	for _, chunk := range chunks[:len(chunks)-1] {
+		if _, err := r.post(&discordgo.MessageSend{Content: chunk}); err != nil {
+			return err
+		}
+	}
+
+	_, err := r.post(&discordgo.MessageSend{
+		Content: chunks[len(chunks)-1],
+		Files:   []*discordgo.File{{Name: name, Reader: reader}},
+	})
+	return err
+}
+
+func (r *discordResponder) SendPages(pages []command.Page) error {
+	switch len(pages) {
+	case 0:
+		return nil
+	case 1:
+		return r.SendEmbeds(pages[0]...)
+	}
+	return r.paginator.Send(r.message.ChannelID, r.message.Author.ID, pages, r.post)
+}
+
+// sendAsFile attaches output too long for messages as a text file
+func (r *discordResponder) sendAsFile(text string) error {
+	return r.SendFile("response.txt", "The response is too long for Discord, so it is attached as a file.", strings.NewReader(text))
+}
+
+// post sends one message, or edits the next response left from an earlier
+// run. Messages with files are always sent new, since an edit can't swap
+// attachments; the response they would have replaced is deleted by finish.
+func (r *discordResponder) post(send *discordgo.MessageSend) (*discordgo.Message, error) {
+	if len(r.previous) > 0 && len(send.Files) == 0 {
+		id := r.previous[0]
+		r.previous = r.previous[1:]
+
+		content := send.Content
+		edit := &discordgo.MessageEdit{
+			ID:         id,
+			Channel:    r.message.ChannelID,
+			Content:    &content,
+			Embeds:     send.Embeds,
+			Components: send.Components,
+		}
+		// Clear whatever the earlier response had
+		if edit.Embeds == nil {
+			edit.Embeds = []*discordgo.MessageEmbed{}
+		}
+		if edit.Components == nil {
+			edit.Components = []discordgo.MessageComponent{}
+		}
+
+		// A response that was deleted meanwhile is sent anew below
+		if msg, err := r.session.ChannelMessageEditComplex(edit); err == nil {
+			r.sent = append(r.sent, msg.ID)
+			return msg, nil
+		}
+	}
+
+	msg, err := r.session.ChannelMessageSendComplex(r.message.ChannelID, send)
+	if err != nil {
+		return nil, err
+	}
+	r.sent = append(r.sent, msg.ID)
+	return msg, nil
+}
+
+// finish deletes earlier responses this run did not reuse, and returns the
+// IDs of this run's responses
+func (r *discordResponder) finish() []string {
+	for _, id := range r.previous {
+		r.session.ChannelMessageDelete(r.message.ChannelID, id)
+	}
+	r.previous = nil
+	return r.sent
+}
@@ -0,0 +1,115 @@
+package discord
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// How long and how many command messages are remembered for edits and deletes
+const (
+	trackedCommandTTL  = time.Hour
+	maxTrackedCommands = 500
+)
+
+// editableCommands are re-run when their message is edited. Commands that
+// change something, like !dfa, !retention, !backup and !reload, are not, so
+// an edit never repeats them.
+var editableCommands = map[string]bool{
+	"help":         true,
+	"player":       true,
+	"players":      true,
+	"trade":        true,
+	"team":         true,
+	"spotrac":      true,
+	"transactions": true,
+	"history":      true,
+	"roster":       true,
+	"reconcile":    true,
+	"status":       true,
+	"digest":       true,
+	"budget":       true,
+	"ratelimits":   true,
+}
+
+// trackedCommand is a command message and the bot's responses to it. Its
+// mutex is held while the command runs, so an edit waits for the run before
+// it to finish.
+type trackedCommand struct {
+	mu        sync.Mutex
+	channelID string
+	content   string
+	responses []string
+	expires   time.Time
+}
+
+// responseTracker remembers which responses belong to which command
+// messages, keeping at most maxTrackedCommands for trackedCommandTTL
+type responseTracker struct {
+	mu       sync.Mutex
+	commands map[string]*trackedCommand
+	order    []string // Command message IDs, oldest first
+}
+
+func newResponseTracker() *responseTracker {
+	return &responseTracker{commands: make(map[string]*trackedCommand)}
+}
+
+// start tracks a new command message and returns it locked; the caller
+// records the responses and unlocks it
+func (t *responseTracker) start(m *discordgo.Message) *trackedCommand {
+	tc := &trackedCommand{
+		channelID: m.ChannelID,
+		content:   m.Content,
+		expires:   time.Now().Add(trackedCommandTTL),
+	}
+	tc.mu.Lock()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.prune()
+	t.commands[m.ID] = tc
+	t.order = append(t.order, m.ID)
+	return tc
+}
+
+// get returns a tracked command message, or nil
+func (t *responseTracker) get(messageID string) *trackedCommand {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tc, ok := t.commands[messageID]
+	if !ok || time.Now().After(tc.expires) {
+		return nil
+	}
+	return tc
+}
+
+// remove stops tracking a command message and returns it, or nil
+func (t *responseTracker) remove(messageID string) *trackedCommand {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tc, ok := t.commands[messageID]
+	if !ok {
+		return nil
+	}
+	delete(t.commands, messageID)
+	return tc
+}
+
+// prune drops expired command messages, and the oldest beyond the limit
+func (t *responseTracker) prune() {
+	now := time.Now()
+	for len(t.order) > 0 {
+		id := t.order[0]
+		tc, ok := t.commands[id]
+		if ok && now.Before(tc.expires) && len(t.commands) < maxTrackedCommands {
+			break
+		}
+		delete(t.commands, id)
+		t.order = t.order[1:]
+	}
+}
@@ -0,0 +1,138 @@
+package discord
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	fantraxmodels "github.com/pmurley/go-fantrax/models"
+	"github.com/pmurley/ulb-bot/internal/command"
+	"github.com/pmurley/ulb-bot/internal/models"
+	"github.com/pmurley/ulb-bot/internal/storage"
+)
+
+// handleRetention records salary retained by the team that traded a player
+// away in their most recent trade, then refreshes the trade's announcement
+func (hm *HandlerManager) handleRetention(req *command.Request, res command.Responder) {
+	if len(req.Args) < 2 {
+		res.Send("Usage: `!retention <player> <percent>`\n" +
+			"Example: `!retention Juan Soto 25%` (use 0 to clear)")
+		return
+	}
+
+	if !isSuperUser(req.User.Name) {
+		res.Send("Only commissioners can record retention.")
+		return
+	}
+
+	percent, err := strconv.ParseFloat(strings.TrimSuffix(req.Args[len(req.Args)-1], "%"), 64)
+	if err != nil || percent < 0 || percent > 100 {
+		res.Send("Retention must be a percentage between 0 and 100.")
+		return
+	}
+	playerName := strings.Join(req.Args[:len(req.Args)-1], " ")
+
+	transactions, err := hm.store.Transactions().GetPlayerTransactions(playerName)
+	if err != nil {
+		command.Fail(res, "Failed to read transactions", err)
+		return
+	}
+
+	traded, found := latestTrade(transactions, playerName)
+	if !found {
+		res.Send(fmt.Sprintf("No trade found for **%s**.", playerName))
+		return
+	}
+
+	err = hm.store.Retentions().SetRetention(storage.Retention{
+		TradeGroupID: traded.TradeGroupID,
+		PlayerName:   traded.PlayerName,
+		Percent:      percent,
+		RecordedBy:   req.User.Name,
+		RecordedAt:   time.Now(),
+	})
+	if err != nil {
+		command.Fail(res, "Failed to record retention", err)
+		return
+	}
+
+	updated := hm.refreshTradeAnnouncement(traded.TradeGroupID)
+
+	var confirmation string
+	if percent == 0 {
+		confirmation = fmt.Sprintf("Cleared retention on **%s** (traded by %s on %s).",
+			traded.PlayerName, traded.FromTeamName, traded.ProcessedDate.Format(transactionDateFmt))
+	} else {
+		confirmation = fmt.Sprintf("Recorded **%.0f%%** retention by %s on **%s** (traded %s).",
+			percent, traded.FromTeamName, traded.PlayerName, traded.ProcessedDate.Format(transactionDateFmt))
+	}
+	confirmation += fmt.Sprintf(" Updated %d announcement%s.", updated, pluralize(updated))
+	res.Send(confirmation)
+}
+
+// latestTrade finds the most recent trade that moved a player
+func latestTrade(transactions []fantraxmodels.Transaction, playerName string) (fantraxmodels.Transaction, bool) {
+	name := models.NormalizeName(playerName)
+
+	var latest fantraxmodels.Transaction
+	found := false
+	for _, tx := range transactions {
+		if tx.Type != "TRADE" || tx.TradeGroupID == "" || models.NormalizeName(tx.PlayerName) != name {
+			continue
+		}
+		if !found || tx.ProcessedDate.After(latest.ProcessedDate) {
+			latest = tx
+			found = true
+		}
+	}
+	return latest, found
+}
+
+// refreshTradeAnnouncement re-renders every announcement of a trade group with
+// the current retention, keeping title markers and audit updates. Returns how
+// many messages were edited.
+func (hm *HandlerManager) refreshTradeAnnouncement(tradeGroupID string) int {
+	trade, err := hm.store.Transactions().GetTradeGroup(tradeGroupID)
+	if err != nil {
+		hm.logger.Error("Failed to read trade:", err)
+		return 0
+	}
+
+	retentions, err := hm.store.Retentions().GetRetentions(tradeGroupID)
+	if err != nil {
+		hm.logger.Error("Failed to read trade retention:", err)
+		return 0
+	}
+
+	refs, err := hm.announcements.ForTradeGroup(tradeGroupID)
+	if err != nil {
+		hm.logger.Error("Failed to look up trade announcement:", err)
+		return 0
+	}
+
+	// Without sheet data the announcement still lists the players
+	players, _ := hm.cache.GetPlayers()
+	fresh := BuildTradeAnnouncementEmbed(trade, players, retentions)
+
+	updated := 0
+	for _, ref := range refs {
+		err := hm.announcements.EditEmbed(ref, func(embed *discordgo.MessageEmbed) {
+			fields := append([]*discordgo.MessageEmbedField{}, fresh.Fields...)
+			for _, field := range embed.Fields {
+				if field.Name == "Update" {
+					fields = append(fields, field)
+				}
+			}
+			embed.Description = fresh.Description
+			embed.Fields = fields
+		})
+		if err != nil {
+			hm.logger.Error("Failed to refresh trade announcement:", err)
+			continue
+		}
+		updated++
+	}
+	return updated
+}
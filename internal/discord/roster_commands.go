@@ -0,0 +1,264 @@
+package discord
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pmurley/ulb-bot/internal/command"
+	"github.com/pmurley/ulb-bot/internal/history"
+)
+
+// maxRosterLength keeps replayed rosters inside Discord's embed description limit
+const maxRosterLength = 3800
+
+const rosterUsage = "Usage: `!roster <team> [--period=<n> | --date=YYYY-MM-DD]` or `!roster [team] --check`"
+
+// RosterQuery is a parsed !roster command
+type RosterQuery struct {
+	Team   string
+	Period int       // Rosters at the start of this period
+	Date   time.Time // Rosters at the start of this day
+	Check  bool      // Compare replayed rosters against the sheet
+}
+
+// handleRoster replays the transaction log to show a team's roster at a point in time
+func (hm *HandlerManager) handleRoster(req *command.Request, res command.Responder) {
+	query, err := parseRosterQuery(req.Args)
+	if err != nil {
+		res.Send(fmt.Sprintf("Invalid arguments: %s\n%s", err, rosterUsage))
+		return
+	}
+	if query.Team == "" && !query.Check {
+		res.Send(rosterUsage)
+		return
+	}
+
+	transactions, err := hm.store.Transactions().GetAllTransactions()
+	if err != nil {
+		hm.logger.Error("Failed to read transactions:", err)
+		res.Send("Failed to load transaction history.")
+		return
+	}
+
+	var ledger *history.Ledger
+	var asOf string
+	switch {
+	case query.Period > 0:
+		ledger = history.ReplayUntilPeriod(transactions, query.Period)
+		asOf = fmt.Sprintf("start of period %d", query.Period)
+	case !query.Date.IsZero():
+		ledger = history.ReplayUntil(transactions, query.Date)
+		asOf = "start of " + query.Date.Format(transactionDateFmt)
+	default:
+		ledger = history.ReplayAll(transactions)
+		asOf = "now"
+	}
+
+	team := ""
+	if query.Team != "" {
+		teams := findSimilarTeams(query.Team, ledger.Rosters.Teams())
+		for _, candidate := range teams {
+			if strings.EqualFold(candidate, query.Team) {
+				teams = []string{candidate}
+				break
+			}
+		}
+		switch {
+		case len(teams) == 0:
+			res.Send(fmt.Sprintf("No transactions found for a team matching '%s' as of %s.", query.Team, asOf))
+			return
+		case len(teams) > 1:
+			res.Send(fmt.Sprintf("Multiple teams match '%s': %s", query.Team, strings.Join(teams, ", ")))
+			return
+		}
+		team = teams[0]
+	}
+
+	var embed *discordgo.MessageEmbed
+	if query.Check {
+		players, err := hm.ensurePlayersLoaded()
+		if err != nil {
+			command.Fail(res, "Failed to load player data", err)
+			return
+		}
+		embed = buildRosterCheckEmbed(team, ledger.CheckConsistency(players))
+	} else {
+		embed = buildReplayedRosterEmbed(team, asOf, ledger)
+	}
+
+	if err := res.SendEmbeds(embed); err != nil {
+		hm.logger.Error("Failed to send roster embed: ", err)
+		command.Fail(res, "Failed to display roster", err)
+	}
+}
+
+// parseRosterQuery parses !roster arguments. Words that are not flags or flag
+// values make up the team name.
+func parseRosterQuery(args []string) (RosterQuery, error) {
+	var query RosterQuery
+	var teamWords []string
+
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "--") {
+			teamWords = append(teamWords, arg)
+			continue
+		}
+
+		parts := strings.SplitN(arg, "=", 2)
+		name := strings.ToLower(parts[0])
+		if name == "--check" {
+			query.Check = true
+			continue
+		}
+
+		// Accept both "--period=12" and "--period 12"
+		value := ""
+		if len(parts) == 2 {
+			value = parts[1]
+		} else if i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		if value == "" {
+			return query, fmt.Errorf("missing value for %s", name)
+		}
+
+		switch name {
+		case "--period":
+			period, err := strconv.Atoi(value)
+			if err != nil || period <= 0 {
+				return query, fmt.Errorf("invalid period '%s'", value)
+			}
+			query.Period = period
+		case "--date":
+			date, err := time.ParseInLocation(transactionDateFmt, value, time.Local)
+			if err != nil {
+				return query, fmt.Errorf("invalid date '%s', use YYYY-MM-DD", value)
+			}
+			query.Date = date
+		default:
+			return query, fmt.Errorf("unknown option %s", name)
+		}
+	}
+
+	if query.Period > 0 && !query.Date.IsZero() {
+		return query, fmt.Errorf("use either --period or --date, not both")
+	}
+	if query.Check && (query.Period > 0 || !query.Date.IsZero()) {
+		return query, fmt.Errorf("--check always compares the current rosters")
+	}
+
+	query.Team = strings.Join(teamWords, " ")
+	return query, nil
+}
+
+// buildReplayedRosterEmbed creates an embed listing a team's replayed roster
+func buildReplayedRosterEmbed(team, asOf string, ledger *history.Ledger) *discordgo.MessageEmbed {
+	entries := ledger.Rosters[team]
+
+	var description strings.Builder
+	for i, entry := range entries {
+		line := fmt.Sprintf("• **%s** (%s, %s) - %s %s\n", entry.PlayerName, entry.Position, entry.MLBTeam,
+			describeAcquisition(entry.Via), entry.Since.Format(transactionDateFmt))
+		if description.Len()+len(line) > maxRosterLength {
+			description.WriteString(fmt.Sprintf("... and %d more\n", len(entries)-i))
+			break
+		}
+		description.WriteString(line)
+	}
+	if len(entries) == 0 {
+		description.WriteString("No players acquired through tracked transactions.")
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("%s - Roster as of %s", team, asOf),
+		Description: description.String(),
+		Color:       getTeamColor(team),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("%d player%s • Replayed from %d transaction%s. Players acquired before tracking began are not shown.",
+				len(entries), pluralize(len(entries)), ledger.Applied, pluralize(ledger.Applied)),
+		},
+	}
+}
+
+// describeAcquisition returns a short description of how a player was acquired
+func describeAcquisition(kind history.EventKind) string {
+	switch kind {
+	case history.EventSigning:
+		return "signed"
+	case history.EventWaiverClaim:
+		return "claimed"
+	case history.EventPromotion:
+		return "promoted"
+	case history.EventTrade:
+		return "traded for"
+	default:
+		return "added"
+	}
+}
+
+// buildRosterCheckEmbed creates an embed listing replay/sheet discrepancies,
+// optionally limited to one team
+func buildRosterCheckEmbed(team string, discrepancies []history.Discrepancy) *discordgo.MessageEmbed {
+	var lines []string
+	for _, d := range discrepancies {
+		if team != "" && !strings.EqualFold(d.LogTeam, team) && !strings.EqualFold(d.SheetTeam, team) {
+			continue
+		}
+
+		logTeam := d.LogTeam
+		if logTeam == "" {
+			logTeam = "dropped"
+		}
+		sheetTeam := d.SheetTeam
+		switch {
+		case !d.InSheet:
+			sheetTeam = "not in sheet"
+		case sheetTeam == "":
+			sheetTeam = "unowned"
+		}
+		lines = append(lines, fmt.Sprintf("• **%s**: Fantrax log says %s, sheet says %s", d.PlayerName, logTeam, sheetTeam))
+	}
+
+	title := "Roster Consistency Check"
+	if team != "" {
+		title += " - " + team
+	}
+
+	var description strings.Builder
+	for i, line := range lines {
+		if description.Len()+len(line)+1 > maxRosterLength {
+			description.WriteString(fmt.Sprintf("... and %d more\n", len(lines)-i))
+			break
+		}
+		description.WriteString(line)
+		description.WriteString("\n")
+	}
+
+	color := 0xe74c3c
+	if len(lines) == 0 {
+		description.WriteString("✅ The transaction log matches the sheet.")
+		color = 0x2ecc71
+	}
+
+	return &discordgo.MessageEmbed{
+		Title:       title,
+		Description: description.String(),
+		Color:       color,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("%d mismatch%s • Only players with tracked transactions are checked", len(lines), pluralizeES(len(lines))),
+		},
+	}
+}
+
+// pluralizeES returns "es" for counts other than 1
+func pluralizeES(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "es"
+}
@@ -6,23 +6,24 @@ import (
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/pmurley/ulb-bot/internal/command"
 	"github.com/pmurley/ulb-bot/internal/spotrac"
 )
 
 // handleSpotrac looks up a player's contract information from Spotrac
-func (hm *HandlerManager) handleSpotrac(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
-	if len(args) == 0 {
-		s.ChannelMessageSend(m.ChannelID, "Usage: `!spotrac <player name>`")
+func (hm *HandlerManager) handleSpotrac(req *command.Request, res command.Responder) {
+	if len(req.Args) == 0 {
+		res.Send("Usage: `!spotrac <player name>`")
 		return
 	}
 
 	// Join args to handle multi-word names
-	playerName := strings.Join(args, " ")
+	playerName := strings.Join(req.Args, " ")
 
 	// Search for player on Spotrac
 	result, err := hm.spotracClient.Search(playerName)
 	if err != nil {
-		s.ChannelMessageSend(m.ChannelID, "Failed to search Spotrac: "+err.Error())
+		command.Fail(res, "Failed to search Spotrac", err)
 		return
 	}
 
@@ -32,7 +33,7 @@ func (hm *HandlerManager) handleSpotrac(s *discordgo.Session, m *discordgo.Messa
 		if result.ErrorMessage != "" {
 			message = result.ErrorMessage
 		}
-		s.ChannelMessageSend(m.ChannelID, message)
+		res.Send(message)
 		return
 
 	case "multiple":
@@ -49,14 +50,13 @@ func (hm *HandlerManager) handleSpotrac(s *discordgo.Session, m *discordgo.Messa
 
 			if allSameName {
 				// All players have the same name - show contracts for all of them
-				hm.handleMultipleSameNamePlayers(s, m, result.PlayerResults)
+				hm.handleMultipleSameNamePlayers(res, result.PlayerResults)
 				return
 			}
 		}
 
 		// Different names - show multiple results and ask user to be more specific
-		embed := buildSpotracMultipleResultsEmbed(result, playerName)
-		s.ChannelMessageSendEmbed(m.ChannelID, embed)
+		res.SendPages(buildSpotracMultipleResultsPages(result, playerName))
 		return
 
 	case "single":
@@ -64,29 +64,30 @@ func (hm *HandlerManager) handleSpotrac(s *discordgo.Session, m *discordgo.Messa
 		player := result.PlayerResults[0]
 		contract, err := hm.spotracClient.GetPlayerContract(player.URL)
 		if err != nil {
-			s.ChannelMessageSend(m.ChannelID, "Failed to get contract information: "+err.Error())
+			command.Fail(res, "Failed to get contract information", err)
 			return
 		}
 
 		// Build and send contract embed
 		embed := buildSpotracContractEmbed(contract)
-		s.ChannelMessageSendEmbed(m.ChannelID, embed)
+		res.SendEmbeds(embed)
 	}
 }
 
+// maxSameNameContracts caps how many contracts one search fetches from Spotrac
+const maxSameNameContracts = 10
+
 // handleMultipleSameNamePlayers handles the case where multiple players have identical names
-func (hm *HandlerManager) handleMultipleSameNamePlayers(s *discordgo.Session, m *discordgo.MessageCreate, players []spotrac.PlayerSearchResult) {
-	// Limit to 10 results to avoid flooding the channel
+func (hm *HandlerManager) handleMultipleSameNamePlayers(res command.Responder, players []spotrac.PlayerSearchResult) {
+	// Every contract is a Spotrac request, so only fetch the first few
 	maxResults := len(players)
-	if maxResults > 10 {
-		maxResults = 10
+	if maxResults > maxSameNameContracts {
+		maxResults = maxSameNameContracts
 	}
 
 	var embeds []*discordgo.MessageEmbed
 
-	for i := 0; i < maxResults; i++ {
-		player := players[i]
-
+	for _, player := range players[:maxResults] {
 		// Get contract information for each player
 		contract, err := hm.spotracClient.GetPlayerContract(player.URL)
 		if err != nil {
@@ -100,61 +101,53 @@ func (hm *HandlerManager) handleMultipleSameNamePlayers(s *discordgo.Session, m
 	}
 
 	if len(embeds) == 0 {
-		s.ChannelMessageSend(m.ChannelID, "Failed to get contract information for any of the players found")
+		res.Send("Failed to get contract information for any of the players found")
 		return
 	}
 
-	// Add a summary message if we had to limit results
-	if len(players) > 10 {
+	// One contract per page
+	pages := embedListPages(embeds, 1)
+
+	// Add a summary to the first page if we had to limit results
+	if len(players) > maxResults {
 		summaryEmbed := &discordgo.MessageEmbed{
 			Title: fmt.Sprintf("Showing first %d of %d players", maxResults, len(players)),
 			Color: 0xFFFF00, // Yellow
 		}
-		embeds = append([]*discordgo.MessageEmbed{summaryEmbed}, embeds...)
+		pages[0] = append(command.Page{summaryEmbed}, pages[0]...)
 	}
 
-	// Send all embeds at once (Discord allows up to 10 embeds per message)
-	_, err := s.ChannelMessageSendEmbeds(m.ChannelID, embeds)
+	err := res.SendPages(pages)
 	if err != nil {
 		hm.logger.Error("Failed to send embeds: ", err.Error())
-		s.ChannelMessageSend(m.ChannelID, "Failed to send contract information")
+		res.Send("Failed to send contract information")
 	}
 }
 
-// buildSpotracMultipleResultsEmbed creates an embed for multiple search results
-func buildSpotracMultipleResultsEmbed(result *spotrac.SearchResult, query string) *discordgo.MessageEmbed {
-	embed := &discordgo.MessageEmbed{
-		Title: fmt.Sprintf("Multiple players found for '%s'", query),
-		Color: 0xFFA500, // Orange
-	}
-
-	var description strings.Builder
-	description.WriteString("Please be more specific. Found players:\n\n")
+// spotracResultsPerPage is how many search results a page lists
+const spotracResultsPerPage = 20
 
-	// Show up to 20 results to avoid hitting Discord's limits
-	maxResults := len(result.PlayerResults)
-	if maxResults > 20 {
-		maxResults = 20
+// buildSpotracMultipleResultsPages lists search results, a page at a time
+func buildSpotracMultipleResultsPages(result *spotrac.SearchResult, query string) []command.Page {
+	embed := &discordgo.MessageEmbed{
+		Title:       fmt.Sprintf("Multiple players found for '%s'", query),
+		Color:       0xFFA500, // Orange
+		Description: fmt.Sprintf("Please be more specific. Found %d players:\n\n", len(result.PlayerResults)),
 	}
 
-	for i := 0; i < maxResults; i++ {
-		player := result.PlayerResults[i]
-		description.WriteString(fmt.Sprintf("**%s**", player.Name))
+	var lines []string
+	for _, player := range result.PlayerResults {
+		line := fmt.Sprintf("**%s**", player.Name)
 		if player.Team != "" {
-			description.WriteString(fmt.Sprintf(" (%s)", player.Team))
+			line += fmt.Sprintf(" (%s)", player.Team)
 		}
 		if player.Position != "" {
-			description.WriteString(fmt.Sprintf(" - %s", player.Position))
+			line += fmt.Sprintf(" - %s", player.Position)
 		}
-		description.WriteString("\n")
+		lines = append(lines, line)
 	}
 
-	if len(result.PlayerResults) > 20 {
-		description.WriteString(fmt.Sprintf("\n*...and %d more results*", len(result.PlayerResults)-20))
-	}
-
-	embed.Description = description.String()
-	return embed
+	return linePages(embed, lines, spotracResultsPerPage)
 }
 
 // buildSpotracContractEmbed creates an embed for a player's contract information
@@ -272,16 +265,10 @@ func buildSpotracContractEmbed(contract *spotrac.ContractInfo) *discordgo.Messag
 		}
 		breakdown.WriteString("```")
 
-		// Discord field values have a limit of 1024 characters
-		breakdownText := breakdown.String()
-		if len(breakdownText) > 1024 {
-			// Truncate if too long
-			breakdownText = breakdownText[:1020] + "..."
-		}
-
+		// Long breakdowns are split across fields when sent
 		fields = append(fields, &discordgo.MessageEmbedField{
 			Name:   "Contract Breakdown",
-			Value:  breakdownText,
+			Value:  breakdown.String(),
 			Inline: false,
 		})
 	}
@@ -429,16 +416,10 @@ func buildSpotracContractEmbedWithDistinction(contract *spotrac.ContractInfo, se
 		}
 		breakdown.WriteString("```")
 
-		// Discord field values have a limit of 1024 characters
-		breakdownText := breakdown.String()
-		if len(breakdownText) > 1024 {
-			// Truncate if too long
-			breakdownText = breakdownText[:1020] + "..."
-		}
-
+		// Long breakdowns are split across fields when sent
 		fields = append(fields, &discordgo.MessageEmbedField{
 			Name:   "Contract Breakdown",
-			Value:  breakdownText,
+			Value:  breakdown.String(),
 			Inline: false,
 		})
 	}
@@ -0,0 +1,72 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pmurley/ulb-bot/internal/command"
+	"github.com/pmurley/ulb-bot/internal/poll"
+)
+
+// handleStatus reports the health of the bot's background pollers
+func (hm *HandlerManager) handleStatus(req *command.Request, res command.Responder) {
+	embed := &discordgo.MessageEmbed{
+		Title: "Bot Status",
+		Color: 0x2ecc71,
+	}
+
+	for _, poller := range hm.pollers {
+		health := poller.Health()
+		if !health.Healthy() {
+			embed.Color = 0xe74c3c
+		}
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   health.Name,
+			Value:  formatPollHealth(health),
+			Inline: false,
+		})
+	}
+
+	if len(embed.Fields) == 0 {
+		embed.Description = "No background monitors are running."
+	}
+
+	res.SendEmbeds(embed)
+}
+
+// formatPollHealth describes a poller's state
+func formatPollHealth(h poll.Health) string {
+	var lines []string
+
+	switch {
+	case h.CircuitOpen:
+		lines = append(lines, fmt.Sprintf("⛔ Paused after %d failures in a row", h.ConsecutiveFailures))
+	case h.ConsecutiveFailures > 0:
+		lines = append(lines, fmt.Sprintf("⚠️ Failing (%d in a row), backing off", h.ConsecutiveFailures))
+	case h.LastSuccess.IsZero():
+		lines = append(lines, "⏳ Waiting for the first poll")
+	default:
+		lines = append(lines, "✅ Healthy")
+	}
+
+	lines = append(lines, "Last success: "+formatPollTime(h.LastSuccess))
+	lines = append(lines, "Last attempt: "+formatPollTime(h.LastAttempt))
+	if !h.NextAttempt.IsZero() {
+		lines = append(lines, "Next attempt: "+formatPollTime(h.NextAttempt))
+	}
+	if h.LastError != "" {
+		lines = append(lines, "Last error: `"+truncateText(h.LastError, 300)+"`")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// formatPollTime renders a time as a Discord relative timestamp
+func formatPollTime(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+	return fmt.Sprintf("<t:%d:R>", t.Unix())
+}
@@ -1,11 +1,13 @@
 package discord
 
 import (
+	"errors"
 	"fmt"
 	"sort"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/pmurley/ulb-bot/internal/command"
 	"github.com/pmurley/ulb-bot/internal/models"
 )
 
@@ -18,18 +20,22 @@ type TeamFilters struct {
 	ShowContracts bool   // Whether to show contract details
 }
 
+// teamFieldsPerPage is how many position groups a roster page shows, three
+// rows of inline fields
+const teamFieldsPerPage = 9
+
 // handleTeam displays the roster for a specific team with optional filters
-func (hm *HandlerManager) handleTeam(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
-	if len(args) == 0 {
-		s.ChannelMessageSend(m.ChannelID, "Usage: `!team <team name> [--status=<40-man|minors|all>] [--position=<pos>] [--age=<min-max>] [--contracts]`")
+func (hm *HandlerManager) handleTeam(req *command.Request, res command.Responder) {
+	if len(req.Args) == 0 {
+		res.Send("Usage: `!team <team name> [--status=<40-man|minors|all>] [--position=<pos>] [--age=<min-max>] [--contracts]`")
 		return
 	}
 
 	// Special debug option to list all teams
-	if len(args) == 1 && args[0] == "--list" {
+	if len(req.Args) == 1 && req.Args[0] == "--list" {
 		players, err := hm.ensurePlayersLoaded()
 		if err != nil {
-			s.ChannelMessageSend(m.ChannelID, "Failed to load player data: "+err.Error())
+			command.Fail(res, "Failed to load player data", err)
 			return
 		}
 
@@ -38,11 +44,51 @@ func (hm *HandlerManager) handleTeam(s *discordgo.Session, m *discordgo.MessageC
 		for _, team := range allTeams {
 			msg += fmt.Sprintf("• %s\n", team)
 		}
-		s.ChannelMessageSend(m.ChannelID, msg)
+		res.Send(msg)
+		return
+	}
+
+	teamName, filters := ParseTeamArgs(req.Args)
+	if teamName == "" {
+		res.Send("Please specify a team name")
+		return
+	}
+
+	// Get players from cache (auto-reload if needed)
+	players, err := hm.ensurePlayersLoaded()
+	if err != nil {
+		command.Fail(res, "Failed to load player data", err)
+		return
+	}
+
+	teamName, teamPlayers, err := FindTeam(players, teamName)
+	if err != nil {
+		res.Send(err.Error())
 		return
 	}
 
-	// Parse args to separate team name from filters
+	// Apply filters
+	filteredPlayers := ApplyTeamFilters(teamPlayers, filters)
+
+	if len(filteredPlayers) == 0 {
+		res.Send(fmt.Sprintf("No players found for %s with the specified filters", teamName))
+		return
+	}
+
+	// Build team roster embed
+	embed := BuildTeamRosterEmbed(teamName, filteredPlayers, filters)
+
+	hm.logger.Info("Sending embed for team: ", teamName, " with ", len(filteredPlayers), " players")
+
+	if err := res.SendPages(embedPages(embed, teamFieldsPerPage)); err != nil {
+		hm.logger.Error("Failed to send team roster:", err)
+		res.Send(fmt.Sprintf("Couldn't display the roster for %s right now. Please try again later.", teamName))
+	}
+}
+
+// ParseTeamArgs separates the team name from the filter options of a !team
+// command. The name is empty when only options were given.
+func ParseTeamArgs(args []string) (string, TeamFilters) {
 	teamNameParts := []string{}
 	filters := TeamFilters{
 		Status: "40-man", // Default to 40-man roster only
@@ -98,80 +144,43 @@ func (hm *HandlerManager) handleTeam(s *discordgo.Session, m *discordgo.MessageC
 		}
 	}
 
-	if len(teamNameParts) == 0 {
-		s.ChannelMessageSend(m.ChannelID, "Please specify a team name")
-		return
-	}
-
-	teamName := strings.Join(teamNameParts, " ")
-
-	// Get players from cache (auto-reload if needed)
-	players, err := hm.ensurePlayersLoaded()
-	if err != nil {
-		s.ChannelMessageSend(m.ChannelID, "Failed to load player data: "+err.Error())
-		return
-	}
+	return strings.Join(teamNameParts, " "), filters
+}
 
+// FindTeam returns the roster of the team a name refers to. When no team
+// matches exactly, a single similar team name is used instead. The error is
+// a message for the user, suggesting team names when there are several.
+func FindTeam(players models.PlayerList, teamName string) (string, models.PlayerList, error) {
 	// Find team (case-insensitive)
 	teamPlayers := players.FilterByTeam(teamName)
-
-	if len(teamPlayers) == 0 {
-		// Try to find similar team names
-		allTeams := getAllTeamNames(players)
-		suggestions := findSimilarTeams(teamName, allTeams)
-
-		hm.logger.Info("Team search: ", teamName, " found ", len(suggestions), " suggestions: ", suggestions)
-
-		if len(suggestions) == 1 {
-			// Auto-select the single suggestion
-			teamName = suggestions[0]
-			teamPlayers = players.FilterByTeam(teamName)
-			hm.logger.Info("Auto-selected team: ", teamName, " with ", len(teamPlayers), " players")
-
-			// Double-check we found players
-			if len(teamPlayers) == 0 {
-				s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("Team '%s' exists but has no players", teamName))
-				return
-			}
-		} else if len(suggestions) > 1 {
-			// Multiple suggestions - ask user to choose
-			msg := fmt.Sprintf("No team found matching '%s'\n\nDid you mean:\n", teamName)
-			for _, team := range suggestions {
-				msg += fmt.Sprintf("• %s\n", team)
-			}
-			s.ChannelMessageSend(m.ChannelID, msg)
-			return
-		} else {
-			// No suggestions found
-			s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("No team found matching '%s'", teamName))
-			return
-		}
-	}
-
-	// Apply filters
-	filteredPlayers := applyTeamFilters(teamPlayers, filters)
-
-	if len(filteredPlayers) == 0 {
-		s.ChannelMessageSend(m.ChannelID, fmt.Sprintf("No players found for %s with the specified filters", teamName))
-		return
+	if len(teamPlayers) > 0 {
+		return teamName, teamPlayers, nil
 	}
 
-	// Build team roster embed
-	embed := buildTeamRosterEmbed(teamName, filteredPlayers, filters)
-
-	hm.logger.Info("Sending embed for team: ", teamName, " with ", len(filteredPlayers), " players")
-
-	_, err = s.ChannelMessageSendEmbed(m.ChannelID, embed)
-	if err != nil {
-		hm.logger.Error("Failed to send embed: ", err)
-		// Try sending a simple text message as fallback
-		fallbackMsg := fmt.Sprintf("Error displaying team roster for %s (%d players). Error: %v", teamName, len(filteredPlayers), err)
-		s.ChannelMessageSend(m.ChannelID, fallbackMsg)
+	// Try to find similar team names
+	suggestions := findSimilarTeams(teamName, getAllTeamNames(players))
+	switch {
+	case len(suggestions) == 1:
+		// Auto-select the single suggestion
+		teamPlayers = players.FilterByTeam(suggestions[0])
+		if len(teamPlayers) == 0 {
+			return "", nil, fmt.Errorf("Team '%s' exists but has no players", suggestions[0])
+		}
+		return suggestions[0], teamPlayers, nil
+	case len(suggestions) > 1:
+		// Multiple suggestions - ask user to choose
+		msg := fmt.Sprintf("No team found matching '%s'\n\nDid you mean:\n", teamName)
+		for _, team := range suggestions {
+			msg += fmt.Sprintf("• %s\n", team)
+		}
+		return "", nil, errors.New(msg)
+	default:
+		return "", nil, fmt.Errorf("No team found matching '%s'", teamName)
 	}
 }
 
-// applyTeamFilters applies the specified filters to the player list
-func applyTeamFilters(players models.PlayerList, filters TeamFilters) models.PlayerList {
+// ApplyTeamFilters applies the specified filters to the player list
+func ApplyTeamFilters(players models.PlayerList, filters TeamFilters) models.PlayerList {
 	filtered := players
 
 	// Filter by status
@@ -212,8 +221,8 @@ func applyTeamFilters(players models.PlayerList, filters TeamFilters) models.Pla
 	return filtered
 }
 
-// buildTeamRosterEmbed creates a rich embed for team roster
-func buildTeamRosterEmbed(teamName string, players models.PlayerList, filters TeamFilters) *discordgo.MessageEmbed {
+// BuildTeamRosterEmbed creates a rich embed for team roster
+func BuildTeamRosterEmbed(teamName string, players models.PlayerList, filters TeamFilters) *discordgo.MessageEmbed {
 	// Group players by position
 	positionGroups := make(map[string][]models.Player)
 	positionOrder := []string{"C", "1B", "2B", "3B", "SS", "MI", "OF", "DH", "UT", "SP", "RP"}
@@ -0,0 +1,206 @@
+package discord
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	fantraxmodels "github.com/pmurley/go-fantrax/models"
+	"github.com/pmurley/ulb-bot/internal/models"
+	"github.com/pmurley/ulb-bot/internal/storage"
+)
+
+// BuildTradeAnnouncementEmbed creates the embed announcing a Fantrax trade.
+// Players are matched to the sheet for salaries, remaining contract years and
+// each team's payroll before and after, the same way !trade analyzes a
+// proposal. The sheet is read as it was before the trade, so it does not
+// matter whether it has been updated yet. Teams are listed alphabetically.
+func BuildTradeAnnouncementEmbed(trade []fantraxmodels.Transaction, players models.PlayerList, retentions []storage.Retention) *discordgo.MessageEmbed {
+	if len(trade) == 0 {
+		return nil
+	}
+	year := 2025
+
+	retained := make(map[string]float64)
+	for _, r := range retentions {
+		retained[models.NormalizeName(r.PlayerName)] = r.Percent
+	}
+
+	// Put every traded player back on the team that sent them
+	preTrade := make(models.PlayerList, len(players))
+	copy(preTrade, players)
+
+	sent := make(map[string][]models.TradedPlayer)
+	received := make(map[string][]models.TradedPlayer)
+	pieces := make(map[string][]string)
+	teamSet := make(map[string]bool)
+	multiTeam := isMultiTeam(trade)
+	matched := 0
+
+	for _, tx := range trade {
+		teamSet[tx.FromTeamName] = true
+		if tx.ToTeamName != "" {
+			teamSet[tx.ToTeamName] = true
+		}
+
+		i := matchTradedPlayer(preTrade, tx)
+		if i < 0 {
+			pieces[tx.FromTeamName] = append(pieces[tx.FromTeamName], formatTradePiece(tx, nil, multiTeam))
+			continue
+		}
+
+		preTrade[i].ULBTeam = tx.FromTeamName
+		tp := models.TradedPlayer{
+			Player:           preTrade[i],
+			RetentionPercent: retained[models.NormalizeName(tx.PlayerName)],
+		}
+		sent[tx.FromTeamName] = append(sent[tx.FromTeamName], tp)
+		received[tx.ToTeamName] = append(received[tx.ToTeamName], tp)
+		pieces[tx.FromTeamName] = append(pieces[tx.FromTeamName], formatTradePiece(tx, &tp, multiTeam))
+		matched++
+	}
+
+	teams := make([]string, 0, len(teamSet))
+	for team := range teamSet {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+
+	var description strings.Builder
+	description.WriteString("\n")
+	first := true
+	for _, team := range teams {
+		if len(pieces[team]) == 0 {
+			continue
+		}
+		if !first {
+			description.WriteString("\n**↓ ↑**\n\n")
+		}
+		first = false
+
+		description.WriteString(fmt.Sprintf("**%s** traded:\n", team))
+		for _, piece := range pieces[team] {
+			description.WriteString(piece + "\n")
+		}
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "🔄 Trade Executed",
+		Description: description.String(),
+		Color:       0xffa500, // Orange
+		Timestamp:   trade[0].ProcessedDate.Format(time.RFC3339),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Period %d • %d players involved",
+				trade[0].Period, len(trade)),
+		},
+	}
+
+	if matched > 0 {
+		var payrollDesc []string
+		for _, team := range teams {
+			before, after := preTrade.TeamPayrollChange(team, sent[team], received[team], 0, 0, year)
+			payrollDesc = append(payrollDesc, fmt.Sprintf("**%s**\nBefore: $%s\nAfter: $%s\nChange: %s",
+				team, formatNumber(before), formatNumber(after), formatPayrollChange(after-before)))
+		}
+
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   fmt.Sprintf("Team Payroll Impact (%d)", year),
+			Value:  strings.Join(payrollDesc, "\n\n"),
+			Inline: false,
+		})
+	}
+
+	if matched < len(trade) {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Note",
+			Value:  fmt.Sprintf("%d of %d players could not be matched to the sheet and are left out of the payroll impact", len(trade)-matched, len(trade)),
+			Inline: false,
+		})
+	}
+
+	if trade[0].ExecutedBy != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Executed By",
+			Value:  trade[0].ExecutedBy,
+			Inline: true,
+		})
+	}
+
+	return embed
+}
+
+// matchTradedPlayer finds a traded player in the sheet, preferring the entry
+// on one of the two teams involved for players who share a name. Returns -1
+// when the player is missing or ambiguous.
+func matchTradedPlayer(players models.PlayerList, tx fantraxmodels.Transaction) int {
+	name := models.NormalizeName(tx.PlayerName)
+	from, to := models.NormalizeName(tx.FromTeamName), models.NormalizeName(tx.ToTeamName)
+
+	var candidates []int
+	for i, p := range players {
+		if models.NormalizeName(p.Name) != name {
+			continue
+		}
+		team := models.NormalizeName(p.ULBTeam)
+		if team == from || team == to {
+			return i
+		}
+		candidates = append(candidates, i)
+	}
+
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+	return -1
+}
+
+// formatTradePiece formats one traded player with salary, contract years left
+// and retention. Multi-team trades also say where the player went.
+func formatTradePiece(tx fantraxmodels.Transaction, tp *models.TradedPlayer, multiTeam bool) string {
+	line := fmt.Sprintf("• %s (%s - %s)", tx.PlayerName, tx.PlayerPosition, tx.PlayerTeam)
+	if multiTeam && tx.ToTeamName != "" {
+		line += " → " + tx.ToTeamName
+	}
+
+	if tp == nil {
+		return line + " — not in sheet"
+	}
+
+	year := 2025
+	if salary, ok := tp.Player.GetSalary(year); ok {
+		years := tp.Player.RemainingContractYears(year)
+		line += fmt.Sprintf(" — $%s, %d yr%s left", formatNumberShort(salary), years, pluralize(years))
+	} else if tp.Player.IsFreeAgent(year) {
+		line += " — FA"
+	}
+	if tp.RetentionPercent > 0 {
+		line += fmt.Sprintf(" (retain %.0f%% = $%s)", tp.RetentionPercent, formatNumberShort(tp.GetRetainedSalary(year)))
+	}
+
+	return line
+}
+
+// isMultiTeam reports whether more than two teams are part of a trade
+func isMultiTeam(trade []fantraxmodels.Transaction) bool {
+	teams := make(map[string]bool)
+	for _, tx := range trade {
+		teams[tx.FromTeamName] = true
+		teams[tx.ToTeamName] = true
+	}
+	delete(teams, "")
+	return len(teams) > 2
+}
+
+// formatPayrollChange formats a payroll difference with its sign
+func formatPayrollChange(n int) string {
+	switch {
+	case n > 0:
+		return "+$" + formatNumber(n)
+	case n < 0:
+		return "-$" + formatNumber(abs(n))
+	default:
+		return "$0"
+	}
+}
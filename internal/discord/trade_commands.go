@@ -1,34 +1,70 @@
 package discord
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/pmurley/ulb-bot/internal/command"
 	"github.com/pmurley/ulb-bot/internal/models"
 )
 
 // handleTrade analyzes a trade between two teams
-func (hm *HandlerManager) handleTrade(s *discordgo.Session, m *discordgo.MessageCreate, args []string) {
-	if len(args) == 0 {
+func (hm *HandlerManager) handleTrade(req *command.Request, res command.Responder) {
+	if len(req.Args) == 0 {
 		helpMsg := "Usage: `!trade <team1 players> for <team2 players>`\n" +
 			"Example: `!trade Juan Soto, Aaron Judge for Shohei Ohtani`\n" +
 			"With retention: `!trade Ohtani (retain 25%) for Judge`\n" +
 			"With cash: `!trade Player, cash ($5M) for Player`\n" +
 			"Add `-v` or `--verbose` for full contract details"
-		s.ChannelMessageSend(m.ChannelID, helpMsg)
+		res.Send(helpMsg)
 		return
 	}
 
+	request, err := ParseTradeArgs(req.Args)
+	if err != nil {
+		res.Send(err.Error())
+		return
+	}
+
+	// Get players from cache (auto-reload if needed)
+	players, err := hm.ensurePlayersLoaded()
+	if err != nil {
+		command.Fail(res, "Failed to load player data", err)
+		return
+	}
+
+	analysis, err := AnalyzeTrade(players, request)
+	if err != nil {
+		res.Send(err.Error())
+		return
+	}
+
+	// Create embed
+	embed := BuildTradeEmbed(analysis, request.Verbose)
+	res.SendEmbeds(embed)
+}
+
+// TradeRequest is a parsed !trade command
+type TradeRequest struct {
+	Side1   []PlayerWithRetention
+	Side2   []PlayerWithRetention
+	Verbose bool // Show payroll changes for every contract year
+}
+
+// ParseTradeArgs parses "<players> for <players>", optionally preceded by
+// -v or --verbose. The error is a message for the user.
+func ParseTradeArgs(args []string) (TradeRequest, error) {
+	var request TradeRequest
+
 	// Check for verbose flag
-	verbose := false
-	if args[0] == "-v" || args[0] == "--verbose" {
-		verbose = true
+	if len(args) > 0 && (args[0] == "-v" || args[0] == "--verbose") {
+		request.Verbose = true
 		args = args[1:] // Remove flag from args
 		if len(args) == 0 {
-			s.ChannelMessageSend(m.ChannelID, "Please specify players after the verbose flag.")
-			return
+			return request, errors.New("Please specify players after the verbose flag.")
 		}
 	}
 
@@ -36,8 +72,7 @@ func (hm *HandlerManager) handleTrade(s *discordgo.Session, m *discordgo.Message
 	tradeStr := strings.Join(args, " ")
 	parts := strings.Split(strings.ToLower(tradeStr), " for ")
 	if len(parts) != 2 {
-		s.ChannelMessageSend(m.ChannelID, "Invalid format. Use: `!trade <players> for <players>`")
-		return
+		return request, errors.New("Invalid format. Use: `!trade <players> for <players>`")
 	}
 
 	// Get the original case version
@@ -47,24 +82,22 @@ func (hm *HandlerManager) handleTrade(s *discordgo.Session, m *discordgo.Message
 	}
 
 	// Parse player lists with retention
-	side1Info := parsePlayerList(originalParts[0])
-	side2Info := parsePlayerList(originalParts[1])
+	request.Side1 = parsePlayerList(originalParts[0])
+	request.Side2 = parsePlayerList(originalParts[1])
 
-	if len(side1Info) == 0 || len(side2Info) == 0 {
-		s.ChannelMessageSend(m.ChannelID, "Please specify at least one player on each side of the trade.")
-		return
+	if len(request.Side1) == 0 || len(request.Side2) == 0 {
+		return request, errors.New("Please specify at least one player on each side of the trade.")
 	}
 
-	// Get players from cache (auto-reload if needed)
-	players, err := hm.ensurePlayersLoaded()
-	if err != nil {
-		s.ChannelMessageSend(m.ChannelID, "Failed to load player data: "+err.Error())
-		return
-	}
+	return request, nil
+}
 
+// AnalyzeTrade looks up the players on both sides of a trade and works out
+// each team's payroll change. The error lists players that were not found.
+func AnalyzeTrade(players models.PlayerList, request TradeRequest) (TradeAnalysis, error) {
 	// Find players for each side with retention info
-	side1Players, side1Cash, side1NotFound := findPlayersWithRetention(players, side1Info)
-	side2Players, side2Cash, side2NotFound := findPlayersWithRetention(players, side2Info)
+	side1Players, side1Cash, side1NotFound := findPlayersWithRetention(players, request.Side1)
+	side2Players, side2Cash, side2NotFound := findPlayersWithRetention(players, request.Side2)
 
 	// Report not found players
 	if len(side1NotFound) > 0 || len(side2NotFound) > 0 {
@@ -75,16 +108,10 @@ func (hm *HandlerManager) handleTrade(s *discordgo.Session, m *discordgo.Message
 		if len(side2NotFound) > 0 {
 			msg += fmt.Sprintf("Side 2: %s\n", strings.Join(side2NotFound, ", "))
 		}
-		s.ChannelMessageSend(m.ChannelID, msg)
-		return
+		return TradeAnalysis{}, errors.New(msg)
 	}
 
-	// Analyze the trade
-	analysis := hm.analyzeTrade(side1Players, side2Players, side1Cash, side2Cash, verbose)
-
-	// Create embed
-	embed := buildTradeEmbed(analysis, verbose)
-	s.ChannelMessageSendEmbed(m.ChannelID, embed)
+	return analyzeTrade(players, side1Players, side2Players, side1Cash, side2Cash, request.Verbose), nil
 }
 
 // PlayerWithRetention represents a player name with optional retention percentage
@@ -230,8 +257,9 @@ type PayrollChange struct {
 	NetChange     int // PayrollAfter - PayrollBefore
 }
 
-// analyzeTrade performs analysis on the trade
-func (hm *HandlerManager) analyzeTrade(side1, side2 []models.TradedPlayer, side1Cash, side2Cash int, verbose bool) TradeAnalysis {
+// analyzeTrade performs analysis on the trade, using allPlayers for the
+// teams' full payrolls
+func analyzeTrade(allPlayers models.PlayerList, side1, side2 []models.TradedPlayer, side1Cash, side2Cash int, verbose bool) TradeAnalysis {
 	analysis := TradeAnalysis{
 		Side1Players:         side1,
 		Side2Players:         side2,
@@ -243,13 +271,6 @@ func (hm *HandlerManager) analyzeTrade(side1, side2 []models.TradedPlayer, side1
 		YearlyPayrollChanges: make(map[string]map[int]PayrollChange),
 	}
 
-	// Get all players from cache to calculate full team payrolls
-	allPlayers, err := hm.ensurePlayersLoaded()
-	if err != nil {
-		// If we can't load players, we can't calculate payrolls accurately
-		// But we can still show the trade structure
-		allPlayers = models.PlayerList{}
-	}
 	year := 2025
 
 	// Group players by team and track which teams are involved
@@ -269,65 +290,10 @@ func (hm *HandlerManager) analyzeTrade(side1, side2 []models.TradedPlayer, side1
 
 	// Calculate current payroll for each involved team
 	for team := range involvedTeams {
-		change := PayrollChange{TeamName: team}
-
-		// Calculate total payroll before trade
-		teamPlayers := allPlayers.FilterByTeam(team)
-		for _, p := range teamPlayers {
-			if salary, ok := p.GetSalary(year); ok {
-				change.PayrollBefore += salary
-			}
-		}
-
-		// Start with current payroll
-		change.PayrollAfter = change.PayrollBefore
-
-		// Subtract outgoing players (full salary) and add back retained salary
-		if outgoingPlayers, exists := analysis.Side1Teams[team]; exists {
-			for _, tp := range outgoingPlayers {
-				if salary, ok := tp.Player.GetSalary(year); ok {
-					// Remove full salary
-					change.PayrollAfter -= salary
-					// Add back retained portion
-					change.PayrollAfter += tp.GetRetainedSalary(year)
-				}
-			}
-		}
-		if outgoingPlayers, exists := analysis.Side2Teams[team]; exists {
-			for _, tp := range outgoingPlayers {
-				if salary, ok := tp.Player.GetSalary(year); ok {
-					// Remove full salary
-					change.PayrollAfter -= salary
-					// Add back retained portion
-					change.PayrollAfter += tp.GetRetainedSalary(year)
-				}
-			}
-		}
-
-		// Add incoming players (only the portion not retained)
-		// If this team is trading away side1 players, they receive side2 players
-		if _, isSide1Team := analysis.Side1Teams[team]; isSide1Team {
-			for _, tp := range side2 {
-				// Add only the traded portion of salary
-				change.PayrollAfter += tp.GetTradedSalary(year)
-			}
-			// Also receive side2's cash
-			change.PayrollAfter += analysis.Side2Cash
-			// And pay side1's cash
-			change.PayrollAfter -= analysis.Side1Cash
-		}
-		// If this team is trading away side2 players, they receive side1 players
-		if _, isSide2Team := analysis.Side2Teams[team]; isSide2Team {
-			for _, tp := range side1 {
-				// Add only the traded portion of salary
-				change.PayrollAfter += tp.GetTradedSalary(year)
-			}
-			// Also receive side1's cash
-			change.PayrollAfter += analysis.Side1Cash
-			// And pay side2's cash
-			change.PayrollAfter -= analysis.Side2Cash
-		}
+		outgoing, incoming, cashIn, cashOut := analysis.teamSides(team)
 
+		change := PayrollChange{TeamName: team}
+		change.PayrollBefore, change.PayrollAfter = allPlayers.TeamPayrollChange(team, outgoing, incoming, cashIn, cashOut, year)
 		change.NetChange = change.PayrollAfter - change.PayrollBefore
 		analysis.PayrollChanges[team] = change
 	}
@@ -336,52 +302,12 @@ func (hm *HandlerManager) analyzeTrade(side1, side2 []models.TradedPlayer, side1
 	if verbose {
 		for team := range involvedTeams {
 			analysis.YearlyPayrollChanges[team] = make(map[int]PayrollChange)
+			outgoing, incoming, _, _ := analysis.teamSides(team)
 
 			// Calculate for years 2025-2030 (or until all players are FA)
 			for year := 2025; year <= 2030; year++ {
 				change := PayrollChange{TeamName: team}
-
-				// Calculate total payroll before trade for this year
-				teamPlayers := allPlayers.FilterByTeam(team)
-				for _, p := range teamPlayers {
-					if salary, ok := p.GetSalary(year); ok {
-						change.PayrollBefore += salary
-					}
-				}
-
-				// Start with current payroll
-				change.PayrollAfter = change.PayrollBefore
-
-				// Subtract outgoing players (full salary) and add back retained salary
-				if outgoingPlayers, exists := analysis.Side1Teams[team]; exists {
-					for _, tp := range outgoingPlayers {
-						if salary, ok := tp.Player.GetSalary(year); ok {
-							change.PayrollAfter -= salary
-							change.PayrollAfter += tp.GetRetainedSalary(year)
-						}
-					}
-				}
-				if outgoingPlayers, exists := analysis.Side2Teams[team]; exists {
-					for _, tp := range outgoingPlayers {
-						if salary, ok := tp.Player.GetSalary(year); ok {
-							change.PayrollAfter -= salary
-							change.PayrollAfter += tp.GetRetainedSalary(year)
-						}
-					}
-				}
-
-				// Add incoming players (only the traded portion)
-				if _, isSide1Team := analysis.Side1Teams[team]; isSide1Team {
-					for _, tp := range side2 {
-						change.PayrollAfter += tp.GetTradedSalary(year)
-					}
-				}
-				if _, isSide2Team := analysis.Side2Teams[team]; isSide2Team {
-					for _, tp := range side1 {
-						change.PayrollAfter += tp.GetTradedSalary(year)
-					}
-				}
-
+				change.PayrollBefore, change.PayrollAfter = allPlayers.TeamPayrollChange(team, outgoing, incoming, 0, 0, year)
 				change.NetChange = change.PayrollAfter - change.PayrollBefore
 				analysis.YearlyPayrollChanges[team][year] = change
 			}
@@ -391,8 +317,28 @@ func (hm *HandlerManager) analyzeTrade(side1, side2 []models.TradedPlayer, side1
 	return analysis
 }
 
-// buildTradeEmbed creates an embed for the trade analysis
-func buildTradeEmbed(analysis TradeAnalysis, verbose bool) *discordgo.MessageEmbed {
+// teamSides returns what a team sends and receives in the trade. A team on
+// side 1 receives everything on side 2, and vice versa.
+func (analysis TradeAnalysis) teamSides(team string) (outgoing, incoming []models.TradedPlayer, cashIn, cashOut int) {
+	outgoing = append(outgoing, analysis.Side1Teams[team]...)
+	outgoing = append(outgoing, analysis.Side2Teams[team]...)
+
+	if _, isSide1Team := analysis.Side1Teams[team]; isSide1Team {
+		incoming = append(incoming, analysis.Side2Players...)
+		cashIn += analysis.Side2Cash
+		cashOut += analysis.Side1Cash
+	}
+	if _, isSide2Team := analysis.Side2Teams[team]; isSide2Team {
+		incoming = append(incoming, analysis.Side1Players...)
+		cashIn += analysis.Side1Cash
+		cashOut += analysis.Side2Cash
+	}
+
+	return outgoing, incoming, cashIn, cashOut
+}
+
+// BuildTradeEmbed creates an embed for the trade analysis
+func BuildTradeEmbed(analysis TradeAnalysis, verbose bool) *discordgo.MessageEmbed {
 	embed := &discordgo.MessageEmbed{
 		Title: "Trade Analysis",
 		Color: 0x3498db,
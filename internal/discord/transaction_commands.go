@@ -0,0 +1,242 @@
+package discord
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pmurley/go-fantrax/models"
+	"github.com/pmurley/ulb-bot/internal/command"
+	"github.com/pmurley/ulb-bot/internal/storage"
+)
+
+const (
+	// TransactionsPerPage is how many results one page of !transactions shows
+	TransactionsPerPage = 15
+	transactionDateFmt  = "2006-01-02"
+)
+
+// TransactionQuery is a parsed !transactions command
+type TransactionQuery struct {
+	Filter storage.TransactionFilter
+	Page   int  // 1-based page number
+	CSV    bool // Attach the full result as a CSV file
+}
+
+// handleTransactions searches the stored Fantrax transaction history
+func (hm *HandlerManager) handleTransactions(req *command.Request, res command.Responder) {
+	query, err := ParseTransactionQuery(req.Args)
+	if err != nil {
+		res.Send(fmt.Sprintf("Invalid arguments: %s\n%s", err, transactionsUsage))
+		return
+	}
+
+	allTransactions, err := hm.store.Transactions().GetAllTransactions()
+	if err != nil {
+		hm.logger.Error("Failed to read transactions:", err)
+		res.Send("Failed to load transaction history.")
+		return
+	}
+
+	matches := storage.FilterTransactions(allTransactions, query.Filter)
+	if len(matches) == 0 {
+		res.Send("No transactions found matching those filters.")
+		return
+	}
+
+	totalPages := (len(matches) + TransactionsPerPage - 1) / TransactionsPerPage
+	if query.Page > totalPages {
+		res.Send(fmt.Sprintf("Page %d is out of range, there are only %d pages.", query.Page, totalPages))
+		return
+	}
+
+	embed := BuildTransactionsEmbed(matches, query, totalPages)
+	if err := res.SendEmbeds(embed); err != nil {
+		hm.logger.Error("Failed to send transactions embed: ", err)
+		command.Fail(res, "Failed to display transactions", err)
+		return
+	}
+
+	if query.CSV {
+		var buf bytes.Buffer
+		if err := storage.WriteTransactionsCSV(&buf, matches); err != nil {
+			hm.logger.Error("Failed to build transactions CSV: ", err)
+			command.Fail(res, "Failed to build CSV", err)
+			return
+		}
+		if err := res.SendFile("transactions.csv", "", &buf); err != nil {
+			hm.logger.Error("Failed to send transactions CSV: ", err)
+			command.Fail(res, "Failed to send CSV", err)
+		}
+	}
+}
+
+const transactionsUsage = "Usage: `!transactions [--team=<team>] [--player=<name>] [--type=<CLAIM|DROP|TRADE>] " +
+	"[--claim=<FA|WW>] [--by=<executor>] [--period=<n>] [--from=YYYY-MM-DD] [--to=YYYY-MM-DD] [--page=<n>] [--csv]`"
+
+// ParseTransactionQuery parses !transactions arguments. Flag values may
+// contain spaces ("--team=Havana Bananas"): words after a flag are added to
+// its value until the next flag.
+func ParseTransactionQuery(args []string) (TransactionQuery, error) {
+	query := TransactionQuery{Page: 1}
+
+	var flags [][2]string
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "--") {
+			parts := strings.SplitN(arg, "=", 2)
+			value := ""
+			if len(parts) == 2 {
+				value = parts[1]
+			}
+			flags = append(flags, [2]string{strings.ToLower(parts[0]), value})
+		} else if len(flags) > 0 {
+			last := &flags[len(flags)-1]
+			last[1] = strings.TrimSpace(last[1] + " " + arg)
+		} else {
+			return query, fmt.Errorf("unexpected argument '%s'", arg)
+		}
+	}
+
+	for _, flag := range flags {
+		name, value := flag[0], flag[1]
+		if value == "" && name != "--csv" {
+			return query, fmt.Errorf("missing value for %s", name)
+		}
+
+		switch name {
+		case "--team":
+			query.Filter.Team = value
+		case "--player":
+			query.Filter.Player = value
+		case "--type":
+			query.Filter.Type = strings.ToUpper(value)
+		case "--claim", "--claim-type":
+			query.Filter.ClaimType = strings.ToUpper(value)
+		case "--by", "--executed-by":
+			query.Filter.ExecutedBy = value
+		case "--period":
+			period, err := strconv.Atoi(value)
+			if err != nil || period <= 0 {
+				return query, fmt.Errorf("invalid period '%s'", value)
+			}
+			query.Filter.Period = period
+		case "--from":
+			from, err := time.ParseInLocation(transactionDateFmt, value, time.Local)
+			if err != nil {
+				return query, fmt.Errorf("invalid date '%s', use YYYY-MM-DD", value)
+			}
+			query.Filter.From = from
+		case "--to":
+			to, err := time.ParseInLocation(transactionDateFmt, value, time.Local)
+			if err != nil {
+				return query, fmt.Errorf("invalid date '%s', use YYYY-MM-DD", value)
+			}
+			// Include the whole "to" day
+			query.Filter.To = to.AddDate(0, 0, 1)
+		case "--page":
+			page, err := strconv.Atoi(value)
+			if err != nil || page <= 0 {
+				return query, fmt.Errorf("invalid page '%s'", value)
+			}
+			query.Page = page
+		case "--csv":
+			query.CSV = true
+		default:
+			return query, fmt.Errorf("unknown option %s", name)
+		}
+	}
+
+	return query, nil
+}
+
+// BuildTransactionsEmbed creates an embed for one page of transaction results
+func BuildTransactionsEmbed(matches []models.Transaction, query TransactionQuery, totalPages int) *discordgo.MessageEmbed {
+	start := (query.Page - 1) * TransactionsPerPage
+	end := start + TransactionsPerPage
+	if end > len(matches) {
+		end = len(matches)
+	}
+
+	var description strings.Builder
+	for _, tx := range matches[start:end] {
+		description.WriteString(FormatTransactionLine(tx))
+		description.WriteString("\n")
+	}
+
+	embed := &discordgo.MessageEmbed{
+		Title:       "Transaction History",
+		Description: description.String(),
+		Color:       0x3498db,
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: fmt.Sprintf("Page %d of %d • %d transaction%s", query.Page, totalPages, len(matches), pluralize(len(matches))),
+		},
+	}
+
+	if filterDesc := describeTransactionFilter(query.Filter); filterDesc != "" {
+		embed.Fields = append(embed.Fields, &discordgo.MessageEmbedField{
+			Name:   "Filters",
+			Value:  filterDesc,
+			Inline: false,
+		})
+	}
+
+	return embed
+}
+
+// FormatTransactionLine formats a single transaction as one line of text
+func FormatTransactionLine(tx models.Transaction) string {
+	date := tx.ProcessedDate.Format(transactionDateFmt)
+
+	switch tx.Type {
+	case "TRADE":
+		return fmt.Sprintf("`%s` **TRADE** %s → %s: %s", date, tx.FromTeamName, tx.ToTeamName, tx.PlayerName)
+	case "CLAIM":
+		line := fmt.Sprintf("`%s` **CLAIM", date)
+		if tx.ClaimType != "" {
+			line += " " + tx.ClaimType
+		}
+		line += fmt.Sprintf("** %s: %s (%s)", tx.TeamName, tx.PlayerName, tx.PlayerPosition)
+		if tx.BidAmount != "" {
+			line += fmt.Sprintf(" $%s", tx.BidAmount)
+		}
+		if strings.EqualFold(tx.ExecutedBy, "commissioner") {
+			line += " *(commissioner)*"
+		}
+		return line
+	default:
+		return fmt.Sprintf("`%s` **%s** %s: %s (%s)", date, tx.Type, tx.TeamName, tx.PlayerName, tx.PlayerPosition)
+	}
+}
+
+// describeTransactionFilter summarises the active filters
+func describeTransactionFilter(f storage.TransactionFilter) string {
+	var parts []string
+	if f.Team != "" {
+		parts = append(parts, "Team: "+f.Team)
+	}
+	if f.Player != "" {
+		parts = append(parts, "Player: "+f.Player)
+	}
+	if f.Type != "" {
+		parts = append(parts, "Type: "+f.Type)
+	}
+	if f.ClaimType != "" {
+		parts = append(parts, "Claim: "+f.ClaimType)
+	}
+	if f.ExecutedBy != "" {
+		parts = append(parts, "By: "+f.ExecutedBy)
+	}
+	if f.Period != 0 {
+		parts = append(parts, fmt.Sprintf("Period: %d", f.Period))
+	}
+	if !f.From.IsZero() {
+		parts = append(parts, "From: "+f.From.Format(transactionDateFmt))
+	}
+	if !f.To.IsZero() {
+		parts = append(parts, "To: "+f.To.AddDate(0, 0, -1).Format(transactionDateFmt))
+	}
+	return strings.Join(parts, " | ")
+}
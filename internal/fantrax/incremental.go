@@ -0,0 +1,46 @@
+package fantrax
+
+import (
+	"fmt"
+
+	"github.com/pmurley/go-fantrax/models"
+)
+
+// Transaction history views
+const (
+	ViewClaimDrop = "CLAIM_DROP"
+	ViewTrade     = "TRADE"
+)
+
+// incrementalPageSize is small because a normal poll finds nothing new
+const incrementalPageSize = 50
+
+// GetTransactionsUntil pages through a transaction view, newest first, and
+// returns every transaction for which known returns false. Paging stops at
+// the first page that contains a known transaction, so a poll only downloads
+// what is new since the last processed transaction.
+func (c *Client) GetTransactionsUntil(view string, known func(models.Transaction) bool) ([]models.Transaction, error) {
+	var newTransactions []models.Transaction
+
+	for page := 1; ; page++ {
+		transactions, pagination, err := c.Client.GetTransactionsPaginated(view, page, incrementalPageSize, true)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch %s transactions: %w", view, err)
+		}
+
+		reachedKnown := false
+		for _, tx := range transactions {
+			if known(tx) {
+				reachedKnown = true
+				continue
+			}
+			newTransactions = append(newTransactions, tx)
+		}
+
+		if reachedKnown || len(transactions) == 0 || pagination == nil || page >= pagination.TotalNumPages {
+			break
+		}
+	}
+
+	return newTransactions, nil
+}
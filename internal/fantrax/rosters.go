@@ -0,0 +1,46 @@
+package fantrax
+
+import (
+	"fmt"
+
+	"github.com/pmurley/go-fantrax/models"
+)
+
+// TeamRoster is one fantasy team's current Fantrax roster
+type TeamRoster struct {
+	TeamID   string
+	TeamName string
+	Active   []models.RosterPlayer // Active, reserve and injured reserve slots
+	Minors   []models.RosterPlayer
+}
+
+// GetTeamRosters fetches the current period roster of every team in the league
+func (c *Client) GetTeamRosters() ([]TeamRoster, error) {
+	// Any roster response lists the league's teams
+	own, err := c.Client.GetCurrentPeriodTeamRosterInfo("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch league teams: %w", err)
+	}
+
+	rosters := make([]TeamRoster, 0, len(own.LeagueTeams))
+	for _, team := range own.LeagueTeams {
+		roster, err := c.Client.GetCurrentPeriodTeamRosterInfo(team.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch roster for %s: %w", team.Name, err)
+		}
+
+		active := make([]models.RosterPlayer, 0, len(roster.ActiveRoster)+len(roster.ReserveRoster)+len(roster.InjuredReserve))
+		active = append(active, roster.ActiveRoster...)
+		active = append(active, roster.ReserveRoster...)
+		active = append(active, roster.InjuredReserve...)
+
+		rosters = append(rosters, TeamRoster{
+			TeamID:   team.ID,
+			TeamName: team.Name,
+			Active:   active,
+			Minors:   roster.MinorsRoster,
+		})
+	}
+
+	return rosters, nil
+}
@@ -0,0 +1,179 @@
+package fantrax
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pmurley/go-fantrax/models"
+	"github.com/pmurley/ulb-bot/internal/storage"
+)
+
+// ScriptEntry is one transaction in a JSON script. After is how long after
+// the bot starts the transaction appears, e.g. "90s"; "0s" or empty makes it
+// part of the history that exists at startup.
+type ScriptEntry struct {
+	After       string             `json:"after,omitempty"`
+	Transaction models.Transaction `json:"transaction"`
+}
+
+// scriptedTransaction is a transaction and the time it becomes visible
+type scriptedTransaction struct {
+	at time.Time
+	tx models.Transaction
+}
+
+// ScriptSource is a TransactionSource that replays transactions from a local
+// file instead of calling Fantrax, for staging and local development.
+//
+// A .json script is a list of ScriptEntry values. A .csv script uses the
+// transaction storage format (a copy of transactions.csv works) and releases
+// each transaction after the gap between its ProcessedDate and the earliest
+// one. Either way, delays are divided by speed.
+type ScriptSource struct {
+	mu      sync.Mutex
+	entries []scriptedTransaction
+	now     func() time.Time
+}
+
+// NewScriptSource loads a transaction script; the replay clock starts now
+func NewScriptSource(path string, speed float64) (*ScriptSource, error) {
+	if speed <= 0 {
+		speed = 1
+	}
+
+	var entries []ScriptEntry
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read transaction script: %w", err)
+		}
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("failed to parse transaction script: %w", err)
+		}
+	case ".csv":
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open transaction script: %w", err)
+		}
+		defer file.Close()
+
+		transactions, err := storage.ReadTransactionsCSV(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse transaction script: %w", err)
+		}
+		entries = csvScriptEntries(transactions)
+	default:
+		return nil, fmt.Errorf("unsupported transaction script format %q, use .json or .csv", filepath.Ext(path))
+	}
+
+	start := time.Now()
+	source := &ScriptSource{now: time.Now}
+	tradeGroupSizes := make(map[string]int)
+
+	for i, entry := range entries {
+		var after time.Duration
+		if entry.After != "" {
+			d, err := time.ParseDuration(entry.After)
+			if err != nil {
+				return nil, fmt.Errorf("invalid delay %q for script entry %d: %w", entry.After, i+1, err)
+			}
+			after = time.Duration(float64(d) / speed)
+		}
+
+		tx := entry.Transaction
+		at := start.Add(after)
+		if tx.ID == "" {
+			tx.ID = fmt.Sprintf("script-%d", i+1)
+		}
+		if tx.ProcessedDate.IsZero() {
+			tx.ProcessedDate = at
+		}
+		if tx.TradeGroupID != "" {
+			tradeGroupSizes[tx.TradeGroupID]++
+		}
+
+		source.entries = append(source.entries, scriptedTransaction{at: at, tx: tx})
+	}
+
+	for i := range source.entries {
+		tx := &source.entries[i].tx
+		if tx.TradeGroupID != "" && tx.TradeGroupSize == 0 {
+			tx.TradeGroupSize = tradeGroupSizes[tx.TradeGroupID]
+		}
+	}
+
+	return source, nil
+}
+
+// csvScriptEntries spaces CSV transactions out by their processed dates
+func csvScriptEntries(transactions []models.Transaction) []ScriptEntry {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	earliest := transactions[0].ProcessedDate
+	for _, tx := range transactions {
+		if tx.ProcessedDate.Before(earliest) {
+			earliest = tx.ProcessedDate
+		}
+	}
+
+	entries := make([]ScriptEntry, 0, len(transactions))
+	for _, tx := range transactions {
+		entries = append(entries, ScriptEntry{
+			After:       tx.ProcessedDate.Sub(earliest).String(),
+			Transaction: tx,
+		})
+	}
+	return entries
+}
+
+// visible returns the transactions released so far, newest first
+func (s *ScriptSource) visible() []models.Transaction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	var released []scriptedTransaction
+	for _, entry := range s.entries {
+		if !entry.at.After(now) {
+			released = append(released, entry)
+		}
+	}
+
+	sort.SliceStable(released, func(i, j int) bool {
+		return released[i].at.After(released[j].at)
+	})
+
+	transactions := make([]models.Transaction, 0, len(released))
+	for _, entry := range released {
+		transactions = append(transactions, entry.tx)
+	}
+	return transactions
+}
+
+// GetTransactionsFromFantrax returns every transaction released so far
+func (s *ScriptSource) GetTransactionsFromFantrax() ([]models.Transaction, error) {
+	return s.visible(), nil
+}
+
+// GetTransactionsUntil returns released transactions in a view that are not yet known
+func (s *ScriptSource) GetTransactionsUntil(view string, known func(models.Transaction) bool) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	for _, tx := range s.visible() {
+		if (view == ViewTrade) != (tx.Type == "TRADE") {
+			continue
+		}
+		if !known(tx) {
+			transactions = append(transactions, tx)
+		}
+	}
+	return transactions, nil
+}
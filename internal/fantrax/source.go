@@ -0,0 +1,84 @@
+package fantrax
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pmurley/go-fantrax/models"
+)
+
+// TransactionSource supplies league transactions to the transaction monitor
+type TransactionSource interface {
+	// GetTransactionsFromFantrax returns the full history, including trades
+	GetTransactionsFromFantrax() ([]models.Transaction, error)
+
+	// GetTransactionsUntil returns the newest transactions in a view
+	// (ViewClaimDrop or ViewTrade) up to the first one known returns true for
+	GetTransactionsUntil(view string, known func(models.Transaction) bool) ([]models.Transaction, error)
+}
+
+// LiveSource is a TransactionSource backed by the Fantrax API. It logs in on
+// first use and keeps the client between polls; after a failed request the
+// client is dropped so the next call logs in again.
+type LiveSource struct {
+	leagueID string
+	mu       sync.Mutex
+	client   *Client
+}
+
+// NewLiveSource creates a Fantrax API source for a league
+func NewLiveSource(leagueID string) *LiveSource {
+	return &LiveSource{leagueID: leagueID}
+}
+
+// GetTransactionsFromFantrax returns the full history, including trades
+func (s *LiveSource) GetTransactionsFromFantrax() ([]models.Transaction, error) {
+	client, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := client.GetTransactionsFromFantrax()
+	if err != nil {
+		s.reset()
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// GetTransactionsUntil returns the newest transactions in a view up to the first known one
+func (s *LiveSource) GetTransactionsUntil(view string, known func(models.Transaction) bool) ([]models.Transaction, error) {
+	client, err := s.connect()
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := client.GetTransactionsUntil(view, known)
+	if err != nil {
+		s.reset()
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// connect returns the logged-in client, logging in if needed
+func (s *LiveSource) connect() (*Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.client == nil {
+		client, err := NewFantraxClient(s.leagueID, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Fantrax client: %w", err)
+		}
+		s.client = client
+	}
+	return s.client, nil
+}
+
+// reset forces a fresh login on the next call, in case the session expired
+func (s *LiveSource) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.client = nil
+}
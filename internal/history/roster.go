@@ -0,0 +1,207 @@
+package history
+
+import (
+	"sort"
+	"time"
+
+	"github.com/pmurley/go-fantrax/models"
+	ulbmodels "github.com/pmurley/ulb-bot/internal/models"
+)
+
+// RosterEntry is a player on a replayed roster
+type RosterEntry struct {
+	PlayerName string
+	PlayerID   string
+	Position   string
+	MLBTeam    string
+	Since      time.Time // When the player joined the team
+	Via        EventKind // How the player joined the team
+}
+
+// Rosters maps each team name to the players on it at a point in time. Only
+// players that appear in the transaction log are known; anyone acquired
+// before tracking began is missing.
+type Rosters map[string][]RosterEntry
+
+// Teams returns the team names in sorted order
+func (r Rosters) Teams() []string {
+	teams := make([]string, 0, len(r))
+	for team := range r {
+		teams = append(teams, team)
+	}
+	sort.Strings(teams)
+	return teams
+}
+
+// Ledger is the result of replaying the transaction log
+type Ledger struct {
+	Rosters Rosters
+	// LastTeam maps every player seen in the log (keyed by PlayerKey) to the
+	// team the log last left them on, or "" if they were dropped
+	LastTeam map[string]string
+	// Names maps PlayerKey back to the player's most recent name
+	Names map[string]string
+	// Applied is the number of transactions replayed
+	Applied int
+}
+
+// ReplayUntil replays every transaction processed before the given time
+func ReplayUntil(transactions []models.Transaction, until time.Time) *Ledger {
+	return replay(transactions, func(tx models.Transaction) bool {
+		return tx.ProcessedDate.Before(until)
+	})
+}
+
+// ReplayUntilPeriod replays every transaction from periods before the given
+// one, giving the rosters at the start of that period
+func ReplayUntilPeriod(transactions []models.Transaction, period int) *Ledger {
+	return replay(transactions, func(tx models.Transaction) bool {
+		return tx.Period < period
+	})
+}
+
+// ReplayAll replays the whole transaction log, giving the current rosters
+func ReplayAll(transactions []models.Transaction) *Ledger {
+	return replay(transactions, func(models.Transaction) bool { return true })
+}
+
+// replay applies the included transactions in processing order
+func replay(transactions []models.Transaction, include func(models.Transaction) bool) *Ledger {
+	ordered := make([]models.Transaction, 0, len(transactions))
+	for _, tx := range transactions {
+		if include(tx) {
+			ordered = append(ordered, tx)
+		}
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].ProcessedDate.Before(ordered[j].ProcessedDate)
+	})
+
+	ledger := &Ledger{
+		LastTeam: make(map[string]string),
+		Names:    make(map[string]string),
+	}
+	rosters := make(map[string]map[string]RosterEntry)
+
+	remove := func(team, key string) {
+		if players, exists := rosters[team]; exists {
+			delete(players, key)
+		}
+	}
+	add := func(team, key string, entry RosterEntry) {
+		// A player can only be on one roster, whatever the log missed
+		if previous, exists := ledger.LastTeam[key]; exists && previous != "" {
+			remove(previous, key)
+		}
+		if _, exists := rosters[team]; !exists {
+			rosters[team] = make(map[string]RosterEntry)
+		}
+		rosters[team][key] = entry
+		ledger.LastTeam[key] = team
+	}
+
+	for _, tx := range ordered {
+		key := PlayerKey(tx)
+		ledger.Names[key] = tx.PlayerName
+		entry := RosterEntry{
+			PlayerName: tx.PlayerName,
+			PlayerID:   tx.PlayerID,
+			Position:   tx.PlayerPosition,
+			MLBTeam:    tx.PlayerTeam,
+			Since:      tx.ProcessedDate,
+		}
+
+		switch tx.Type {
+		case "CLAIM":
+			entry.Via = claimKind(tx)
+			add(tx.TeamName, key, entry)
+		case "DROP":
+			remove(tx.TeamName, key)
+			ledger.LastTeam[key] = ""
+		case "TRADE":
+			entry.Via = EventTrade
+			remove(tx.FromTeamName, key)
+			add(tx.ToTeamName, key, entry)
+		default:
+			continue
+		}
+		ledger.Applied++
+	}
+
+	ledger.Rosters = make(Rosters)
+	for team, players := range rosters {
+		entries := make([]RosterEntry, 0, len(players))
+		for _, entry := range players {
+			entries = append(entries, entry)
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return entries[i].PlayerName < entries[j].PlayerName
+		})
+		ledger.Rosters[team] = entries
+	}
+
+	return ledger
+}
+
+// PlayerKey identifies a player across transactions, preferring the Fantrax ID
+func PlayerKey(tx models.Transaction) string {
+	if tx.PlayerID != "" {
+		return tx.PlayerID
+	}
+	return "name:" + ulbmodels.NormalizeName(tx.PlayerName)
+}
+
+// Discrepancy is a player whose replayed team differs from the sheet
+type Discrepancy struct {
+	PlayerName string
+	LogTeam    string // "" means the log has the player dropped
+	SheetTeam  string // "" means unowned or missing from the sheet
+	InSheet    bool
+}
+
+// CheckConsistency compares the team the transaction log last left each
+// player on with the sheet's ULBTeam. Players the log never mentions are
+// skipped, since their acquisition predates tracking.
+func (l *Ledger) CheckConsistency(players ulbmodels.PlayerList) []Discrepancy {
+	sheet := make(map[string][]ulbmodels.Player)
+	for _, p := range players {
+		key := ulbmodels.NormalizeName(p.Name)
+		sheet[key] = append(sheet[key], p)
+	}
+
+	var discrepancies []Discrepancy
+	for key, logTeam := range l.LastTeam {
+		name := l.Names[key]
+		entries, inSheet := sheet[ulbmodels.NormalizeName(name)]
+
+		sheetTeam := ""
+		matched := false
+		for _, p := range entries {
+			if ulbmodels.NormalizeName(p.ULBTeam) == ulbmodels.NormalizeName(logTeam) {
+				matched = true
+				break
+			}
+			if sheetTeam == "" {
+				sheetTeam = p.ULBTeam
+			}
+		}
+		if matched || (!inSheet && logTeam == "") {
+			continue
+		}
+
+		discrepancies = append(discrepancies, Discrepancy{
+			PlayerName: name,
+			LogTeam:    logTeam,
+			SheetTeam:  sheetTeam,
+			InSheet:    inSheet,
+		})
+	}
+
+	sort.Slice(discrepancies, func(i, j int) bool {
+		if discrepancies[i].LogTeam != discrepancies[j].LogTeam {
+			return discrepancies[i].LogTeam < discrepancies[j].LogTeam
+		}
+		return discrepancies[i].PlayerName < discrepancies[j].PlayerName
+	})
+	return discrepancies
+}
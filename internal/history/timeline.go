@@ -0,0 +1,186 @@
+package history
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pmurley/go-fantrax/models"
+	ulbmodels "github.com/pmurley/ulb-bot/internal/models"
+)
+
+// EventKind identifies what happened to a player
+type EventKind string
+
+const (
+	EventSigning       EventKind = "SIGNING"        // Free agent claim
+	EventWaiverClaim   EventKind = "WAIVER_CLAIM"   // Waiver wire claim
+	EventPromotion     EventKind = "PROMOTION"      // Commissioner-executed claim (40-man promotion)
+	EventDrop          EventKind = "DROP"           // Fantrax drop
+	EventTrade         EventKind = "TRADE"          // One piece of a trade
+	EventDFA           EventKind = "DFA"            // Manual !dfa waiver record
+	EventWaiverExpired EventKind = "WAIVER_EXPIRED" // Waiver period ended unclaimed
+)
+
+// dropWaiverWindow is how soon after a Fantrax DROP an automatic waiver
+// record is created. Waivers started inside it belong to the drop.
+const dropWaiverWindow = 24 * time.Hour
+
+// Event is a single entry in a player's timeline
+type Event struct {
+	Time      time.Time
+	Kind      EventKind
+	Team      string // Team that acted (signed, dropped, received the player)
+	FromTeam  string // Trades only
+	BidAmount string // Signings and waiver claims
+	Period    int
+
+	// OtherPieces holds every other transaction in the same trade group
+	OtherPieces []models.Transaction
+
+	// Waiver is set for DFA and waiver-expired events
+	Waiver *ulbmodels.Waiver
+}
+
+// Timeline builds a chronological history for a player from the stored
+// Fantrax transactions and waiver records. Transactions are matched by name
+// and then by Fantrax player ID, so entries recorded under a different
+// spelling of the name are still included.
+func Timeline(playerName string, transactions []models.Transaction, waivers []*ulbmodels.Waiver) []Event {
+	playerIDs := make(map[string]bool)
+	for _, tx := range transactions {
+		if strings.EqualFold(tx.PlayerName, playerName) && tx.PlayerID != "" {
+			playerIDs[tx.PlayerID] = true
+		}
+	}
+
+	tradeGroups := make(map[string][]models.Transaction)
+	for _, tx := range transactions {
+		if tx.Type == "TRADE" && tx.TradeGroupID != "" {
+			tradeGroups[tx.TradeGroupID] = append(tradeGroups[tx.TradeGroupID], tx)
+		}
+	}
+
+	var events []Event
+	var drops []time.Time
+
+	for _, tx := range transactions {
+		if !strings.EqualFold(tx.PlayerName, playerName) && !playerIDs[tx.PlayerID] {
+			continue
+		}
+
+		event := Event{
+			Time:   tx.ProcessedDate,
+			Team:   tx.TeamName,
+			Period: tx.Period,
+		}
+
+		switch tx.Type {
+		case "CLAIM":
+			event.BidAmount = tx.BidAmount
+			event.Kind = claimKind(tx)
+		case "DROP":
+			event.Kind = EventDrop
+			drops = append(drops, tx.ProcessedDate)
+		case "TRADE":
+			event.Kind = EventTrade
+			event.Team = tx.ToTeamName
+			event.FromTeam = tx.FromTeamName
+			for _, other := range tradeGroups[tx.TradeGroupID] {
+				if other.ID != tx.ID {
+					event.OtherPieces = append(event.OtherPieces, other)
+				}
+			}
+		default:
+			continue
+		}
+
+		events = append(events, event)
+	}
+
+	// Each waiver is stored once per notified owner, so collapse on message ID
+	seen := make(map[string]bool)
+	for _, waiver := range waivers {
+		if !strings.EqualFold(waiver.PlayerName, playerName) {
+			continue
+		}
+		key := waiver.MessageID + "|" + waiver.StartTime.String()
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		// Drops already appear from the transaction log; only add the DFA
+		// entry for waivers that were started manually
+		if !followsDrop(waiver.StartTime, drops) {
+			events = append(events, Event{
+				Time:   waiver.StartTime,
+				Kind:   EventDFA,
+				Team:   waiver.TeamName,
+				Waiver: waiver,
+			})
+		}
+
+		if waiver.Processed && !waiver.Cancelled {
+			events = append(events, Event{
+				Time:   waiver.EndTime,
+				Kind:   EventWaiverExpired,
+				Team:   waiver.TeamName,
+				Waiver: waiver,
+			})
+		}
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+
+	return events
+}
+
+// claimKind classifies a CLAIM transaction
+func claimKind(tx models.Transaction) EventKind {
+	switch {
+	case strings.EqualFold(tx.ExecutedBy, "commissioner"):
+		return EventPromotion
+	case tx.ClaimType == "WW":
+		return EventWaiverClaim
+	default:
+		return EventSigning
+	}
+}
+
+// followsDrop reports whether a waiver started shortly after one of the drops
+func followsDrop(start time.Time, drops []time.Time) bool {
+	for _, drop := range drops {
+		if !start.Before(drop) && start.Sub(drop) <= dropWaiverWindow {
+			return true
+		}
+	}
+	return false
+}
+
+// KnownNames returns the distinct player names in the transactions and
+// waivers that contain the search term (case-insensitive), sorted
+func KnownNames(search string, transactions []models.Transaction, waivers []*ulbmodels.Waiver) []string {
+	search = strings.ToLower(search)
+	found := make(map[string]bool)
+
+	for _, tx := range transactions {
+		if strings.Contains(strings.ToLower(tx.PlayerName), search) {
+			found[tx.PlayerName] = true
+		}
+	}
+	for _, waiver := range waivers {
+		if strings.Contains(strings.ToLower(waiver.PlayerName), search) {
+			found[waiver.PlayerName] = true
+		}
+	}
+
+	names := make([]string, 0, len(found))
+	for name := range found {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -142,3 +142,15 @@ func (p *Player) HasContract() bool {
 	}
 	return false
 }
+
+// RemainingContractYears counts the salaried years left on a contract,
+// starting with the given year and stopping at free agency
+func (p *Player) RemainingContractYears(year int) int {
+	years := 0
+	for ; ; year++ {
+		if _, ok := p.GetSalary(year); !ok {
+			return years
+		}
+		years++
+	}
+}
@@ -0,0 +1,122 @@
+package models
+
+// PlayerChange is one difference for a rostered player between two loads of the sheet
+type PlayerChange struct {
+	PlayerName string
+	Team       string // ULB team after the change, or before it if the player left
+	Field      string // "Team", "Status", "Contract", "Added" or "Removed"
+	Old        string
+	New        string
+}
+
+// DiffPlayers compares two loads of the player pool and lists the changes
+// for players owned by a ULB team in either load. Unowned players are
+// ignored, since their MLB moves are not league news. Contracts are compared
+// for the given season.
+func DiffPlayers(oldPlayers, newPlayers PlayerList, season int) []PlayerChange {
+	oldByName := groupByNormalizedName(oldPlayers)
+	newByName := groupByNormalizedName(newPlayers)
+
+	var changes []PlayerChange
+	for key, after := range newByName {
+		pairs, added, removed := pairPlayers(oldByName[key], after)
+		for _, pair := range pairs {
+			changes = append(changes, comparePlayers(pair[0], pair[1], season)...)
+		}
+		for _, p := range added {
+			if p.ULBTeam != "" {
+				changes = append(changes, PlayerChange{PlayerName: p.Name, Team: p.ULBTeam, Field: "Added", New: p.ULBTeam})
+			}
+		}
+		for _, p := range removed {
+			if p.ULBTeam != "" {
+				changes = append(changes, PlayerChange{PlayerName: p.Name, Team: p.ULBTeam, Field: "Removed", Old: p.ULBTeam})
+			}
+		}
+	}
+
+	for key, before := range oldByName {
+		if _, exists := newByName[key]; exists {
+			continue
+		}
+		for _, p := range before {
+			if p.ULBTeam != "" {
+				changes = append(changes, PlayerChange{PlayerName: p.Name, Team: p.ULBTeam, Field: "Removed", Old: p.ULBTeam})
+			}
+		}
+	}
+
+	return changes
+}
+
+// groupByNormalizedName groups players who share a name
+func groupByNormalizedName(players PlayerList) map[string][]Player {
+	groups := make(map[string][]Player)
+	for _, p := range players {
+		key := NormalizeName(p.Name)
+		groups[key] = append(groups[key], p)
+	}
+	return groups
+}
+
+// pairPlayers matches up players who share a name across two loads,
+// preferring the same ULB team, then the same MLB team, then sheet order
+func pairPlayers(before, after []Player) (pairs [][2]Player, added, removed []Player) {
+	used := make([]bool, len(before))
+	var unmatched []Player
+
+	match := func(p Player, same func(a, b Player) bool) bool {
+		for i, candidate := range before {
+			if !used[i] && same(candidate, p) {
+				used[i] = true
+				pairs = append(pairs, [2]Player{candidate, p})
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, p := range after {
+		if !match(p, func(a, b Player) bool { return a.ULBTeam == b.ULBTeam }) &&
+			!match(p, func(a, b Player) bool { return a.MLBTeam == b.MLBTeam }) {
+			unmatched = append(unmatched, p)
+		}
+	}
+	for _, p := range unmatched {
+		if !match(p, func(a, b Player) bool { return true }) {
+			added = append(added, p)
+		}
+	}
+	for i, p := range before {
+		if !used[i] {
+			removed = append(removed, p)
+		}
+	}
+
+	return pairs, added, removed
+}
+
+// comparePlayers lists the tracked fields that differ for an owned player
+func comparePlayers(before, after Player, season int) []PlayerChange {
+	if before.ULBTeam == "" && after.ULBTeam == "" {
+		return nil
+	}
+
+	team := after.ULBTeam
+	if team == "" {
+		team = before.ULBTeam
+	}
+
+	var changes []PlayerChange
+	add := func(field, oldValue, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, PlayerChange{PlayerName: after.Name, Team: team, Field: field, Old: oldValue, New: newValue})
+		}
+	}
+
+	add("Team", before.ULBTeam, after.ULBTeam)
+	add("Status", before.Status, after.Status)
+	add("Contract", before.Contract[season], after.Contract[season])
+
+	return changes
+}
@@ -30,3 +30,29 @@ func (tp *TradedPlayer) GetTradedSalary(year int) int {
 	retained := tp.GetRetainedSalary(year)
 	return salary - retained
 }
+
+// TeamPayrollChange calculates a team's payroll for a year before and after a
+// trade. Outgoing players come off at full salary but leave their retained
+// share behind, incoming players arrive at their traded salary, and cash is
+// added when received and subtracted when paid.
+func (pl PlayerList) TeamPayrollChange(team string, outgoing, incoming []TradedPlayer, cashIn, cashOut, year int) (before, after int) {
+	for _, p := range pl.FilterByTeam(team) {
+		if salary, ok := p.GetSalary(year); ok {
+			before += salary
+		}
+	}
+
+	after = before
+	for _, tp := range outgoing {
+		if salary, ok := tp.Player.GetSalary(year); ok {
+			after -= salary
+			after += tp.GetRetainedSalary(year)
+		}
+	}
+	for _, tp := range incoming {
+		after += tp.GetTradedSalary(year)
+	}
+	after += cashIn - cashOut
+
+	return before, after
+}
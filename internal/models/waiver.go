@@ -14,6 +14,8 @@ type Waiver struct {
 	MessageID  string    // Discord message ID to reply to
 	ChannelID  string    // Discord channel ID where command was issued
 	Processed  bool      // Whether this waiver has been processed
+	ThreadID   string    // Discussion thread for the player, if one was opened
+	Cancelled  bool      // Whether the waiver was called off before it expired, e.g. a reversed drop
 }
 
 // IsExpired checks if the waiver period has expired
@@ -0,0 +1,138 @@
+package notify
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// AtomConfig configures an Atom feed file subscriber
+type AtomConfig struct {
+	Path       string `json:"path"` // Relative to the data directory
+	Title      string `json:"title,omitempty"`
+	Link       string `json:"link,omitempty"`        // Optional link to the league site
+	MaxEntries int    `json:"max_entries,omitempty"` // Default 200
+}
+
+// AtomSink keeps an Atom feed file of the most recent events
+type AtomSink struct {
+	name   string
+	config AtomConfig
+
+	mu   sync.Mutex
+	feed atomFeed
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Link    *atomLink   `xml:"link,omitempty"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	Title    string         `xml:"title"`
+	ID       string         `xml:"id"`
+	Updated  string         `xml:"updated"`
+	Summary  string         `xml:"summary"`
+	Category []atomCategory `xml:"category"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+// NewAtomSink creates an Atom feed sink, keeping any entries already in the file
+func NewAtomSink(name string, config AtomConfig) (*AtomSink, error) {
+	if config.Path == "" {
+		return nil, fmt.Errorf("atom feed %s has no path", name)
+	}
+	if config.Title == "" {
+		config.Title = "Ultra League Baseball Transactions"
+	}
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = 200
+	}
+
+	a := &AtomSink{
+		name:   name,
+		config: config,
+	}
+
+	if data, err := os.ReadFile(config.Path); err == nil {
+		if err := xml.Unmarshal(data, &a.feed); err != nil {
+			return nil, fmt.Errorf("failed to parse existing atom feed %s: %w", config.Path, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read atom feed %s: %w", config.Path, err)
+	}
+
+	return a, nil
+}
+
+func (a *AtomSink) Name() string {
+	return a.name
+}
+
+// Notify adds the event to the top of the feed and rewrites the file
+func (a *AtomSink) Notify(event Event) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	entry := atomEntry{
+		Title:   event.Title(),
+		ID:      "urn:ulb-bot:" + event.ID(),
+		Updated: event.Time.UTC().Format(time.RFC3339),
+		Summary: event.Summary(),
+	}
+	for _, term := range append([]string{event.Type()}, event.Teams()...) {
+		if term != "" {
+			entry.Category = append(entry.Category, atomCategory{Term: term})
+		}
+	}
+
+	a.feed.Entries = append([]atomEntry{entry}, a.feed.Entries...)
+	if len(a.feed.Entries) > a.config.MaxEntries {
+		a.feed.Entries = a.feed.Entries[:a.config.MaxEntries]
+	}
+
+	a.feed.Title = a.config.Title
+	a.feed.ID = "urn:ulb-bot:feed"
+	a.feed.Updated = time.Now().UTC().Format(time.RFC3339)
+	a.feed.Link = nil
+	if a.config.Link != "" {
+		a.feed.Link = &atomLink{Href: a.config.Link}
+	}
+
+	return a.write()
+}
+
+// write replaces the feed file, going through a temporary file so readers never see a partial feed
+func (a *AtomSink) write() error {
+	data, err := xml.MarshalIndent(a.feed, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode atom feed: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(a.config.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create feed directory: %w", err)
+	}
+
+	tmpPath := a.config.Path + ".tmp"
+	if err := os.WriteFile(tmpPath, append([]byte(xml.Header), data...), 0644); err != nil {
+		return fmt.Errorf("failed to write atom feed: %w", err)
+	}
+	if err := os.Rename(tmpPath, a.config.Path); err != nil {
+		return fmt.Errorf("failed to replace atom feed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package notify
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pmurley/ulb-bot/pkg/logger"
+)
+
+// Subscriber types
+const (
+	TypeDiscord = "discord"
+	TypeWebhook = "webhook"
+	TypeEmail   = "email"
+	TypeAtom    = "atom"
+)
+
+// SubscriberConfig describes one notification subscriber
+type SubscriberConfig struct {
+	Name    string         `json:"name"`
+	Type    string         `json:"type"`
+	Filter                 // Teams and types to receive
+	Webhook *WebhookConfig `json:"webhook,omitempty"`
+	Email   *EmailConfig   `json:"email,omitempty"`
+	Atom    *AtomConfig    `json:"atom,omitempty"`
+}
+
+// Config lists every notification subscriber
+type Config struct {
+	Subscribers []SubscriberConfig `json:"subscribers"`
+}
+
+// LoadConfig reads a notification config file. Environment variables in the
+// file (e.g. "${SMTP_PASSWORD}") are expanded. An empty path returns an empty config.
+func LoadConfig(path string) (*Config, error) {
+	if path == "" {
+		return &Config{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notification config: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal([]byte(os.ExpandEnv(string(data))), &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse notification config: %w", err)
+	}
+
+	return &cfg, nil
+}
+
+// DiscordFilter returns the filter for the Discord subscriber. Discord receives
+// everything unless the config contains a discord entry.
+func (c *Config) DiscordFilter() Filter {
+	for _, sub := range c.Subscribers {
+		if sub.Type == TypeDiscord {
+			return sub.Filter
+		}
+	}
+	return Filter{}
+}
+
+// Subscribe creates the configured non-Discord sinks and subscribes them to
+// the dispatcher. Atom feed paths are relative to storageDir. In staging,
+// webhooks and email reach real people, so they are left out.
+func (c *Config) Subscribe(d *Dispatcher, log *logger.Logger, storageDir string, staging bool) error {
+	for i, sub := range c.Subscribers {
+		name := sub.Name
+		if name == "" {
+			name = fmt.Sprintf("%s-%d", sub.Type, i+1)
+		}
+
+		if staging && (sub.Type == TypeWebhook || sub.Type == TypeEmail) {
+			log.Warn("Staging mode: not subscribing ", sub.Type, " ", name)
+			continue
+		}
+
+		var sink Sink
+		var err error
+		switch sub.Type {
+		case TypeDiscord:
+			continue // Created by the bot, which owns the session
+		case TypeWebhook:
+			if sub.Webhook == nil {
+				return fmt.Errorf("subscriber %s is missing webhook settings", name)
+			}
+			sink, err = NewWebhookSink(name, *sub.Webhook)
+		case TypeEmail:
+			if sub.Email == nil {
+				return fmt.Errorf("subscriber %s is missing email settings", name)
+			}
+			sink, err = NewEmailSink(name, *sub.Email, log)
+		case TypeAtom:
+			if sub.Atom == nil {
+				return fmt.Errorf("subscriber %s is missing atom settings", name)
+			}
+			atom := *sub.Atom
+			atom.Path, err = storagePath(storageDir, atom.Path)
+			if err != nil {
+				return fmt.Errorf("subscriber %s: %w", name, err)
+			}
+			sink, err = NewAtomSink(name, atom)
+		default:
+			return fmt.Errorf("subscriber %s has unknown type %q", name, sub.Type)
+		}
+		if err != nil {
+			return err
+		}
+
+		d.Subscribe(sink, sub.Filter)
+	}
+	return nil
+}
+
+// storagePath resolves a file path relative to the storage directory, keeping
+// it inside so staging never writes production files
+func storagePath(storageDir, path string) (string, error) {
+	if path == "" {
+		return "", nil
+	}
+	if filepath.IsAbs(path) || !filepath.IsLocal(path) {
+		return "", fmt.Errorf("path %q must be relative to the data directory", path)
+	}
+	return filepath.Join(storageDir, path), nil
+}
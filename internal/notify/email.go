@@ -0,0 +1,168 @@
+package notify
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pmurley/ulb-bot/pkg/logger"
+)
+
+// EmailConfig configures an SMTP digest subscriber
+type EmailConfig struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+	Subject  string   `json:"subject,omitempty"`
+	Interval string   `json:"interval,omitempty"` // How often to send the digest, e.g. "24h" (default)
+}
+
+// EmailSink collects events and mails them as a periodic digest
+type EmailSink struct {
+	name     string
+	config   EmailConfig
+	interval time.Duration
+	logger   *logger.Logger
+
+	mu      sync.Mutex
+	pending []Event
+
+	stopChan chan struct{}
+	done     chan struct{}
+}
+
+// NewEmailSink creates an email digest sink and starts its send loop
+func NewEmailSink(name string, config EmailConfig, log *logger.Logger) (*EmailSink, error) {
+	if config.Host == "" || config.From == "" || len(config.To) == 0 {
+		return nil, fmt.Errorf("email %s needs host, from and to", name)
+	}
+	if config.Port == 0 {
+		config.Port = 587
+	}
+	if config.Subject == "" {
+		config.Subject = "ULB transaction digest"
+	}
+
+	interval := 24 * time.Hour
+	if config.Interval != "" {
+		d, err := time.ParseDuration(config.Interval)
+		if err != nil {
+			return nil, fmt.Errorf("email %s has invalid interval: %w", name, err)
+		}
+		if d <= 0 {
+			return nil, fmt.Errorf("email %s has invalid interval: %s is not positive", name, config.Interval)
+		}
+		interval = d
+	}
+
+	e := &EmailSink{
+		name:     name,
+		config:   config,
+		interval: interval,
+		logger:   log,
+		stopChan: make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go e.sendLoop()
+
+	return e, nil
+}
+
+func (e *EmailSink) Name() string {
+	return e.name
+}
+
+// Notify queues the event for the next digest
+func (e *EmailSink) Notify(event Event) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.pending = append(e.pending, event)
+	return nil
+}
+
+// Close sends any queued events and stops the send loop
+func (e *EmailSink) Close() error {
+	close(e.stopChan)
+	<-e.done
+	return e.flush()
+}
+
+// sendLoop mails the digest every interval
+func (e *EmailSink) sendLoop() {
+	defer close(e.done)
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := e.flush(); err != nil {
+				e.logger.Error("Failed to send email digest ", e.name, ": ", err)
+			}
+		case <-e.stopChan:
+			return
+		}
+	}
+}
+
+// flush sends all pending events in one message. Events are kept for the
+// next attempt if sending fails.
+func (e *EmailSink) flush() error {
+	e.mu.Lock()
+	events := e.pending
+	e.pending = nil
+	e.mu.Unlock()
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	if err := e.send(events); err != nil {
+		e.mu.Lock()
+		e.pending = append(events, e.pending...)
+		e.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// send delivers a digest containing the given events
+func (e *EmailSink) send(events []Event) error {
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("%d league update(s):\r\n\r\n", len(events)))
+	for _, event := range events {
+		body.WriteString(fmt.Sprintf("[%s] %s\r\n", event.Time.Format("Jan 2 15:04"), event.Title()))
+		if summary := event.Summary(); summary != "" {
+			for _, line := range strings.Split(summary, "\n") {
+				body.WriteString("    " + line + "\r\n")
+			}
+		}
+		body.WriteString("\r\n")
+	}
+
+	var msg strings.Builder
+	msg.WriteString("From: " + e.config.From + "\r\n")
+	msg.WriteString("To: " + strings.Join(e.config.To, ", ") + "\r\n")
+	msg.WriteString("Subject: " + e.config.Subject + "\r\n")
+	msg.WriteString("Date: " + time.Now().Format(time.RFC1123Z) + "\r\n")
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(body.String())
+
+	var auth smtp.Auth
+	if e.config.Username != "" {
+		auth = smtp.PlainAuth("", e.config.Username, e.config.Password, e.config.Host)
+	}
+
+	addr := fmt.Sprintf("%s:%d", e.config.Host, e.config.Port)
+	if err := smtp.SendMail(addr, auth, e.config.From, e.config.To, []byte(msg.String())); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,308 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pmurley/go-fantrax/models"
+	ulbmodels "github.com/pmurley/ulb-bot/internal/models"
+	"github.com/pmurley/ulb-bot/pkg/logger"
+)
+
+// EventKind identifies what happened
+type EventKind string
+
+const (
+	EventTransaction   EventKind = "transaction"    // A single CLAIM or DROP
+	EventTrade         EventKind = "trade"          // Every transaction in one trade group
+	EventWaiverExpired EventKind = "waiver_expired" // A DFA waiver period has ended
+)
+
+// WaiverType is the type filters use to select waiver events
+const WaiverType = "WAIVER"
+
+// Event is a league notification produced by one of the monitors
+type Event struct {
+	Kind         EventKind
+	Time         time.Time
+	Transactions []models.Transaction // Set for transaction and trade events
+	Waiver       *ulbmodels.Waiver    // Set for waiver events
+}
+
+// Sink delivers events somewhere (Discord, a webhook, email, a feed, ...)
+type Sink interface {
+	Name() string
+	Notify(event Event) error
+}
+
+// Filter restricts which events a subscriber receives. Empty lists match everything.
+type Filter struct {
+	Teams []string `json:"teams,omitempty"` // Team names involved in the event
+	Types []string `json:"types,omitempty"` // CLAIM, DROP, TRADE or WAIVER
+}
+
+// Matches reports whether the event passes the filter
+func (f Filter) Matches(event Event) bool {
+	return matchesAny(f.Types, event.Type()) && matchesAny(f.Teams, event.Teams()...)
+}
+
+// Dispatcher fans events out to every subscribed sink
+type Dispatcher struct {
+	mu          sync.RWMutex
+	logger      *logger.Logger
+	subscribers []subscriber
+}
+
+type subscriber struct {
+	sink   Sink
+	filter Filter
+}
+
+// NewDispatcher creates a dispatcher with no subscribers
+func NewDispatcher(log *logger.Logger) *Dispatcher {
+	return &Dispatcher{logger: log}
+}
+
+// Subscribe registers a sink for the events matching the filter
+func (d *Dispatcher) Subscribe(sink Sink, filter Filter) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.subscribers = append(d.subscribers, subscriber{sink: sink, filter: filter})
+}
+
+// DeliveryError reports the sinks that failed to deliver an event
+type DeliveryError struct {
+	Failures map[string]error // By sink name
+}
+
+func (e *DeliveryError) Error() string {
+	var parts []string
+	for _, name := range e.Sinks() {
+		parts = append(parts, fmt.Sprintf("%s: %v", name, e.Failures[name]))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Sinks returns the names of the sinks that failed, sorted
+func (e *DeliveryError) Sinks() []string {
+	names := make([]string, 0, len(e.Failures))
+	for name := range e.Failures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Failed reports whether the named sink failed
+func (e *DeliveryError) Failed(name string) bool {
+	_, failed := e.Failures[name]
+	return failed
+}
+
+// Dispatch delivers the event to every matching sink. All sinks are tried even
+// if some fail; the failures are returned as a *DeliveryError.
+func (d *Dispatcher) Dispatch(event Event) error {
+	return d.dispatch(event, nil)
+}
+
+// DispatchTo delivers the event only to the named sinks that match it, to
+// retry the sinks a *DeliveryError reported
+func (d *Dispatcher) DispatchTo(event Event, sinks []string) error {
+	only := make(map[string]bool, len(sinks))
+	for _, name := range sinks {
+		only[name] = true
+	}
+	return d.dispatch(event, only)
+}
+
+// dispatch delivers the event to the matching sinks, limited to only when it
+// is not nil
+func (d *Dispatcher) dispatch(event Event, only map[string]bool) error {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	d.mu.RLock()
+	subscribers := d.subscribers
+	d.mu.RUnlock()
+
+	failures := make(map[string]error)
+	for _, sub := range subscribers {
+		if only != nil && !only[sub.sink.Name()] {
+			continue
+		}
+		if !sub.filter.Matches(event) {
+			continue
+		}
+		if err := sub.sink.Notify(event); err != nil {
+			d.logger.Error("Notification sink ", sub.sink.Name(), " failed: ", err)
+			failures[sub.sink.Name()] = err
+		}
+	}
+
+	if len(failures) > 0 {
+		return &DeliveryError{Failures: failures}
+	}
+	return nil
+}
+
+// Close flushes and closes every sink that holds resources
+func (d *Dispatcher) Close() error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var errs []error
+	for _, sub := range d.subscribers {
+		if closer, ok := sub.sink.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", sub.sink.Name(), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Type returns the transaction type of the event, or WaiverType for waivers
+func (e Event) Type() string {
+	if e.Kind == EventWaiverExpired {
+		return WaiverType
+	}
+	if len(e.Transactions) > 0 {
+		return e.Transactions[0].Type
+	}
+	return ""
+}
+
+// Teams returns every team involved in the event
+func (e Event) Teams() []string {
+	var teams []string
+	seen := make(map[string]bool)
+	add := func(team string) {
+		if team != "" && !seen[team] {
+			seen[team] = true
+			teams = append(teams, team)
+		}
+	}
+
+	for _, tx := range e.Transactions {
+		add(tx.TeamName)
+		add(tx.FromTeamName)
+		add(tx.ToTeamName)
+	}
+	if e.Waiver != nil {
+		add(e.Waiver.TeamName)
+	}
+	return teams
+}
+
+// ID returns a stable identifier for the event
+func (e Event) ID() string {
+	switch {
+	case e.Kind == EventWaiverExpired && e.Waiver != nil:
+		return fmt.Sprintf("waiver-%s-%s", e.Waiver.MessageID, e.Waiver.UserID)
+	case e.Kind == EventTrade && len(e.Transactions) > 0:
+		return "trade-" + e.Transactions[0].TradeGroupID
+	case len(e.Transactions) > 0:
+		return "transaction-" + e.Transactions[0].ID
+	default:
+		return fmt.Sprintf("%s-%d", e.Kind, e.Time.Unix())
+	}
+}
+
+// Title returns a one-line headline for text-based sinks
+func (e Event) Title() string {
+	switch e.Kind {
+	case EventWaiverExpired:
+		if e.Waiver != nil {
+			return fmt.Sprintf("Waiver period expired for %s", e.Waiver.PlayerName)
+		}
+		return "Waiver period expired"
+	case EventTrade:
+		return "Trade executed: " + strings.Join(e.Teams(), " / ")
+	}
+
+	if len(e.Transactions) == 0 {
+		return string(e.Kind)
+	}
+
+	tx := e.Transactions[0]
+	switch tx.Type {
+	case "CLAIM":
+		if strings.EqualFold(tx.ExecutedBy, "commissioner") {
+			return fmt.Sprintf("%s promoted %s to the 40-man roster", tx.TeamName, tx.PlayerName)
+		}
+		if tx.ClaimType == "FA" {
+			return fmt.Sprintf("%s signed %s", tx.TeamName, tx.PlayerName)
+		}
+		return fmt.Sprintf("%s claimed %s", tx.TeamName, tx.PlayerName)
+	case "DROP":
+		return fmt.Sprintf("%s designated %s for assignment", tx.TeamName, tx.PlayerName)
+	default:
+		return fmt.Sprintf("%s %s: %s", tx.TeamName, tx.Type, tx.PlayerName)
+	}
+}
+
+// Summary returns a plain-text description of the event for text-based sinks
+func (e Event) Summary() string {
+	var summary strings.Builder
+
+	switch e.Kind {
+	case EventWaiverExpired:
+		if e.Waiver != nil {
+			summary.WriteString(fmt.Sprintf("The waiver period for %s (%s) ended %s.",
+				e.Waiver.PlayerName, e.Waiver.TeamName, e.Waiver.EndTime.Format("Jan 2, 2006 3:04 PM MST")))
+		}
+	case EventTrade:
+		// List what each team sent, in a stable order
+		sent := make(map[string][]string)
+		var teams []string
+		for _, tx := range e.Transactions {
+			if _, exists := sent[tx.FromTeamName]; !exists {
+				teams = append(teams, tx.FromTeamName)
+			}
+			sent[tx.FromTeamName] = append(sent[tx.FromTeamName],
+				fmt.Sprintf("%s (%s - %s) to %s", tx.PlayerName, tx.PlayerPosition, tx.PlayerTeam, tx.ToTeamName))
+		}
+		for i, team := range teams {
+			if i > 0 {
+				summary.WriteString("\n")
+			}
+			summary.WriteString(fmt.Sprintf("%s traded %s", team, strings.Join(sent[team], ", ")))
+		}
+	default:
+		for i, tx := range e.Transactions {
+			if i > 0 {
+				summary.WriteString("\n")
+			}
+			summary.WriteString(fmt.Sprintf("%s: %s (%s - %s)", tx.Type, tx.PlayerName, tx.PlayerPosition, tx.PlayerTeam))
+			if tx.BidAmount != "" {
+				summary.WriteString(fmt.Sprintf(", bid $%s", tx.BidAmount))
+			}
+			summary.WriteString(fmt.Sprintf(", period %d", tx.Period))
+		}
+	}
+
+	return summary.String()
+}
+
+// matchesAny reports whether any value equals one of the allowed entries
+// (case-insensitive). An empty allow list matches everything.
+func matchesAny(allowed []string, values ...string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	for _, a := range allowed {
+		for _, v := range values {
+			if strings.EqualFold(a, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/pmurley/go-fantrax/models"
+)
+
+// WebhookConfig configures an outgoing JSON webhook
+type WebhookConfig struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"` // Extra headers, e.g. an auth token
+}
+
+// WebhookSink POSTs every event as a JSON document
+type WebhookSink struct {
+	name       string
+	config     WebhookConfig
+	httpClient *http.Client
+}
+
+// WebhookPayload is the JSON body sent to webhook subscribers
+type WebhookPayload struct {
+	ID           string               `json:"id"`
+	Event        EventKind            `json:"event"`
+	Type         string               `json:"type"`
+	Time         time.Time            `json:"time"`
+	Teams        []string             `json:"teams"`
+	Title        string               `json:"title"`
+	Summary      string               `json:"summary"`
+	Transactions []models.Transaction `json:"transactions,omitempty"`
+	Waiver       *WebhookWaiver       `json:"waiver,omitempty"`
+}
+
+// WebhookWaiver is the waiver portion of a webhook payload
+type WebhookWaiver struct {
+	PlayerName string    `json:"playerName"`
+	TeamName   string    `json:"teamName"`
+	StartTime  time.Time `json:"startTime"`
+	EndTime    time.Time `json:"endTime"`
+}
+
+// NewWebhookSink creates a webhook sink
+func NewWebhookSink(name string, config WebhookConfig) (*WebhookSink, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("webhook %s has no url", name)
+	}
+
+	return &WebhookSink{
+		name:   name,
+		config: config,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}, nil
+}
+
+func (w *WebhookSink) Name() string {
+	return w.name
+}
+
+// Notify posts the event to the webhook URL
+func (w *WebhookSink) Notify(event Event) error {
+	payload := WebhookPayload{
+		ID:           event.ID(),
+		Event:        event.Kind,
+		Type:         event.Type(),
+		Time:         event.Time,
+		Teams:        event.Teams(),
+		Title:        event.Title(),
+		Summary:      event.Summary(),
+		Transactions: event.Transactions,
+	}
+	if event.Waiver != nil {
+		payload.Waiver = &WebhookWaiver{
+			PlayerName: event.Waiver.PlayerName,
+			TeamName:   event.Waiver.TeamName,
+			StartTime:  event.Waiver.StartTime,
+			EndTime:    event.Waiver.EndTime,
+		}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", w.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ulb-bot")
+	for key, value := range w.config.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := w.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("performing request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package poll
+
+import (
+	"sync"
+	"time"
+)
+
+// Config controls how a Poller schedules attempts after failures
+type Config struct {
+	Interval         time.Duration // Delay between successful polls
+	MaxBackoff       time.Duration // Upper bound for the exponential backoff
+	BreakerThreshold int           // Consecutive failures that open the circuit
+	BreakerCooldown  time.Duration // How long the circuit stays open before a trial poll
+}
+
+// Health is a snapshot of a poller's state
+type Health struct {
+	Name                string
+	LastAttempt         time.Time
+	LastSuccess         time.Time
+	LastError           string
+	ConsecutiveFailures int
+	CircuitOpen         bool
+	NextAttempt         time.Time
+}
+
+// Healthy reports whether the last poll succeeded
+func (h Health) Healthy() bool {
+	return h.ConsecutiveFailures == 0 && !h.LastSuccess.IsZero()
+}
+
+// Poller tracks the outcome of repeated polls of an external service. After
+// a failure it backs off exponentially, and after BreakerThreshold failures in
+// a row it opens a circuit breaker and waits BreakerCooldown before trying a
+// single trial poll. A success resets everything.
+type Poller struct {
+	mu     sync.RWMutex
+	config Config
+	health Health
+}
+
+// New creates a poller
+func New(name string, config Config) *Poller {
+	return &Poller{
+		config: config,
+		health: Health{Name: name},
+	}
+}
+
+// Record stores the outcome of a poll and returns how long to wait before the
+// next one. The second result reports whether the circuit state changed.
+func (p *Poller) Record(err error) (time.Duration, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	p.health.LastAttempt = now
+	wasOpen := p.health.CircuitOpen
+
+	if err == nil {
+		p.health.LastSuccess = now
+		p.health.LastError = ""
+		p.health.ConsecutiveFailures = 0
+		p.health.CircuitOpen = false
+		p.health.NextAttempt = now.Add(p.config.Interval)
+		return p.config.Interval, wasOpen
+	}
+
+	p.health.LastError = err.Error()
+	p.health.ConsecutiveFailures++
+
+	delay := p.backoff(p.health.ConsecutiveFailures)
+	if p.config.BreakerThreshold > 0 && p.health.ConsecutiveFailures >= p.config.BreakerThreshold {
+		p.health.CircuitOpen = true
+		delay = p.config.BreakerCooldown
+	}
+	p.health.NextAttempt = now.Add(delay)
+
+	return delay, wasOpen != p.health.CircuitOpen
+}
+
+// backoff returns the delay after the given number of consecutive failures
+func (p *Poller) backoff(failures int) time.Duration {
+	delay := p.config.Interval
+	for i := 1; i < failures; i++ {
+		delay *= 2
+		if p.config.MaxBackoff > 0 && delay >= p.config.MaxBackoff {
+			return p.config.MaxBackoff
+		}
+	}
+	return delay
+}
+
+// Health returns a snapshot of the poller's state
+func (p *Poller) Health() Health {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.health
+}
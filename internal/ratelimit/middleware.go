@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pmurley/ulb-bot/internal/command"
+)
+
+// Middleware refuses commands over a limit with a cooldown reply. Exempt
+// users are never limited. A user who keeps trying while limited is only
+// told once per cooldown, so the replies can't be used to flood a channel.
+func Middleware(l *Limiter, exempt func(req *command.Request) bool) command.Middleware {
+	var mu sync.Mutex
+	notified := make(map[string]time.Time)
+
+	return func(next command.Handler) command.Handler {
+		return func(req *command.Request, res command.Responder) {
+			if exempt(req) {
+				next(req, res)
+				return
+			}
+
+			decision := l.Allow(req.User.ID, req.Name)
+			if decision.Allowed {
+				next(req, res)
+				return
+			}
+			command.Record(res, fmt.Errorf("%w (%s limit)", command.ErrRateLimited, decision.Scope))
+
+			now := time.Now()
+			mu.Lock()
+			quiet := now.Before(notified[req.User.ID])
+			if !quiet {
+				notified[req.User.ID] = now.Add(decision.Wait)
+			}
+			for id, until := range notified {
+				if now.After(until) {
+					delete(notified, id)
+				}
+			}
+			mu.Unlock()
+
+			if !quiet {
+				res.Reply(cooldownMessage(req.Name, decision))
+			}
+		}
+	}
+}
+
+// cooldownMessage tells the user when they can try again
+func cooldownMessage(name string, decision Decision) string {
+	seconds := int(math.Ceil(decision.Wait.Seconds()))
+	switch decision.Scope {
+	case ScopeGlobal:
+		return fmt.Sprintf("⏳ The bot is busy right now. Please try again in %ds.", seconds)
+	case ScopeCommand:
+		return fmt.Sprintf("⏳ !%s is cooling down. Please try again in %ds.", name, seconds)
+	default:
+		return fmt.Sprintf("⏳ You're sending commands too quickly. Please try again in %ds.", seconds)
+	}
+}
@@ -0,0 +1,243 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Scopes a limit applies to
+const (
+	ScopeGlobal  = "global"
+	ScopeUser    = "user"
+	ScopeCommand = "command"
+)
+
+// Rule is a token bucket: up to Burst commands at once, refilled at PerMinute.
+// A rule with a zero burst or rate does not limit anything.
+type Rule struct {
+	Burst     int     `json:"burst"`
+	PerMinute float64 `json:"per_minute"`
+}
+
+func (r Rule) enabled() bool {
+	return r.Burst > 0 && r.PerMinute > 0
+}
+
+// Config holds the limits. Global is shared by every command, PerUser by
+// all commands of one user, and each entry of Commands by everyone running
+// that command.
+type Config struct {
+	Global   Rule            `json:"global"`
+	PerUser  Rule            `json:"per_user"`
+	Commands map[string]Rule `json:"commands"`
+}
+
+// DefaultConfig protects Spotrac, Google Sheets and Fantrax from bursts of
+// the commands that call them
+func DefaultConfig() *Config {
+	return &Config{
+		Global:  Rule{Burst: 30, PerMinute: 60},
+		PerUser: Rule{Burst: 8, PerMinute: 12},
+		Commands: map[string]Rule{
+			"spotrac":   {Burst: 3, PerMinute: 3},
+			"reload":    {Burst: 1, PerMinute: 0.5},
+			"reconcile": {Burst: 1, PerMinute: 1},
+		},
+	}
+}
+
+// LoadConfig reads a rate limit config file over the defaults. An empty path
+// gives the defaults; a rule with a zero burst turns that limit off.
+func LoadConfig(path string) (*Config, error) {
+	cfg := DefaultConfig()
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rate limit config: %w", err)
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse rate limit config: %w", err)
+	}
+	return cfg, nil
+}
+
+// bucket is the state of one token bucket
+type bucket struct {
+	rule   Rule
+	tokens float64
+	last   time.Time
+}
+
+func newBucket(rule Rule, now time.Time) *bucket {
+	return &bucket{rule: rule, tokens: float64(rule.Burst), last: now}
+}
+
+// refill adds the tokens earned since the last refill
+func (b *bucket) refill(now time.Time) {
+	// A clock stepping backwards earns nothing
+	elapsed := math.Max(now.Sub(b.last).Minutes(), 0)
+	b.tokens = math.Min(float64(b.rule.Burst), b.tokens+elapsed*b.rule.PerMinute)
+	b.last = now
+}
+
+// wait returns how long until the bucket has a token
+func (b *bucket) wait() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rule.PerMinute * float64(time.Minute))
+}
+
+func (b *bucket) full() bool {
+	return b.tokens >= float64(b.rule.Burst)
+}
+
+// Decision is the outcome of asking the limiter for a command
+type Decision struct {
+	Allowed bool
+	Scope   string        // Which limit refused the command
+	Wait    time.Duration // Until the command would be allowed
+}
+
+// Limiter applies the configured limits
+type Limiter struct {
+	cfg *Config
+	now func() time.Time
+
+	mu       sync.Mutex
+	global   *bucket
+	users    map[string]*bucket
+	commands map[string]*bucket
+}
+
+// New creates a limiter with full buckets
+func New(cfg *Config) *Limiter {
+	l := &Limiter{
+		cfg:      cfg,
+		now:      time.Now,
+		users:    make(map[string]*bucket),
+		commands: make(map[string]*bucket),
+	}
+	if cfg.Global.enabled() {
+		l.global = newBucket(cfg.Global, l.now())
+	}
+	return l
+}
+
+// Allow takes a token from every bucket the command is subject to. When any
+// of them is empty nothing is taken, and the decision says which limit
+// refused it and for how long.
+func (l *Limiter) Allow(userID, command string) Decision {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.removeIdle(now)
+
+	type scoped struct {
+		scope string
+		b     *bucket
+	}
+	var buckets []scoped
+	if l.global != nil {
+		buckets = append(buckets, scoped{ScopeGlobal, l.global})
+	}
+	if l.cfg.PerUser.enabled() {
+		b, ok := l.users[userID]
+		if !ok {
+			b = newBucket(l.cfg.PerUser, now)
+			l.users[userID] = b
+		}
+		buckets = append(buckets, scoped{ScopeUser, b})
+	}
+	if rule, ok := l.cfg.Commands[command]; ok && rule.enabled() {
+		b, ok := l.commands[command]
+		if !ok {
+			b = newBucket(rule, now)
+			l.commands[command] = b
+		}
+		buckets = append(buckets, scoped{ScopeCommand, b})
+	}
+
+	for _, s := range buckets {
+		s.b.refill(now)
+		if wait := s.b.wait(); wait > 0 {
+			return Decision{Scope: s.scope, Wait: wait}
+		}
+	}
+	for _, s := range buckets {
+		s.b.tokens--
+	}
+	return Decision{Allowed: true}
+}
+
+// removeIdle forgets user buckets that have refilled, since a new bucket
+// starts full anyway
+func (l *Limiter) removeIdle(now time.Time) {
+	for id, b := range l.users {
+		b.refill(now)
+		if b.full() {
+			delete(l.users, id)
+		}
+	}
+}
+
+// BucketState is a snapshot of one bucket
+type BucketState struct {
+	Scope  string
+	Key    string // User ID or command name; empty for the global bucket
+	Tokens float64
+	Burst  int
+	Wait   time.Duration
+}
+
+// State returns the buckets that are not full: the global bucket first, then
+// the command and user buckets by key
+func (l *Limiter) State() []BucketState {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.removeIdle(now)
+
+	snapshot := func(scope, key string, b *bucket) BucketState {
+		b.refill(now)
+		return BucketState{Scope: scope, Key: key, Tokens: b.tokens, Burst: b.rule.Burst, Wait: b.wait()}
+	}
+
+	var states []BucketState
+	if l.global != nil {
+		if s := snapshot(ScopeGlobal, "", l.global); s.Tokens < float64(s.Burst) {
+			states = append(states, s)
+		}
+	}
+	var rest []BucketState
+	for name, b := range l.commands {
+		if s := snapshot(ScopeCommand, name, b); s.Tokens < float64(s.Burst) {
+			rest = append(rest, s)
+		}
+	}
+	for id, b := range l.users {
+		rest = append(rest, snapshot(ScopeUser, id, b))
+	}
+	sort.Slice(rest, func(i, j int) bool {
+		if rest[i].Scope != rest[j].Scope {
+			return rest[i].Scope < rest[j].Scope
+		}
+		return rest[i].Key < rest[j].Key
+	})
+	return append(states, rest...)
+}
+
+// Config returns the limits the limiter applies
+func (l *Limiter) Config() *Config {
+	return l.cfg
+}
@@ -0,0 +1,222 @@
+package reconcile
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pmurley/ulb-bot/internal/fantrax"
+	"github.com/pmurley/ulb-bot/internal/models"
+)
+
+// Kind classifies a roster mismatch
+type Kind string
+
+const (
+	KindMissingFromSheet Kind = "MISSING_FROM_SHEET" // On a Fantrax roster, not in the player pool
+	KindUnownedInSheet   Kind = "UNOWNED_IN_SHEET"   // On a Fantrax roster, sheet shows no team
+	KindTeamMismatch     Kind = "TEAM_MISMATCH"      // Fantrax and the sheet disagree on the team
+	KindStatusMismatch   Kind = "STATUS_MISMATCH"    // Same team, but 40-man vs minors differs
+	KindNotOnFantrax     Kind = "NOT_ON_FANTRAX"     // Sheet has a team, no Fantrax roster has the player
+)
+
+// Roster statuses compared between Fantrax and the sheet
+const (
+	Status40Man  = "40-man"
+	StatusMinors = "minors"
+)
+
+// Mismatch is a single disagreement between Fantrax and the sheet
+type Mismatch struct {
+	Kind          Kind
+	PlayerName    string
+	FantraxTeam   string
+	SheetTeam     string
+	FantraxStatus string
+	SheetStatus   string
+}
+
+// Report is the result of one reconciliation run
+type Report struct {
+	Time           time.Time
+	TeamsChecked   int
+	PlayersChecked int
+	Mismatches     []Mismatch
+}
+
+// ErrRunning is returned when a reconciliation is already in progress
+var ErrRunning = errors.New("reconciliation already in progress")
+
+// Reconciler runs reconciliations against one Fantrax league, one at a time.
+// The scheduled job and !reconcile share it so they never overlap.
+type Reconciler struct {
+	leagueID string
+	running  sync.Mutex
+	client   *fantrax.Client
+}
+
+// NewReconciler creates a reconciler for a Fantrax league
+func NewReconciler(leagueID string) *Reconciler {
+	return &Reconciler{leagueID: leagueID}
+}
+
+// Run fetches every Fantrax roster and compares it with the sheet
+func (r *Reconciler) Run(players models.PlayerList) (*Report, error) {
+	if !r.running.TryLock() {
+		return nil, ErrRunning
+	}
+	defer r.running.Unlock()
+
+	if r.client == nil {
+		client, err := fantrax.NewFantraxClient(r.leagueID, false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Fantrax client: %w", err)
+		}
+		r.client = client
+	}
+
+	rosters, err := r.client.GetTeamRosters()
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Fantrax rosters: %w", err)
+	}
+	return Compare(rosters, players), nil
+}
+
+// fantraxEntry is a rostered Fantrax player
+type fantraxEntry struct {
+	name   string
+	team   string
+	status string
+}
+
+// Compare reports every player whose Fantrax roster spot disagrees with the
+// ULBTeam/Status in the sheet
+func Compare(rosters []fantrax.TeamRoster, players models.PlayerList) *Report {
+	report := &Report{
+		Time:         time.Now(),
+		TeamsChecked: len(rosters),
+	}
+
+	// Sheet entries by normalized name; a name can belong to several players
+	sheet := make(map[string][]models.Player)
+	for _, p := range players {
+		key := models.NormalizeName(p.Name)
+		sheet[key] = append(sheet[key], p)
+	}
+
+	onFantrax := make(map[string]bool)
+	for _, roster := range rosters {
+		for _, rp := range roster.Active {
+			report.check(fantraxEntry{rp.Name, roster.TeamName, Status40Man}, sheet, onFantrax)
+		}
+		for _, rp := range roster.Minors {
+			report.check(fantraxEntry{rp.Name, roster.TeamName, StatusMinors}, sheet, onFantrax)
+		}
+	}
+
+	// Owned sheet players that no Fantrax roster has
+	for _, p := range players {
+		if p.ULBTeam == "" || onFantrax[models.NormalizeName(p.Name)+"|"+models.NormalizeName(p.ULBTeam)] {
+			continue
+		}
+		report.Mismatches = append(report.Mismatches, Mismatch{
+			Kind:        KindNotOnFantrax,
+			PlayerName:  p.Name,
+			SheetTeam:   p.ULBTeam,
+			SheetStatus: sheetStatus(p),
+		})
+	}
+
+	sort.SliceStable(report.Mismatches, func(i, j int) bool {
+		a, b := report.Mismatches[i], report.Mismatches[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.PlayerName < b.PlayerName
+	})
+
+	return report
+}
+
+// check compares one Fantrax roster entry with the sheet
+func (r *Report) check(entry fantraxEntry, sheet map[string][]models.Player, onFantrax map[string]bool) {
+	r.PlayersChecked++
+	key := models.NormalizeName(entry.name)
+	team := models.NormalizeName(entry.team)
+	onFantrax[key+"|"+team] = true
+
+	mismatch := Mismatch{
+		PlayerName:    entry.name,
+		FantraxTeam:   entry.team,
+		FantraxStatus: entry.status,
+	}
+
+	candidates := sheet[key]
+	if len(candidates) == 0 {
+		mismatch.Kind = KindMissingFromSheet
+		r.Mismatches = append(r.Mismatches, mismatch)
+		return
+	}
+
+	// Prefer the sheet entry on the same team, for players who share a name
+	var match *models.Player
+	for i := range candidates {
+		if models.NormalizeName(candidates[i].ULBTeam) == team {
+			match = &candidates[i]
+			break
+		}
+	}
+
+	if match == nil {
+		owned := false
+		for _, p := range candidates {
+			if p.ULBTeam != "" {
+				mismatch.SheetTeam = p.ULBTeam
+				mismatch.SheetStatus = sheetStatus(p)
+				owned = true
+				break
+			}
+		}
+		if owned {
+			mismatch.Kind = KindTeamMismatch
+		} else {
+			mismatch.Kind = KindUnownedInSheet
+		}
+		r.Mismatches = append(r.Mismatches, mismatch)
+		return
+	}
+
+	status := sheetStatus(*match)
+	if status != "" && status != entry.status {
+		mismatch.Kind = KindStatusMismatch
+		mismatch.SheetTeam = match.ULBTeam
+		mismatch.SheetStatus = status
+		r.Mismatches = append(r.Mismatches, mismatch)
+	}
+}
+
+// sheetStatus maps the sheet's Status column to Status40Man or StatusMinors,
+// matching how !team splits rosters. Blank statuses are not compared.
+func sheetStatus(p models.Player) string {
+	status := strings.ToLower(strings.TrimSpace(p.Status))
+	switch {
+	case status == "":
+		return ""
+	case strings.Contains(status, "40"):
+		return Status40Man
+	default:
+		return StatusMinors
+	}
+}
+
+// ByKind groups the mismatches by kind
+func (r *Report) ByKind() map[Kind][]Mismatch {
+	groups := make(map[Kind][]Mismatch)
+	for _, m := range r.Mismatches {
+		groups[m.Kind] = append(groups[m.Kind], m)
+	}
+	return groups
+}
@@ -0,0 +1,158 @@
+package routing
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// ChannelCache keeps an index of every guild text channel the bot can see.
+// It is filled from the guild/channel gateway events so that resolving a
+// channel by name never has to hit the Discord API on the hot path.
+type ChannelCache struct {
+	mu     sync.RWMutex
+	guilds map[string]map[string]*discordgo.Channel // guildID -> channelID -> channel
+}
+
+// NewChannelCache creates an empty channel cache
+func NewChannelCache() *ChannelCache {
+	return &ChannelCache{
+		guilds: make(map[string]map[string]*discordgo.Channel),
+	}
+}
+
+// RegisterHandlers subscribes the cache to the gateway events that keep it fresh
+func (c *ChannelCache) RegisterHandlers(s *discordgo.Session) {
+	s.AddHandler(c.onGuildCreate)
+	s.AddHandler(c.onGuildDelete)
+	s.AddHandler(c.onChannelCreate)
+	s.AddHandler(c.onChannelUpdate)
+	s.AddHandler(c.onChannelDelete)
+}
+
+func (c *ChannelCache) onGuildCreate(s *discordgo.Session, g *discordgo.GuildCreate) {
+	c.setGuildChannels(g.ID, g.Channels)
+}
+
+func (c *ChannelCache) onGuildDelete(s *discordgo.Session, g *discordgo.GuildDelete) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.guilds, g.ID)
+}
+
+func (c *ChannelCache) onChannelCreate(s *discordgo.Session, ch *discordgo.ChannelCreate) {
+	c.putChannel(ch.Channel)
+}
+
+func (c *ChannelCache) onChannelUpdate(s *discordgo.Session, ch *discordgo.ChannelUpdate) {
+	c.putChannel(ch.Channel)
+}
+
+func (c *ChannelCache) onChannelDelete(s *discordgo.Session, ch *discordgo.ChannelDelete) {
+	if ch.Channel == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if channels, exists := c.guilds[ch.GuildID]; exists {
+		delete(channels, ch.ID)
+	}
+}
+
+// setGuildChannels replaces the cached channel list for a guild
+func (c *ChannelCache) setGuildChannels(guildID string, channels []*discordgo.Channel) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	index := make(map[string]*discordgo.Channel, len(channels))
+	for _, channel := range channels {
+		index[channel.ID] = channel
+	}
+	c.guilds[guildID] = index
+}
+
+// putChannel adds or replaces a single channel
+func (c *ChannelCache) putChannel(channel *discordgo.Channel) {
+	if channel == nil || channel.GuildID == "" {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	channels, exists := c.guilds[channel.GuildID]
+	if !exists {
+		channels = make(map[string]*discordgo.Channel)
+		c.guilds[channel.GuildID] = channels
+	}
+	channels[channel.ID] = channel
+}
+
+// Guilds returns the IDs of all guilds the cache knows about, sorted for stable iteration
+func (c *ChannelCache) Guilds(s *discordgo.Session) []string {
+	ids := make(map[string]bool)
+
+	c.mu.RLock()
+	for guildID := range c.guilds {
+		ids[guildID] = true
+	}
+	c.mu.RUnlock()
+
+	// Guilds that have not sent a GUILD_CREATE yet are still listed in state
+	if s != nil && s.State != nil {
+		for _, guild := range s.State.Guilds {
+			ids[guild.ID] = true
+		}
+	}
+
+	guilds := make([]string, 0, len(ids))
+	for id := range ids {
+		guilds = append(guilds, id)
+	}
+	sort.Strings(guilds)
+	return guilds
+}
+
+// Resolve turns a channel reference (a channel ID, "name" or "#name") into a
+// text channel ID in the given guild. Guilds that have not been seen yet are
+// loaded once from the API. Returns "" when no such channel exists.
+func (c *ChannelCache) Resolve(s *discordgo.Session, guildID, ref string) string {
+	ref = strings.TrimPrefix(strings.TrimSpace(ref), "#")
+	if ref == "" || guildID == "" {
+		return ""
+	}
+
+	if !c.hasGuild(guildID) && s != nil {
+		if channels, err := s.GuildChannels(guildID); err == nil {
+			c.setGuildChannels(guildID, channels)
+		}
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	channels := c.guilds[guildID]
+	if channel, exists := channels[ref]; exists {
+		return channel.ID
+	}
+
+	// Pick the lowest matching ID so duplicate names resolve deterministically
+	match := ""
+	for _, channel := range channels {
+		if channel.Name == ref && channel.Type == discordgo.ChannelTypeGuildText {
+			if match == "" || channel.ID < match {
+				match = channel.ID
+			}
+		}
+	}
+	return match
+}
+
+func (c *ChannelCache) hasGuild(guildID string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, exists := c.guilds[guildID]
+	return exists
+}
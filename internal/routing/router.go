@@ -0,0 +1,242 @@
+package routing
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/pmurley/go-fantrax/models"
+)
+
+// Named channels that commands and jobs look up through the router
+const (
+	ChannelDFA          = "dfa"
+	ChannelCommissioner = "commissioner"
+	ChannelDigest       = "digest"
+)
+
+// Rule routes transactions that match all of its (non-empty) criteria to one
+// or more channels. Empty criteria match everything.
+type Rule struct {
+	Types      []string `json:"types,omitempty"`       // CLAIM, DROP, TRADE
+	ClaimTypes []string `json:"claim_types,omitempty"` // FA, WW
+	ExecutedBy []string `json:"executed_by,omitempty"` // e.g. COMMISSIONER
+	Teams      []string `json:"teams,omitempty"`       // Team, from-team or to-team name
+	Channels   []string `json:"channels"`              // Channel names or IDs
+}
+
+// GuildConfig holds the routing table for a single guild
+type GuildConfig struct {
+	Channels map[string]string `json:"channels,omitempty"` // Named channels, e.g. "dfa" -> "dfa-waivers"
+	Rules    []Rule            `json:"rules"`              // Evaluated in order, first match wins
+}
+
+// Config is the routing configuration for every guild the bot is in
+type Config struct {
+	Default *GuildConfig            `json:"default,omitempty"` // Used for guilds without their own entry
+	Guilds  map[string]*GuildConfig `json:"guilds,omitempty"`  // Keyed by guild ID
+}
+
+// DefaultGuildConfig returns the routing the league has always used
+func DefaultGuildConfig() *GuildConfig {
+	return &GuildConfig{
+		Channels: map[string]string{
+			ChannelDFA:          "dfa-waivers",
+			ChannelCommissioner: "commissioner",
+			ChannelDigest:       "general",
+		},
+		Rules: []Rule{
+			// Commissioner-executed claims are 40-man promotions
+			{Types: []string{"CLAIM"}, ClaimTypes: []string{"FA", "WW"}, ExecutedBy: []string{"COMMISSIONER"}, Channels: []string{"40-man-promotions"}},
+			{Types: []string{"CLAIM"}, ClaimTypes: []string{"FA"}, Channels: []string{"signings"}},
+			{Types: []string{"CLAIM"}, Channels: []string{"dfa-waivers"}},
+			{Types: []string{"DROP"}, Channels: []string{"dfa-waivers"}},
+			{Types: []string{"TRADE"}, Channels: []string{"trades"}},
+			{Channels: []string{"dfa-waivers"}},
+		},
+	}
+}
+
+// LoadConfig reads a routing configuration file. An empty path returns the default routing.
+func LoadConfig(path string) (*Config, error) {
+	if path == "" {
+		return &Config{Default: DefaultGuildConfig()}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read routing config: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse routing config: %w", err)
+	}
+
+	if cfg.Default == nil {
+		cfg.Default = DefaultGuildConfig()
+	}
+
+	return &cfg, nil
+}
+
+// guild returns the configuration that applies to a guild
+func (c *Config) guild(guildID string) *GuildConfig {
+	if gc, exists := c.Guilds[guildID]; exists && gc != nil {
+		return gc
+	}
+	return c.Default
+}
+
+// Router resolves transactions and named channels to Discord channel IDs
+type Router struct {
+	session  *discordgo.Session
+	cache    *ChannelCache
+	config   *Config
+	redirect string // When set, all output goes to this channel instead
+}
+
+// NewRouter creates a router backed by the given channel cache
+func NewRouter(session *discordgo.Session, cache *ChannelCache, config *Config) *Router {
+	return &Router{
+		session: session,
+		cache:   cache,
+		config:  config,
+	}
+}
+
+// RedirectAll overrides every rule and named channel with a single channel.
+// Staging mode uses this to keep all bot output in one test channel.
+func (r *Router) RedirectAll(ref string) {
+	r.redirect = ref
+}
+
+// Redirected reports whether all output is being sent to a single channel
+func (r *Router) Redirected() bool {
+	return r.redirect != ""
+}
+
+// RedirectChannel returns the ID of the redirect channel in a guild
+func (r *Router) RedirectChannel(guildID string) string {
+	if r.redirect == "" {
+		return ""
+	}
+	return r.cache.Resolve(r.session, guildID, r.redirect)
+}
+
+// TransactionChannels returns the IDs of every channel, across all guilds,
+// that the given transactions should be posted to. Trades should pass every
+// transaction in the trade group so team rules see both sides of the deal.
+func (r *Router) TransactionChannels(txs ...models.Transaction) []string {
+	if r.redirect != "" {
+		for _, guildID := range r.cache.Guilds(r.session) {
+			if channelID := r.RedirectChannel(guildID); channelID != "" {
+				return []string{channelID}
+			}
+		}
+		return nil
+	}
+
+	var channelIDs []string
+	seen := make(map[string]bool)
+
+	for _, guildID := range r.cache.Guilds(r.session) {
+		gc := r.config.guild(guildID)
+		if gc == nil {
+			continue
+		}
+
+		for _, rule := range gc.Rules {
+			if !rule.matches(txs) {
+				continue
+			}
+
+			for _, ref := range rule.Channels {
+				channelID := r.cache.Resolve(r.session, guildID, ref)
+				if channelID != "" && !seen[channelID] {
+					seen[channelID] = true
+					channelIDs = append(channelIDs, channelID)
+				}
+			}
+			break
+		}
+	}
+
+	return channelIDs
+}
+
+// NamedChannel returns the ID of a named channel (such as ChannelDFA) in a guild
+func (r *Router) NamedChannel(guildID, name string) string {
+	if r.redirect != "" {
+		return r.RedirectChannel(guildID)
+	}
+
+	gc := r.config.guild(guildID)
+	if gc == nil {
+		return ""
+	}
+
+	ref, exists := gc.Channels[name]
+	if !exists {
+		return ""
+	}
+	return r.cache.Resolve(r.session, guildID, ref)
+}
+
+// NamedChannels returns the ID of a named channel in every guild that has one
+func (r *Router) NamedChannels(name string) []string {
+	var channelIDs []string
+	seen := make(map[string]bool)
+
+	for _, guildID := range r.cache.Guilds(r.session) {
+		channelID := r.NamedChannel(guildID, name)
+		if channelID != "" && !seen[channelID] {
+			seen[channelID] = true
+			channelIDs = append(channelIDs, channelID)
+		}
+		if r.redirect != "" && channelID != "" {
+			break
+		}
+	}
+
+	return channelIDs
+}
+
+// matches reports whether any of the transactions satisfies every criterion of the rule
+func (rule Rule) matches(txs []models.Transaction) bool {
+	for _, tx := range txs {
+		if !matchesAny(rule.Types, tx.Type) {
+			continue
+		}
+		if len(rule.ClaimTypes) > 0 && (tx.Type != "CLAIM" || !matchesAny(rule.ClaimTypes, tx.ClaimType)) {
+			continue
+		}
+		if !matchesAny(rule.ExecutedBy, tx.ExecutedBy) {
+			continue
+		}
+		if !matchesAny(rule.Teams, tx.TeamName, tx.FromTeamName, tx.ToTeamName) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// matchesAny reports whether any value equals one of the allowed entries
+// (case-insensitive). An empty allow list matches everything.
+func matchesAny(allowed []string, values ...string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	for _, a := range allowed {
+		for _, v := range values {
+			if v != "" && strings.EqualFold(a, v) {
+				return true
+			}
+		}
+	}
+	return false
+}
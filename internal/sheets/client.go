@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/pmurley/ulb-bot/internal/cache"
@@ -49,6 +50,30 @@ func (c *Client) LoadMasterPlayerPool() ([]models.Player, error) {
 		return nil, err
 	}
 
+	return parsePlayerPool(data)
+}
+
+// LoadPlayerPoolFile loads players from a CSV export of the Master Player
+// Pool tab saved to disk, for working without network access
+func LoadPlayerPoolFile(path string) ([]models.Player, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open player pool file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	data, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read player pool file: %w", err)
+	}
+
+	return parsePlayerPool(data)
+}
+
+// parsePlayerPool parses the rows of the Master Player Pool tab
+func parsePlayerPool(data [][]string) ([]models.Player, error) {
 	if len(data) < 3 { // Need at least header rows and one data row
 		return nil, fmt.Errorf("insufficient data in player pool sheet")
 	}
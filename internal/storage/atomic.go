@@ -0,0 +1,143 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// backupSuffix names the copy of a file's previous contents kept by
+// replaceFile, used to recover from corruption
+const backupSuffix = ".bak"
+
+// fileMutexes holds one lock per storage file so every instance opened on
+// the same file in this process shares it
+var (
+	fileMutexesMu sync.Mutex
+	fileMutexes   = make(map[string]*sync.RWMutex)
+)
+
+// fileMutex returns the process-wide lock for a storage file
+func fileMutex(path string) *sync.RWMutex {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	fileMutexesMu.Lock()
+	defer fileMutexesMu.Unlock()
+
+	mu, exists := fileMutexes[path]
+	if !exists {
+		mu = &sync.RWMutex{}
+		fileMutexes[path] = mu
+	}
+	return mu
+}
+
+// writeCSVAtomic replaces the file with the given records
+func writeCSVAtomic(path string, records [][]string) error {
+	return replaceFile(path, func(w io.Writer) error {
+		return writeCSV(w, records)
+	})
+}
+
+// appendCSVAtomic replaces the file with its current contents followed by
+// the given records, so a crash never leaves a partly written row
+func appendCSVAtomic(path string, records [][]string) error {
+	current, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	if len(current) > 0 && !bytes.HasSuffix(current, []byte("\n")) {
+		current = append(current, '\n')
+	}
+
+	return replaceFile(path, func(w io.Writer) error {
+		if _, err := w.Write(current); err != nil {
+			return err
+		}
+		return writeCSV(w, records)
+	})
+}
+
+// writeCSV writes records and reports any write error
+func writeCSV(w io.Writer, records [][]string) error {
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(records); err != nil {
+		return fmt.Errorf("failed to write records: %w", err)
+	}
+	return nil
+}
+
+// replaceFile atomically replaces path with what write produces: it writes a
+// temp file in the same directory, syncs it and renames it over path. The
+// previous contents are first kept in path+backupSuffix.
+func replaceFile(path string, write func(w io.Writer) error) error {
+	dir := filepath.Dir(path)
+
+	if err := backupFile(path); err != nil {
+		return err
+	}
+
+	tmp, err := writeTemp(dir, filepath.Base(path), write)
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to replace %s: %w", filepath.Base(path), err)
+	}
+
+	return syncDir(dir)
+}
+
+// backupFile atomically copies path to path+backupSuffix. Missing files are
+// skipped.
+func backupFile(path string) error {
+	current, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+
+	tmp, err := writeTemp(filepath.Dir(path), filepath.Base(path)+backupSuffix, func(w io.Writer) error {
+		_, err := w.Write(current)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path+backupSuffix); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to back up %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
+
+// writeTemp writes a synced temp file in dir and returns its path
+func writeTemp(dir, name string, write func(w io.Writer) error) (string, error) {
+	file, err := os.CreateTemp(dir, "."+name+".tmp-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file for %s: %w", name, err)
+	}
+
+	err = write(file)
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write %s: %w", name, err)
+	}
+
+	return file.Name(), nil
+}
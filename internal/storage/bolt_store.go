@@ -0,0 +1,859 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	fantraxmodels "github.com/pmurley/go-fantrax/models"
+	"github.com/pmurley/ulb-bot/internal/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+const boltFileName = "ulb.db"
+
+// boltOpenTimeout is how long to wait for another process holding the database
+const boltOpenTimeout = 5 * time.Second
+
+// Record buckets are keyed by an insertion sequence so records read back in
+// the order they were stored, like the CSV files. Index buckets map
+// "<value>\x00<sequence>" to an empty value.
+var (
+	metaBucket                 = []byte("meta")
+	transactionsBucket         = []byte("transactions")
+	txByIDBucket               = []byte("transactions_by_id") // ID -> sequence
+	txByTradeGroupBucket       = []byte("transactions_by_trade_group")
+	txByPlayerBucket           = []byte("transactions_by_player")
+	waiversBucket              = []byte("waivers")
+	waiversByMessageBucket     = []byte("waivers_by_message")
+	activeWaiversBucket        = []byte("waivers_active") // sequence -> empty, unprocessed waivers only
+	messagesBucket             = []byte("messages")
+	messagesByTxBucket         = []byte("messages_by_transaction")
+	messagesByTradeGroupBucket = []byte("messages_by_trade_group")
+	retentionsBucket           = []byte("retentions")    // "<trade group>\x00<player>" -> retention
+	sheetChangesBucket         = []byte("sheet_changes") // detected time + sequence -> change
+)
+
+var schemaVersionKey = []byte("schema_version")
+
+// csvImportedKey records when CSV storage was imported into the database
+var csvImportedKey = []byte("csv_imported")
+
+// boltMigrations bring a database up to the current schema. The stored
+// schema version is the number applied so far; only ever append.
+var boltMigrations = []func(tx *bolt.Tx) error{
+	createRecordBuckets,
+	createIndexes,
+}
+
+// BoltStore keeps every record type in a single bbolt database file
+type BoltStore struct {
+	db   *bolt.DB
+	lock *dirLock
+}
+
+// OpenBolt opens (or creates) the database in dataDir and applies any
+// pending schema migrations
+func OpenBolt(dataDir string) (Store, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	// bbolt locks its own file; the directory lock also keeps out maintenance
+	// commands and bots using the CSV backend
+	lock, err := lockDir(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	path := filepath.Join(dataDir, boltFileName)
+	db, err := bolt.Open(path, 0644, &bolt.Options{Timeout: boltOpenTimeout})
+	if err != nil {
+		lock.unlock()
+		return nil, fmt.Errorf("failed to open database %s: %w", path, err)
+	}
+
+	if err := migrateBolt(db); err != nil {
+		db.Close()
+		lock.unlock()
+		return nil, err
+	}
+
+	return &BoltStore{db: db, lock: lock}, nil
+}
+
+// migrateBolt applies the migrations the database has not seen yet
+func migrateBolt(db *bolt.DB) error {
+	return db.Update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists(metaBucket)
+		if err != nil {
+			return fmt.Errorf("failed to create meta bucket: %w", err)
+		}
+
+		version := 0
+		if v := meta.Get(schemaVersionKey); v != nil {
+			version = int(binary.BigEndian.Uint64(v))
+		}
+		if version > len(boltMigrations) {
+			return fmt.Errorf("database schema version %d is newer than this build supports (%d)", version, len(boltMigrations))
+		}
+
+		for i := version; i < len(boltMigrations); i++ {
+			if err := boltMigrations[i](tx); err != nil {
+				return fmt.Errorf("failed to apply storage migration %d: %w", i+1, err)
+			}
+		}
+
+		return meta.Put(schemaVersionKey, itob(uint64(len(boltMigrations))))
+	})
+}
+
+// createRecordBuckets is migration 1: one bucket per record type
+func createRecordBuckets(tx *bolt.Tx) error {
+	for _, name := range [][]byte{transactionsBucket, waiversBucket, messagesBucket, retentionsBucket, sheetChangesBucket} {
+		if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createIndexes is migration 2: lookups by transaction ID, trade group,
+// player and message, built from any records already stored
+func createIndexes(tx *bolt.Tx) error {
+	for _, name := range [][]byte{txByIDBucket, txByTradeGroupBucket, txByPlayerBucket, waiversByMessageBucket, activeWaiversBucket, messagesByTxBucket, messagesByTradeGroupBucket} {
+		if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+			return err
+		}
+	}
+
+	err := tx.Bucket(transactionsBucket).ForEach(func(seq, data []byte) error {
+		var row storedTransaction
+		if err := json.Unmarshal(data, &row); err != nil {
+			return err
+		}
+		if err := tx.Bucket(txByIDBucket).Put([]byte(row.ID), seq); err != nil {
+			return err
+		}
+		return indexTransaction(tx, row.Transaction, seq, true)
+	})
+	if err != nil {
+		return err
+	}
+
+	err = tx.Bucket(waiversBucket).ForEach(func(seq, data []byte) error {
+		var waiver models.Waiver
+		if err := json.Unmarshal(data, &waiver); err != nil {
+			return err
+		}
+		return indexWaiver(tx, &waiver, seq)
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Bucket(messagesBucket).ForEach(func(seq, data []byte) error {
+		var ref MessageRef
+		if err := json.Unmarshal(data, &ref); err != nil {
+			return err
+		}
+		return indexMessageRef(tx, ref, seq)
+	})
+}
+
+func (s *BoltStore) Transactions() TransactionStore { return &boltTransactions{db: s.db} }
+func (s *BoltStore) Waivers() WaiverStore           { return &boltWaivers{db: s.db} }
+func (s *BoltStore) Messages() MessageStore         { return &boltMessages{db: s.db} }
+func (s *BoltStore) Retentions() RetentionStore     { return &boltRetentions{db: s.db} }
+func (s *BoltStore) SheetChanges() SheetChangeStore { return &boltSheetChanges{db: s.db} }
+
+// boltDB runs the record stores' reads and writes: a *bolt.DB gives each its
+// own transaction, a boltTx runs them all in one
+type boltDB interface {
+	Update(fn func(*bolt.Tx) error) error
+	View(fn func(*bolt.Tx) error) error
+}
+
+// boltTx runs every operation in one open read-write transaction
+type boltTx struct {
+	tx *bolt.Tx
+}
+
+func (t boltTx) Update(fn func(*bolt.Tx) error) error { return fn(t.tx) }
+func (t boltTx) View(fn func(*bolt.Tx) error) error   { return fn(t.tx) }
+
+// boltTxStore is a Store whose operations all belong to one transaction,
+// committed or rolled back together by whoever opened it
+type boltTxStore struct {
+	db boltTx
+}
+
+func (s boltTxStore) Transactions() TransactionStore { return &boltTransactions{db: s.db} }
+func (s boltTxStore) Waivers() WaiverStore           { return &boltWaivers{db: s.db} }
+func (s boltTxStore) Messages() MessageStore         { return &boltMessages{db: s.db} }
+func (s boltTxStore) Retentions() RetentionStore     { return &boltRetentions{db: s.db} }
+func (s boltTxStore) SheetChanges() SheetChangeStore { return &boltSheetChanges{db: s.db} }
+func (s boltTxStore) Close() error                   { return nil }
+
+// Close closes the database file and releases the data directory
+func (s *BoltStore) Close() error {
+	defer s.lock.unlock()
+	return s.db.Close()
+}
+
+// Snapshot writes a consistent copy of the open database
+func (s *BoltStore) Snapshot(w io.Writer) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(w)
+		return err
+	})
+}
+
+// SnapshotBolt writes a copy of the database in dataDir when no store has it
+// open. It fails while a running bot holds the database.
+func SnapshotBolt(dataDir string, w io.Writer) error {
+	path := filepath.Join(dataDir, boltFileName)
+	db, err := bolt.Open(path, 0644, &bolt.Options{ReadOnly: true, Timeout: boltOpenTimeout})
+	if err != nil {
+		return fmt.Errorf("failed to open database %s (is the bot running?): %w", path, err)
+	}
+	defer db.Close()
+
+	return db.View(func(tx *bolt.Tx) error {
+		_, err := tx.WriteTo(w)
+		return err
+	})
+}
+
+// readBoltTransactions reads the transactions from the database in dataDir
+// when no store has it open
+func readBoltTransactions(dataDir string) ([]fantraxmodels.Transaction, error) {
+	path := filepath.Join(dataDir, boltFileName)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	db, err := bolt.Open(path, 0644, &bolt.Options{ReadOnly: true, Timeout: boltOpenTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database %s (is the bot running?): %w", path, err)
+	}
+	defer db.Close()
+
+	empty := false
+	db.View(func(tx *bolt.Tx) error {
+		empty = tx.Bucket(transactionsBucket) == nil
+		return nil
+	})
+	if empty {
+		return nil, nil
+	}
+	return (&boltTransactions{db: db}).GetAllTransactions()
+}
+
+// itob encodes a sequence so keys sort numerically
+func itob(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
+
+// indexKey is the index entry for value pointing at the record at seq
+func indexKey(value string, seq []byte) []byte {
+	key := make([]byte, 0, len(value)+1+len(seq))
+	key = append(key, value...)
+	key = append(key, 0)
+	return append(key, seq...)
+}
+
+// indexValue returns the indexed value of an index key
+func indexValue(key []byte) string {
+	return string(key[:len(key)-9])
+}
+
+// setIndex adds or removes an index entry. Empty values are not indexed.
+func setIndex(b *bolt.Bucket, value string, seq []byte, add bool) error {
+	if value == "" {
+		return nil
+	}
+	if add {
+		return b.Put(indexKey(value, seq), []byte{})
+	}
+	return b.Delete(indexKey(value, seq))
+}
+
+// lookupIndex returns the sequences of the records indexed under value
+func lookupIndex(b *bolt.Bucket, value string) [][]byte {
+	prefix := append([]byte(value), 0)
+
+	var seqs [][]byte
+	c := b.Cursor()
+	for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+		seqs = append(seqs, bytes.Clone(k[len(prefix):]))
+	}
+	return seqs
+}
+
+// boltTransactions is the TransactionStore of a BoltStore
+type boltTransactions struct {
+	db boltDB
+}
+
+// indexTransaction adds or removes a transaction's trade group and player index entries
+func indexTransaction(tx *bolt.Tx, transaction fantraxmodels.Transaction, seq []byte, add bool) error {
+	if err := setIndex(tx.Bucket(txByTradeGroupBucket), transaction.TradeGroupID, seq, add); err != nil {
+		return err
+	}
+	return setIndex(tx.Bucket(txByPlayerBucket), playerKey(transaction.PlayerName), seq, add)
+}
+
+// putTransaction stores a transaction, replacing the record with the same ID.
+// An empty status keeps the replaced record's status.
+func putTransaction(tx *bolt.Tx, row storedTransaction) error {
+	records := tx.Bucket(transactionsBucket)
+	byID := tx.Bucket(txByIDBucket)
+
+	seq := bytes.Clone(byID.Get([]byte(row.ID)))
+	if seq != nil {
+		old, err := getTransaction(records, seq)
+		if err != nil {
+			return err
+		}
+		if row.Status == "" {
+			row.Status = old.Status
+		}
+		if err := indexTransaction(tx, old.Transaction, seq, false); err != nil {
+			return err
+		}
+	} else {
+		next, err := records.NextSequence()
+		if err != nil {
+			return err
+		}
+		seq = itob(next)
+		if err := byID.Put([]byte(row.ID), seq); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.Marshal(row)
+	if err != nil {
+		return fmt.Errorf("failed to encode transaction %s: %w", row.ID, err)
+	}
+	if err := records.Put(seq, data); err != nil {
+		return err
+	}
+	return indexTransaction(tx, row.Transaction, seq, true)
+}
+
+// getTransaction decodes the transaction stored at seq
+func getTransaction(records *bolt.Bucket, seq []byte) (storedTransaction, error) {
+	var row storedTransaction
+	data := records.Get(seq)
+	if data == nil {
+		return row, fmt.Errorf("transaction record %x not found", seq)
+	}
+	if err := json.Unmarshal(data, &row); err != nil {
+		return row, fmt.Errorf("failed to decode transaction record: %w", err)
+	}
+	return row, nil
+}
+
+// AddTransactions stores new transactions
+func (s *boltTransactions) AddTransactions(transactions []fantraxmodels.Transaction) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		for _, transaction := range transactions {
+			if err := putTransaction(tx, storedTransaction{Transaction: transaction}); err != nil {
+				return fmt.Errorf("failed to store transaction: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetAllTransactions returns all stored transactions except reversed ones
+func (s *boltTransactions) GetAllTransactions() ([]fantraxmodels.Transaction, error) {
+	var transactions []fantraxmodels.Transaction
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(transactionsBucket).ForEach(func(_, data []byte) error {
+			var row storedTransaction
+			if err := json.Unmarshal(data, &row); err != nil {
+				return fmt.Errorf("failed to decode transaction record: %w", err)
+			}
+			if row.Status != TransactionStatusReversed {
+				transactions = append(transactions, row.Transaction)
+			}
+			return nil
+		})
+	})
+	return transactions, err
+}
+
+// lookupTransactions returns the non-reversed transactions an index lists under value
+func (s *boltTransactions) lookupTransactions(index []byte, value string) ([]fantraxmodels.Transaction, error) {
+	var transactions []fantraxmodels.Transaction
+	err := s.db.View(func(tx *bolt.Tx) error {
+		records := tx.Bucket(transactionsBucket)
+		for _, seq := range lookupIndex(tx.Bucket(index), value) {
+			row, err := getTransaction(records, seq)
+			if err != nil {
+				return err
+			}
+			if row.Status != TransactionStatusReversed {
+				transactions = append(transactions, row.Transaction)
+			}
+		}
+		return nil
+	})
+	return transactions, err
+}
+
+// GetTradeGroup returns the stored pieces of one trade
+func (s *boltTransactions) GetTradeGroup(tradeGroupID string) ([]fantraxmodels.Transaction, error) {
+	if tradeGroupID == "" {
+		return nil, nil
+	}
+	return s.lookupTransactions(txByTradeGroupBucket, tradeGroupID)
+}
+
+// GetPlayerTransactions returns every stored transaction involving a player
+func (s *boltTransactions) GetPlayerTransactions(playerName string) ([]fantraxmodels.Transaction, error) {
+	key := playerKey(playerName)
+	if key == "" {
+		return nil, nil
+	}
+	return s.lookupTransactions(txByPlayerBucket, key)
+}
+
+// GetTransactionIDs returns a set of all stored transaction IDs, including reversed ones
+func (s *boltTransactions) GetTransactionIDs() (map[string]bool, error) {
+	ids := make(map[string]bool)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(txByIDBucket).ForEach(func(id, _ []byte) error {
+			ids[string(id)] = true
+			return nil
+		})
+	})
+	return ids, err
+}
+
+// GetTradeGroupIDs returns a set of all stored trade group IDs
+func (s *boltTransactions) GetTradeGroupIDs() (map[string]bool, error) {
+	groupIDs := make(map[string]bool)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(txByTradeGroupBucket).ForEach(func(key, _ []byte) error {
+			groupIDs[indexValue(key)] = true
+			return nil
+		})
+	})
+	return groupIDs, err
+}
+
+// UpdateTransactions replaces stored transactions (matched by ID) with their
+// new values. A non-empty status is recorded; an empty one keeps the current status.
+func (s *boltTransactions) UpdateTransactions(transactions []fantraxmodels.Transaction, status string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		byID := tx.Bucket(txByIDBucket)
+		for _, transaction := range transactions {
+			if byID.Get([]byte(transaction.ID)) == nil {
+				continue
+			}
+			if err := putTransaction(tx, storedTransaction{Transaction: transaction, Status: status}); err != nil {
+				return fmt.Errorf("failed to update transaction: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// MarkTransactions records a status on the stored transactions with the given IDs
+func (s *boltTransactions) MarkTransactions(ids []string, status string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		records := tx.Bucket(transactionsBucket)
+		byID := tx.Bucket(txByIDBucket)
+		for _, id := range ids {
+			seq := byID.Get([]byte(id))
+			if seq == nil {
+				continue
+			}
+			row, err := getTransaction(records, seq)
+			if err != nil {
+				return err
+			}
+			row.Status = status
+			data, err := json.Marshal(row)
+			if err != nil {
+				return fmt.Errorf("failed to encode transaction %s: %w", id, err)
+			}
+			if err := records.Put(seq, data); err != nil {
+				return fmt.Errorf("failed to mark transaction %s: %w", id, err)
+			}
+		}
+		return nil
+	})
+}
+
+// boltWaivers is the WaiverStore of a BoltStore
+type boltWaivers struct {
+	db boltDB
+}
+
+// indexWaiver adds a waiver's message and active index entries
+func indexWaiver(tx *bolt.Tx, waiver *models.Waiver, seq []byte) error {
+	if err := setIndex(tx.Bucket(waiversByMessageBucket), waiver.MessageID, seq, true); err != nil {
+		return err
+	}
+	if waiver.Processed {
+		return nil
+	}
+	return tx.Bucket(activeWaiversBucket).Put(seq, []byte{})
+}
+
+// AddWaiver stores a new waiver
+func (s *boltWaivers) AddWaiver(waiver *models.Waiver) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		records := tx.Bucket(waiversBucket)
+		next, err := records.NextSequence()
+		if err != nil {
+			return err
+		}
+		seq := itob(next)
+
+		data, err := json.Marshal(waiver)
+		if err != nil {
+			return fmt.Errorf("failed to encode waiver: %w", err)
+		}
+		if err := records.Put(seq, data); err != nil {
+			return fmt.Errorf("failed to store waiver: %w", err)
+		}
+		return indexWaiver(tx, waiver, seq)
+	})
+}
+
+// getWaiver decodes the waiver stored at seq
+func getWaiver(records *bolt.Bucket, seq []byte) (*models.Waiver, error) {
+	data := records.Get(seq)
+	if data == nil {
+		return nil, fmt.Errorf("waiver record %x not found", seq)
+	}
+	var waiver models.Waiver
+	if err := json.Unmarshal(data, &waiver); err != nil {
+		return nil, fmt.Errorf("failed to decode waiver record: %w", err)
+	}
+	return &waiver, nil
+}
+
+// GetActiveWaivers returns all unprocessed waivers
+func (s *boltWaivers) GetActiveWaivers() ([]*models.Waiver, error) {
+	var waivers []*models.Waiver
+	err := s.db.View(func(tx *bolt.Tx) error {
+		records := tx.Bucket(waiversBucket)
+		return tx.Bucket(activeWaiversBucket).ForEach(func(seq, _ []byte) error {
+			waiver, err := getWaiver(records, seq)
+			if err != nil {
+				return err
+			}
+			waivers = append(waivers, waiver)
+			return nil
+		})
+	})
+	return waivers, err
+}
+
+// GetAllWaivers returns every stored waiver, including processed ones
+func (s *boltWaivers) GetAllWaivers() ([]*models.Waiver, error) {
+	var waivers []*models.Waiver
+	err := s.db.View(func(tx *bolt.Tx) error {
+		records := tx.Bucket(waiversBucket)
+		return records.ForEach(func(seq, _ []byte) error {
+			waiver, err := getWaiver(records, seq)
+			if err != nil {
+				return err
+			}
+			waivers = append(waivers, waiver)
+			return nil
+		})
+	})
+	return waivers, err
+}
+
+// MarkWaiverProcessed marks every waiver announced by the message as processed
+func (s *boltWaivers) MarkWaiverProcessed(messageID string) error {
+	return s.markWaivers(messageID, false)
+}
+
+// CancelWaiver marks every waiver announced by the message as processed and
+// cancelled, so they are not reported as expired
+func (s *boltWaivers) CancelWaiver(messageID string) error {
+	return s.markWaivers(messageID, true)
+}
+
+// markWaivers sets the processed flag, and optionally the cancelled flag, on
+// every waiver announced by the message
+func (s *boltWaivers) markWaivers(messageID string, cancel bool) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		records := tx.Bucket(waiversBucket)
+		seqs := lookupIndex(tx.Bucket(waiversByMessageBucket), messageID)
+		if len(seqs) == 0 {
+			return fmt.Errorf("waiver with message ID %s not found", messageID)
+		}
+
+		for _, seq := range seqs {
+			waiver, err := getWaiver(records, seq)
+			if err != nil {
+				return err
+			}
+			waiver.Processed = true
+			if cancel {
+				waiver.Cancelled = true
+			}
+			data, err := json.Marshal(waiver)
+			if err != nil {
+				return fmt.Errorf("failed to encode waiver: %w", err)
+			}
+			if err := records.Put(seq, data); err != nil {
+				return fmt.Errorf("failed to update waiver: %w", err)
+			}
+			if err := tx.Bucket(activeWaiversBucket).Delete(seq); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// boltMessages is the MessageStore of a BoltStore
+type boltMessages struct {
+	db boltDB
+}
+
+// indexMessageRef adds a ref's transaction and trade group index entries
+func indexMessageRef(tx *bolt.Tx, ref MessageRef, seq []byte) error {
+	if err := setIndex(tx.Bucket(messagesByTxBucket), ref.TransactionID, seq, true); err != nil {
+		return err
+	}
+	return setIndex(tx.Bucket(messagesByTradeGroupBucket), ref.TradeGroupID, seq, true)
+}
+
+// putMessageRefs stores refs under new sequences
+func putMessageRefs(tx *bolt.Tx, refs []MessageRef) error {
+	records := tx.Bucket(messagesBucket)
+	for _, ref := range refs {
+		next, err := records.NextSequence()
+		if err != nil {
+			return err
+		}
+		seq := itob(next)
+
+		data, err := json.Marshal(ref)
+		if err != nil {
+			return fmt.Errorf("failed to encode message ref: %w", err)
+		}
+		if err := records.Put(seq, data); err != nil {
+			return fmt.Errorf("failed to store message ref: %w", err)
+		}
+		if err := indexMessageRef(tx, ref, seq); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddMessageRefs stores message references
+func (s *boltMessages) AddMessageRefs(refs []MessageRef) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return putMessageRefs(tx, refs)
+	})
+}
+
+// lookupRefs returns the refs an index lists under value
+func (s *boltMessages) lookupRefs(index []byte, value string) ([]MessageRef, error) {
+	var refs []MessageRef
+	err := s.db.View(func(tx *bolt.Tx) error {
+		records := tx.Bucket(messagesBucket)
+		for _, seq := range lookupIndex(tx.Bucket(index), value) {
+			var ref MessageRef
+			if err := json.Unmarshal(records.Get(seq), &ref); err != nil {
+				return fmt.Errorf("failed to decode message ref: %w", err)
+			}
+			refs = append(refs, ref)
+		}
+		return nil
+	})
+	return refs, err
+}
+
+// GetMessageRefs returns every message that announced the given transaction
+func (s *boltMessages) GetMessageRefs(transactionID string) ([]MessageRef, error) {
+	if transactionID == "" {
+		return nil, nil
+	}
+	return s.lookupRefs(messagesByTxBucket, transactionID)
+}
+
+// GetTradeGroupRefs returns every message that announced the given trade group,
+// one per message
+func (s *boltMessages) GetTradeGroupRefs(tradeGroupID string) ([]MessageRef, error) {
+	if tradeGroupID == "" {
+		return nil, nil
+	}
+	refs, err := s.lookupRefs(messagesByTradeGroupBucket, tradeGroupID)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []MessageRef
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		if !seen[ref.MessageID] {
+			seen[ref.MessageID] = true
+			matches = append(matches, ref)
+		}
+	}
+	return matches, nil
+}
+
+// GetAllMessageRefs returns every stored message reference
+func (s *boltMessages) GetAllMessageRefs() ([]MessageRef, error) {
+	var refs []MessageRef
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(messagesBucket).ForEach(func(_, data []byte) error {
+			var ref MessageRef
+			if err := json.Unmarshal(data, &ref); err != nil {
+				return fmt.Errorf("failed to decode message ref: %w", err)
+			}
+			refs = append(refs, ref)
+			return nil
+		})
+	})
+	return refs, err
+}
+
+// Migrate fills in trade group IDs and DROP refs older versions never
+// recorded. It is safe to run on every startup.
+func (s *boltMessages) Migrate(transactions []fantraxmodels.Transaction, waivers []*models.Waiver) (int, error) {
+	refs, err := s.GetAllMessageRefs()
+	if err != nil {
+		return 0, err
+	}
+
+	refs, changed := backfillMessageRefs(refs, transactions, waivers)
+	if changed == 0 {
+		return 0, nil
+	}
+
+	// Rebuild the refs and their indexes in one transaction
+	return changed, s.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{messagesBucket, messagesByTxBucket, messagesByTradeGroupBucket} {
+			if err := tx.DeleteBucket(name); err != nil {
+				return err
+			}
+			if _, err := tx.CreateBucket(name); err != nil {
+				return err
+			}
+		}
+		return putMessageRefs(tx, refs)
+	})
+}
+
+// boltRetentions is the RetentionStore of a BoltStore
+type boltRetentions struct {
+	db boltDB
+}
+
+// SetRetention records retention for a player in a trade, replacing any
+// earlier value. A percent of zero removes it.
+func (s *boltRetentions) SetRetention(retention Retention) error {
+	key := indexKey(retention.TradeGroupID, []byte(strings.ToLower(retention.PlayerName)))
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		records := tx.Bucket(retentionsBucket)
+		if retention.Percent <= 0 {
+			return records.Delete(key)
+		}
+
+		data, err := json.Marshal(retention)
+		if err != nil {
+			return fmt.Errorf("failed to encode retention: %w", err)
+		}
+		return records.Put(key, data)
+	})
+}
+
+// GetRetentions returns the retention recorded for a trade group
+func (s *boltRetentions) GetRetentions(tradeGroupID string) ([]Retention, error) {
+	prefix := append([]byte(tradeGroupID), 0)
+
+	var retentions []Retention
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(retentionsBucket).Cursor()
+		for k, data := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, data = c.Next() {
+			var r Retention
+			if err := json.Unmarshal(data, &r); err != nil {
+				return fmt.Errorf("failed to decode retention: %w", err)
+			}
+			retentions = append(retentions, r)
+		}
+		return nil
+	})
+	return retentions, err
+}
+
+// boltSheetChanges is the SheetChangeStore of a BoltStore
+type boltSheetChanges struct {
+	db boltDB
+}
+
+// timeKey encodes a time so keys sort chronologically. Times before the
+// epoch sort first.
+func timeKey(t time.Time) []byte {
+	if t.Before(time.Unix(0, 0)) {
+		return itob(0)
+	}
+	return itob(uint64(t.UnixNano()))
+}
+
+// AddChanges stores changes detected at the given time
+func (s *boltSheetChanges) AddChanges(detectedAt time.Time, changes []models.PlayerChange) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		records := tx.Bucket(sheetChangesBucket)
+		for _, c := range changes {
+			next, err := records.NextSequence()
+			if err != nil {
+				return err
+			}
+
+			data, err := json.Marshal(SheetChange{DetectedAt: detectedAt, PlayerChange: c})
+			if err != nil {
+				return fmt.Errorf("failed to encode sheet change: %w", err)
+			}
+			if err := records.Put(append(timeKey(detectedAt), itob(next)...), data); err != nil {
+				return fmt.Errorf("failed to store sheet change: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// GetChangesBetween returns changes detected in [start, end)
+func (s *boltSheetChanges) GetChangesBetween(start, end time.Time) ([]SheetChange, error) {
+	endKey := timeKey(end)
+
+	var changes []SheetChange
+	err := s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(sheetChangesBucket).Cursor()
+		for k, data := c.Seek(timeKey(start)); k != nil && bytes.Compare(k[:8], endKey) < 0; k, data = c.Next() {
+			var change SheetChange
+			if err := json.Unmarshal(data, &change); err != nil {
+				return fmt.Errorf("failed to decode sheet change: %w", err)
+			}
+			changes = append(changes, change)
+		}
+		return nil
+	})
+	return changes, err
+}
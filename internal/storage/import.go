@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	fantraxmodels "github.com/pmurley/go-fantrax/models"
+	"github.com/pmurley/ulb-bot/internal/models"
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrStoreNotEmpty is returned when importing into a store that already has records
+var ErrStoreNotEmpty = errors.New("destination store already has records")
+
+// ImportResult counts the records copied by ImportCSV
+type ImportResult struct {
+	Transactions int
+	Waivers      int
+	Messages     int
+	Retentions   int
+	SheetChanges int
+}
+
+func (r ImportResult) String() string {
+	return fmt.Sprintf("%d transactions, %d waivers, %d message refs, %d retentions, %d sheet changes",
+		r.Transactions, r.Waivers, r.Messages, r.Retentions, r.SheetChanges)
+}
+
+// csvFileNames are the files the CSV backend keeps records in
+var csvFileNames = []string{transactionFileName, waiverFileName, messageFileName, retentionFileName, sheetChangeFileName}
+
+// HasCSVData reports whether dataDir holds any CSV storage file
+func HasCSVData(dataDir string) bool {
+	for _, name := range csvFileNames {
+		if _, err := os.Stat(filepath.Join(dataDir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// CSVImported reports whether CSV storage was already imported into s, or
+// marked as not needing an import. Only the bolt backend keeps the marker.
+func CSVImported(s Store) (bool, error) {
+	b, ok := s.(*BoltStore)
+	if !ok {
+		return false, nil
+	}
+
+	imported := false
+	err := b.db.View(func(tx *bolt.Tx) error {
+		imported = tx.Bucket(metaBucket).Get(csvImportedKey) != nil
+		return nil
+	})
+	return imported, err
+}
+
+// MarkCSVImported records that s needs no CSV import, so later starts skip it
+func MarkCSVImported(s Store) error {
+	b, ok := s.(*BoltStore)
+	if !ok {
+		return nil
+	}
+	return b.db.Update(markCSVImported)
+}
+
+func markCSVImported(tx *bolt.Tx) error {
+	if err := tx.Bucket(metaBucket).Put(csvImportedKey, []byte(time.Now().UTC().Format(time.RFC3339))); err != nil {
+		return fmt.Errorf("failed to record CSV import: %w", err)
+	}
+	return nil
+}
+
+// ImportCSV copies every record in the CSV files in dataDir into dst,
+// keeping transaction statuses and processed waivers. dst must be empty so
+// nothing is imported twice. Missing CSV files are skipped, not created.
+//
+// A bolt store is filled in a single transaction that also marks the import
+// done, so a failed import leaves it empty and the next start tries again.
+func ImportCSV(dataDir string, dst Store) (ImportResult, error) {
+	b, ok := dst.(*BoltStore)
+	if !ok {
+		return importCSV(dataDir, dst)
+	}
+
+	var result ImportResult
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		var err error
+		if result, err = importCSV(dataDir, boltTxStore{db: boltTx{tx: tx}}); err != nil {
+			return err
+		}
+		return markCSVImported(tx)
+	})
+	if err != nil {
+		return ImportResult{}, err
+	}
+	return result, nil
+}
+
+// importCSV copies the CSV records into dst
+func importCSV(dataDir string, dst Store) (ImportResult, error) {
+	var result ImportResult
+
+	if empty, err := isEmpty(dst); err != nil {
+		return result, err
+	} else if !empty {
+		return result, ErrStoreNotEmpty
+	}
+
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(dataDir, name))
+		return err == nil
+	}
+
+	// The source files are only read; opening them as storage would migrate
+	// them in place
+	rows, err := readCSVStoredTransactions(dataDir)
+	if err != nil {
+		return result, err
+	}
+	if result.Transactions, err = importTransactions(rows, dst.Transactions()); err != nil {
+		return result, err
+	}
+
+	waivers, err := readCSVWaivers(dataDir)
+	if err != nil {
+		return result, err
+	}
+	for _, waiver := range waivers {
+		if err := dst.Waivers().AddWaiver(waiver); err != nil {
+			return result, fmt.Errorf("failed to import waiver: %w", err)
+		}
+	}
+	result.Waivers = len(waivers)
+
+	if exists(messageFileName) {
+		src, err := NewMessageStorage(dataDir)
+		if err != nil {
+			return result, err
+		}
+		refs, err := src.GetAllMessageRefs()
+		if err != nil {
+			return result, err
+		}
+		if err := dst.Messages().AddMessageRefs(refs); err != nil {
+			return result, fmt.Errorf("failed to import message refs: %w", err)
+		}
+		result.Messages = len(refs)
+	}
+
+	if exists(retentionFileName) {
+		src, err := NewRetentionStorage(dataDir)
+		if err != nil {
+			return result, err
+		}
+		retentions, err := src.readRetentions()
+		if err != nil {
+			return result, err
+		}
+		for _, r := range retentions {
+			if err := dst.Retentions().SetRetention(r); err != nil {
+				return result, fmt.Errorf("failed to import retention: %w", err)
+			}
+		}
+		result.Retentions = len(retentions)
+	}
+
+	if exists(sheetChangeFileName) {
+		src, err := NewSheetChangeStorage(dataDir)
+		if err != nil {
+			return result, err
+		}
+		changes, err := src.GetChangesBetween(time.Time{}, time.Now().AddDate(100, 0, 0))
+		if err != nil {
+			return result, err
+		}
+		for _, c := range changes {
+			if err := dst.SheetChanges().AddChanges(c.DetectedAt, []models.PlayerChange{c.PlayerChange}); err != nil {
+				return result, fmt.Errorf("failed to import sheet change: %w", err)
+			}
+		}
+		result.SheetChanges = len(changes)
+	}
+
+	return result, nil
+}
+
+// importTransactions copies transactions, then re-applies their statuses
+func importTransactions(rows []storedTransaction, dst TransactionStore) (int, error) {
+	transactions := make([]fantraxmodels.Transaction, 0, len(rows))
+	byStatus := make(map[string][]string)
+	for _, row := range rows {
+		transactions = append(transactions, row.Transaction)
+		if row.Status != "" {
+			byStatus[row.Status] = append(byStatus[row.Status], row.ID)
+		}
+	}
+
+	if err := dst.AddTransactions(transactions); err != nil {
+		return 0, fmt.Errorf("failed to import transactions: %w", err)
+	}
+	for status, ids := range byStatus {
+		if err := dst.MarkTransactions(ids, status); err != nil {
+			return 0, fmt.Errorf("failed to import transaction statuses: %w", err)
+		}
+	}
+
+	return len(transactions), nil
+}
+
+// isEmpty reports whether a store has no transactions, waivers or message refs
+func isEmpty(s Store) (bool, error) {
+	ids, err := s.Transactions().GetTransactionIDs()
+	if err != nil {
+		return false, err
+	}
+	waivers, err := s.Waivers().GetAllWaivers()
+	if err != nil {
+		return false, err
+	}
+	refs, err := s.Messages().GetAllMessageRefs()
+	if err != nil {
+		return false, err
+	}
+	return len(ids) == 0 && len(waivers) == 0 && len(refs) == 0, nil
+}
@@ -0,0 +1,22 @@
+//go:build !unix
+
+package storage
+
+// dirLock is a no-op where flock is not available
+type dirLock struct{}
+
+// lockDir does nothing on this platform; running two bots on one data
+// directory is not detected
+func lockDir(dataDir string) (*dirLock, error) {
+	return &dirLock{}, nil
+}
+
+// unlock does nothing on this platform
+func (l *dirLock) unlock() error {
+	return nil
+}
+
+// syncDir does nothing on this platform, where directories cannot be synced
+func syncDir(dir string) error {
+	return nil
+}
@@ -0,0 +1,56 @@
+//go:build unix
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// dirLock is an advisory lock on a data directory, held while it is open
+type dirLock struct {
+	file *os.File
+}
+
+// lockDir takes an exclusive flock on dataDir/.lock. It fails immediately
+// when another process holds it.
+func lockDir(dataDir string) (*dirLock, error) {
+	path := filepath.Join(dataDir, lockFileName)
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file: %w", err)
+	}
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, fmt.Errorf("%w: %s", ErrDataDirLocked, dataDir)
+		}
+		return nil, fmt.Errorf("failed to lock data directory: %w", err)
+	}
+
+	return &dirLock{file: file}, nil
+}
+
+// unlock releases the lock
+func (l *dirLock) unlock() error {
+	defer l.file.Close()
+	return syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN)
+}
+
+// syncDir flushes a directory so renames in it survive a crash
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open directory for sync: %w", err)
+	}
+	defer d.Close()
+
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("failed to sync directory: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+package storage
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	fantraxmodels "github.com/pmurley/go-fantrax/models"
+	"github.com/pmurley/ulb-bot/internal/models"
+)
+
+const messageFileName = "messages.csv"
+
+// messageHeaders are the CSV columns, in order. Columns are only ever appended.
+var messageHeaders = []string{"TransactionID", "ChannelID", "MessageID", "PostedAt", "TradeGroupID"}
+
+// waiverRefWindow is how soon after a DROP its automatic waivers are created;
+// waivers started later are not linked to the drop's announcement
+const waiverRefWindow = 24 * time.Hour
+
+// MessageRef links a transaction to a Discord message that announced it.
+// Every piece of a trade has its own ref pointing at the trade's message.
+type MessageRef struct {
+	TransactionID string
+	TradeGroupID  string
+	ChannelID     string
+	MessageID     string
+	PostedAt      time.Time
+}
+
+// MessageStorage handles persistent storage of transaction announcement messages
+type MessageStorage struct {
+	mu       *sync.RWMutex
+	filePath string
+}
+
+// NewMessageStorage creates a new message storage instance in the given data directory
+func NewMessageStorage(dataDir string) (*MessageStorage, error) {
+	// Ensure data directory exists
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	filePath := filepath.Join(dataDir, messageFileName)
+	ms := &MessageStorage{
+		mu:       fileMutex(filePath),
+		filePath: filePath,
+	}
+
+	// Create file if it doesn't exist
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		if err := ms.createFile(); err != nil {
+			return nil, err
+		}
+	}
+
+	return ms, nil
+}
+
+// createFile creates the CSV file with headers
+func (ms *MessageStorage) createFile() error {
+	if err := writeCSVAtomic(ms.filePath, [][]string{messageHeaders}); err != nil {
+		return fmt.Errorf("failed to create message file: %w", err)
+	}
+	return nil
+}
+
+// AddMessageRefs appends message references to the CSV file
+func (ms *MessageStorage) AddMessageRefs(refs []MessageRef) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	records := make([][]string, 0, len(refs))
+	for _, ref := range refs {
+		records = append(records, messageRecord(ref))
+	}
+
+	if err := appendCSVAtomic(ms.filePath, records); err != nil {
+		return fmt.Errorf("failed to write message records: %w", err)
+	}
+
+	return nil
+}
+
+// GetMessageRefs returns every message that announced the given transaction
+func (ms *MessageStorage) GetMessageRefs(transactionID string) ([]MessageRef, error) {
+	refs, err := ms.readRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []MessageRef
+	for _, ref := range refs {
+		if ref.TransactionID == transactionID {
+			matches = append(matches, ref)
+		}
+	}
+	return matches, nil
+}
+
+// GetTradeGroupRefs returns every message that announced the given trade group,
+// one per message
+func (ms *MessageStorage) GetTradeGroupRefs(tradeGroupID string) ([]MessageRef, error) {
+	refs, err := ms.readRefs()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []MessageRef
+	seen := make(map[string]bool)
+	for _, ref := range refs {
+		if ref.TradeGroupID == tradeGroupID && !seen[ref.MessageID] {
+			seen[ref.MessageID] = true
+			matches = append(matches, ref)
+		}
+	}
+	return matches, nil
+}
+
+// GetAllMessageRefs returns every stored message reference
+func (ms *MessageStorage) GetAllMessageRefs() ([]MessageRef, error) {
+	return ms.readRefs()
+}
+
+// Migrate brings the file up to the current columns and fills in data older
+// versions never recorded: trade group IDs for trade refs, and refs for DROP
+// announcements that were only remembered by their automatic waivers. It is
+// safe to run on every startup.
+func (ms *MessageStorage) Migrate(transactions []fantraxmodels.Transaction, waivers []*models.Waiver) (int, error) {
+	refs, err := ms.readRefs()
+	if err != nil {
+		return 0, err
+	}
+
+	refs, changed := backfillMessageRefs(refs, transactions, waivers)
+
+	if changed == 0 && !ms.needsMigration() {
+		return 0, nil
+	}
+	return changed, ms.writeRefs(refs)
+}
+
+// backfillMessageRefs fills in trade group IDs missing from trade refs and
+// adds refs for DROP announcements only remembered by their automatic
+// waivers. It returns the refs and how many were changed or added.
+func backfillMessageRefs(refs []MessageRef, transactions []fantraxmodels.Transaction, waivers []*models.Waiver) ([]MessageRef, int) {
+	byID := make(map[string]fantraxmodels.Transaction, len(transactions))
+	for _, tx := range transactions {
+		byID[tx.ID] = tx
+	}
+
+	changed := 0
+	existing := make(map[string]bool, len(refs))
+	for i := range refs {
+		existing[refs[i].TransactionID+"|"+refs[i].MessageID] = true
+		if tx, ok := byID[refs[i].TransactionID]; ok && refs[i].TradeGroupID == "" && tx.TradeGroupID != "" {
+			refs[i].TradeGroupID = tx.TradeGroupID
+			changed++
+		}
+	}
+
+	for _, ref := range waiverMessageRefs(transactions, waivers) {
+		key := ref.TransactionID + "|" + ref.MessageID
+		if !existing[key] {
+			existing[key] = true
+			refs = append(refs, ref)
+			changed++
+		}
+	}
+
+	return refs, changed
+}
+
+// waiverMessageRefs links automatic waivers back to the DROP they were created
+// for: same player and team, started within waiverRefWindow of the drop
+func waiverMessageRefs(transactions []fantraxmodels.Transaction, waivers []*models.Waiver) []MessageRef {
+	var refs []MessageRef
+	seen := make(map[string]bool)
+
+	for _, waiver := range waivers {
+		if waiver.MessageID == "" || seen[waiver.MessageID] {
+			continue
+		}
+
+		for _, tx := range transactions {
+			if tx.Type != "DROP" || !strings.EqualFold(tx.PlayerName, waiver.PlayerName) || !strings.EqualFold(tx.TeamName, waiver.TeamName) {
+				continue
+			}
+			if waiver.StartTime.Before(tx.ProcessedDate) || waiver.StartTime.Sub(tx.ProcessedDate) > waiverRefWindow {
+				continue
+			}
+
+			seen[waiver.MessageID] = true
+			refs = append(refs, MessageRef{
+				TransactionID: tx.ID,
+				ChannelID:     waiver.ChannelID,
+				MessageID:     waiver.MessageID,
+				PostedAt:      waiver.StartTime,
+			})
+			break
+		}
+	}
+
+	return refs
+}
+
+// needsMigration reports whether the file's header is missing columns
+func (ms *MessageStorage) needsMigration() bool {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	file, err := os.Open(ms.filePath)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	header, err := csv.NewReader(file).Read()
+	return err == nil && len(header) < len(messageHeaders)
+}
+
+// readRefs reads all message references from the CSV file
+func (ms *MessageStorage) readRefs() ([]MessageRef, error) {
+	ms.mu.RLock()
+	defer ms.mu.RUnlock()
+
+	file, err := os.Open(ms.filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open message file: %w", err)
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read message file: %w", err)
+	}
+
+	var refs []MessageRef
+	// Skip header row
+	for i := 1; i < len(records); i++ {
+		record := records[i]
+		if len(record) < 4 {
+			continue
+		}
+
+		postedAt, _ := time.Parse(time.RFC3339, record[3])
+		ref := MessageRef{
+			TransactionID: record[0],
+			ChannelID:     record[1],
+			MessageID:     record[2],
+			PostedAt:      postedAt,
+		}
+		if len(record) > 4 {
+			ref.TradeGroupID = record[4]
+		}
+		refs = append(refs, ref)
+	}
+
+	return refs, nil
+}
+
+// writeRefs replaces the file with the given refs
+func (ms *MessageStorage) writeRefs(refs []MessageRef) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	records := [][]string{messageHeaders}
+	for _, ref := range refs {
+		records = append(records, messageRecord(ref))
+	}
+
+	if err := writeCSVAtomic(ms.filePath, records); err != nil {
+		return fmt.Errorf("failed to write message records: %w", err)
+	}
+
+	return nil
+}
+
+// messageRecord converts a message reference into a CSV row
+func messageRecord(ref MessageRef) []string {
+	return []string{ref.TransactionID, ref.ChannelID, ref.MessageID, ref.PostedAt.Format(time.RFC3339), ref.TradeGroupID}
+}
@@ -0,0 +1,166 @@
+package storage
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// lockFileName is the advisory lock file in a data directory
+const lockFileName = ".lock"
+
+// ErrDataDirLocked is returned when another process has the data directory open
+var ErrDataDirLocked = errors.New("data directory is in use by another ulb-bot process")
+
+// csvFile describes a storage file well enough to tell whether it is intact
+type csvFile struct {
+	name      string
+	headers   []string
+	minFields int // Rows written by the oldest supported version have at least this many columns
+}
+
+// csvFiles are the files of the CSV backend
+var csvFiles = []csvFile{
+	{name: transactionFileName, headers: transactionHeaders, minFields: 20},
+	{name: waiverFileName, headers: waiverHeaders, minFields: 8},
+	{name: messageFileName, headers: messageHeaders, minFields: 4},
+	{name: retentionFileName, headers: retentionHeaders, minFields: len(retentionHeaders)},
+	{name: sheetChangeFileName, headers: sheetChangeHeaders, minFields: len(sheetChangeHeaders)},
+}
+
+// StateFiles lists the files that hold the bot's state in a data directory,
+// for both backends
+func StateFiles() []string {
+	names := make([]string, 0, len(csvFiles)+1)
+	for _, f := range csvFiles {
+		names = append(names, f.name)
+	}
+	return append(names, boltFileName)
+}
+
+// BoltFileName is the database file of the bolt backend
+const BoltFileName = boltFileName
+
+// LockDir takes the lock a running bot holds on dataDir, for maintenance that
+// must not run alongside it. The returned function releases it.
+func LockDir(dataDir string) (func() error, error) {
+	lock, err := lockDir(dataDir)
+	if err != nil {
+		return nil, err
+	}
+	return lock.unlock, nil
+}
+
+// ReplaceFile atomically replaces the file at path with data, keeping the
+// previous contents as a backup like every storage write
+func ReplaceFile(path string, data []byte) error {
+	return replaceFile(path, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// Recovery records a storage file that was corrupt and restored from its backup
+type Recovery struct {
+	File    string
+	Problem string
+	Corrupt string // Where the corrupt file was moved for inspection
+}
+
+// RecoverCSV checks every CSV file in dataDir and restores any that are
+// corrupt from the copy kept by the last write. Corrupt files are kept
+// alongside with a .corrupt suffix. It fails when a file is corrupt and its
+// backup is unusable, rather than letting the bot start on lost data.
+func RecoverCSV(dataDir string) ([]Recovery, error) {
+	var recoveries []Recovery
+
+	for _, f := range csvFiles {
+		path := filepath.Join(dataDir, f.name)
+		problem := f.check(path)
+		if problem == nil {
+			continue
+		}
+		if os.IsNotExist(problem) {
+			// A missing file is created empty, unless a backup shows it had data
+			if _, err := os.Stat(path + backupSuffix); err != nil {
+				continue
+			}
+		}
+
+		if err := f.check(path + backupSuffix); err != nil {
+			return recoveries, fmt.Errorf("%s is corrupt (%v) and its backup is unusable (%v)", f.name, problem, err)
+		}
+
+		recovery := Recovery{File: f.name, Problem: problem.Error()}
+		if !os.IsNotExist(problem) {
+			recovery.Corrupt = fmt.Sprintf("%s.corrupt-%s", path, time.Now().Format("20060102-150405"))
+			if err := os.Rename(path, recovery.Corrupt); err != nil {
+				return recoveries, fmt.Errorf("failed to move corrupt %s aside: %w", f.name, err)
+			}
+		}
+
+		backup, err := os.ReadFile(path + backupSuffix)
+		if err != nil {
+			return recoveries, fmt.Errorf("failed to read backup of %s: %w", f.name, err)
+		}
+		tmp, err := writeTemp(dataDir, f.name, func(w io.Writer) error {
+			_, err := w.Write(backup)
+			return err
+		})
+		if err != nil {
+			return recoveries, err
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			os.Remove(tmp)
+			return recoveries, fmt.Errorf("failed to restore %s: %w", f.name, err)
+		}
+		if err := syncDir(dataDir); err != nil {
+			return recoveries, err
+		}
+
+		recoveries = append(recoveries, recovery)
+	}
+
+	return recoveries, nil
+}
+
+// check returns why the file at path is not a readable storage file, or nil
+func (f csvFile) check(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return fmt.Errorf("unreadable CSV: %w", err)
+	}
+	if len(records) == 0 {
+		return errors.New("file is empty")
+	}
+
+	header := records[0]
+	if len(header) < f.minFields || len(header) > len(f.headers) {
+		return fmt.Errorf("header has %d columns", len(header))
+	}
+	for i, column := range header {
+		if column != f.headers[i] {
+			return fmt.Errorf("unexpected header column %q", column)
+		}
+	}
+
+	for i, record := range records[1:] {
+		if len(record) < f.minFields || len(record) > len(f.headers) {
+			return fmt.Errorf("row %d has %d columns", i+2, len(record))
+		}
+	}
+
+	return nil
+}
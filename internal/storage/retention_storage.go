@@ -0,0 +1,157 @@
+package storage
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const retentionFileName = "retentions.csv"
+
+// retentionHeaders are the CSV columns, in order
+var retentionHeaders = []string{"TradeGroupID", "PlayerName", "Percent", "RecordedBy", "RecordedAt"}
+
+// Retention is salary a team kept when trading a player away
+type Retention struct {
+	TradeGroupID string
+	PlayerName   string
+	Percent      float64
+	RecordedBy   string
+	RecordedAt   time.Time
+}
+
+// RetentionStorage handles persistent storage of salary retention recorded for trades
+type RetentionStorage struct {
+	mu       *sync.RWMutex
+	filePath string
+}
+
+// NewRetentionStorage creates a new retention storage instance in the given data directory
+func NewRetentionStorage(dataDir string) (*RetentionStorage, error) {
+	// Ensure data directory exists
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	filePath := filepath.Join(dataDir, retentionFileName)
+	rs := &RetentionStorage{
+		mu:       fileMutex(filePath),
+		filePath: filePath,
+	}
+
+	// Create file if it doesn't exist
+	if _, err := os.Stat(rs.filePath); os.IsNotExist(err) {
+		if err := rs.writeRetentions(nil); err != nil {
+			return nil, err
+		}
+	}
+
+	return rs, nil
+}
+
+// SetRetention records retention for a player in a trade, replacing any
+// earlier value. A percent of zero removes it.
+func (rs *RetentionStorage) SetRetention(retention Retention) error {
+	retentions, err := rs.readRetentions()
+	if err != nil {
+		return err
+	}
+
+	kept := retentions[:0]
+	for _, r := range retentions {
+		if r.TradeGroupID == retention.TradeGroupID && strings.EqualFold(r.PlayerName, retention.PlayerName) {
+			continue
+		}
+		kept = append(kept, r)
+	}
+	if retention.Percent > 0 {
+		kept = append(kept, retention)
+	}
+
+	return rs.writeRetentions(kept)
+}
+
+// GetRetentions returns the retention recorded for a trade group
+func (rs *RetentionStorage) GetRetentions(tradeGroupID string) ([]Retention, error) {
+	retentions, err := rs.readRetentions()
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []Retention
+	for _, r := range retentions {
+		if r.TradeGroupID == tradeGroupID {
+			matches = append(matches, r)
+		}
+	}
+	return matches, nil
+}
+
+// readRetentions reads all retention records from the CSV file
+func (rs *RetentionStorage) readRetentions() ([]Retention, error) {
+	rs.mu.RLock()
+	defer rs.mu.RUnlock()
+
+	file, err := os.Open(rs.filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open retention file: %w", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read retention file: %w", err)
+	}
+
+	var retentions []Retention
+	// Skip header row
+	for i := 1; i < len(records); i++ {
+		record := records[i]
+		if len(record) < 5 {
+			continue
+		}
+
+		percent, err := strconv.ParseFloat(record[2], 64)
+		if err != nil {
+			continue
+		}
+		recordedAt, _ := time.Parse(time.RFC3339, record[4])
+		retentions = append(retentions, Retention{
+			TradeGroupID: record[0],
+			PlayerName:   record[1],
+			Percent:      percent,
+			RecordedBy:   record[3],
+			RecordedAt:   recordedAt,
+		})
+	}
+
+	return retentions, nil
+}
+
+// writeRetentions replaces the file with the given records
+func (rs *RetentionStorage) writeRetentions(retentions []Retention) error {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	records := [][]string{retentionHeaders}
+	for _, r := range retentions {
+		records = append(records, []string{
+			r.TradeGroupID,
+			r.PlayerName,
+			strconv.FormatFloat(r.Percent, 'f', -1, 64),
+			r.RecordedBy,
+			r.RecordedAt.Format(time.RFC3339),
+		})
+	}
+
+	if err := writeCSVAtomic(rs.filePath, records); err != nil {
+		return fmt.Errorf("failed to write retention records: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pmurley/ulb-bot/internal/models"
+)
+
+const sheetChangeFileName = "sheet_changes.csv"
+
+// sheetChangeHeaders are the CSV columns, in order
+var sheetChangeHeaders = []string{"DetectedAt", "PlayerName", "Team", "Field", "Old", "New"}
+
+// SheetChange is a player change detected when the sheet was reloaded
+type SheetChange struct {
+	DetectedAt time.Time
+	models.PlayerChange
+}
+
+// SheetChangeStorage handles persistent storage of detected sheet changes
+type SheetChangeStorage struct {
+	mu       *sync.RWMutex
+	filePath string
+}
+
+// NewSheetChangeStorage creates a new sheet change storage instance in the given data directory
+func NewSheetChangeStorage(dataDir string) (*SheetChangeStorage, error) {
+	// Ensure data directory exists
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	filePath := filepath.Join(dataDir, sheetChangeFileName)
+	ss := &SheetChangeStorage{
+		mu:       fileMutex(filePath),
+		filePath: filePath,
+	}
+
+	// Create file if it doesn't exist
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		if err := ss.createFile(); err != nil {
+			return nil, err
+		}
+	}
+
+	return ss, nil
+}
+
+// createFile creates the CSV file with headers
+func (ss *SheetChangeStorage) createFile() error {
+	if err := writeCSVAtomic(ss.filePath, [][]string{sheetChangeHeaders}); err != nil {
+		return fmt.Errorf("failed to create sheet change file: %w", err)
+	}
+	return nil
+}
+
+// AddChanges appends changes detected at the given time
+func (ss *SheetChangeStorage) AddChanges(detectedAt time.Time, changes []models.PlayerChange) error {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	records := make([][]string, 0, len(changes))
+	for _, c := range changes {
+		records = append(records, []string{detectedAt.Format(time.RFC3339), c.PlayerName, c.Team, c.Field, c.Old, c.New})
+	}
+
+	if err := appendCSVAtomic(ss.filePath, records); err != nil {
+		return fmt.Errorf("failed to write sheet change records: %w", err)
+	}
+
+	return nil
+}
+
+// GetChangesBetween returns changes detected in [start, end)
+func (ss *SheetChangeStorage) GetChangesBetween(start, end time.Time) ([]SheetChange, error) {
+	ss.mu.RLock()
+	defer ss.mu.RUnlock()
+
+	file, err := os.Open(ss.filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sheet change file: %w", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sheet change file: %w", err)
+	}
+
+	var changes []SheetChange
+	// Skip header row
+	for i := 1; i < len(records); i++ {
+		record := records[i]
+		if len(record) < 6 {
+			continue
+		}
+
+		detectedAt, err := time.Parse(time.RFC3339, record[0])
+		if err != nil || detectedAt.Before(start) || !detectedAt.Before(end) {
+			continue
+		}
+
+		changes = append(changes, SheetChange{
+			DetectedAt: detectedAt,
+			PlayerChange: models.PlayerChange{
+				PlayerName: record[1],
+				Team:       record[2],
+				Field:      record[3],
+				Old:        record[4],
+				New:        record[5],
+			},
+		})
+	}
+
+	return changes, nil
+}
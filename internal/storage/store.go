@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	fantraxmodels "github.com/pmurley/go-fantrax/models"
+	"github.com/pmurley/ulb-bot/internal/models"
+)
+
+// Storage backends selectable with STORAGE_BACKEND
+const (
+	BackendCSV  = "csv"  // One CSV file per record type
+	BackendBolt = "bolt" // A single embedded bbolt database
+)
+
+// TransactionStore persists Fantrax transactions
+type TransactionStore interface {
+	AddTransactions(transactions []fantraxmodels.Transaction) error
+	// GetAllTransactions returns every stored transaction except reversed ones, oldest first
+	GetAllTransactions() ([]fantraxmodels.Transaction, error)
+	// GetTradeGroup returns the pieces of one trade
+	GetTradeGroup(tradeGroupID string) ([]fantraxmodels.Transaction, error)
+	// GetPlayerTransactions returns every transaction involving a player, matched by normalized name
+	GetPlayerTransactions(playerName string) ([]fantraxmodels.Transaction, error)
+	GetTransactionIDs() (map[string]bool, error)
+	GetTradeGroupIDs() (map[string]bool, error)
+	UpdateTransactions(transactions []fantraxmodels.Transaction, status string) error
+	MarkTransactions(ids []string, status string) error
+}
+
+// WaiverStore persists waivers started by DFAs and drops
+type WaiverStore interface {
+	AddWaiver(waiver *models.Waiver) error
+	GetActiveWaivers() ([]*models.Waiver, error)
+	GetAllWaivers() ([]*models.Waiver, error)
+	MarkWaiverProcessed(messageID string) error
+	CancelWaiver(messageID string) error
+}
+
+// MessageStore persists which Discord messages announced which transactions
+type MessageStore interface {
+	AddMessageRefs(refs []MessageRef) error
+	GetMessageRefs(transactionID string) ([]MessageRef, error)
+	GetTradeGroupRefs(tradeGroupID string) ([]MessageRef, error)
+	GetAllMessageRefs() ([]MessageRef, error)
+	Migrate(transactions []fantraxmodels.Transaction, waivers []*models.Waiver) (int, error)
+}
+
+// RetentionStore persists salary retention recorded for trades
+type RetentionStore interface {
+	SetRetention(retention Retention) error
+	GetRetentions(tradeGroupID string) ([]Retention, error)
+}
+
+// SheetChangeStore persists player changes detected on sheet reloads
+type SheetChangeStore interface {
+	AddChanges(detectedAt time.Time, changes []models.PlayerChange) error
+	GetChangesBetween(start, end time.Time) ([]SheetChange, error)
+}
+
+// Store is the bot's persistent state. It is opened once at startup and
+// shared by everything that reads or writes records.
+type Store interface {
+	Transactions() TransactionStore
+	Waivers() WaiverStore
+	Messages() MessageStore
+	Retentions() RetentionStore
+	SheetChanges() SheetChangeStore
+	Close() error
+}
+
+// Open opens the store for the given backend in dataDir. For the CSV backend
+// it also returns the files that were corrupt and restored from backup.
+func Open(backend, dataDir string) (Store, []Recovery, error) {
+	switch backend {
+	case "", BackendCSV:
+		return OpenCSV(dataDir)
+	case BackendBolt:
+		store, err := OpenBolt(dataDir)
+		return store, nil, err
+	default:
+		return nil, nil, fmt.Errorf("unknown storage backend %q (use %s or %s)", backend, BackendCSV, BackendBolt)
+	}
+}
+
+// ReadTransactions returns the stored transactions in dataDir without opening
+// the store. It takes no lock and writes nothing, so a running bot using the
+// CSV backend can keep the directory. bbolt locks its file while the bot has
+// it open, so the bolt backend still needs the bot stopped.
+func ReadTransactions(backend, dataDir string) ([]fantraxmodels.Transaction, error) {
+	switch backend {
+	case "", BackendCSV:
+		return readCSVTransactions(dataDir)
+	case BackendBolt:
+		return readBoltTransactions(dataDir)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q (use %s or %s)", backend, BackendCSV, BackendBolt)
+	}
+}
+
+// csvStore keeps each record type in its own CSV file
+type csvStore struct {
+	lock         *dirLock
+	transactions *TransactionStorage
+	waivers      *WaiverStorage
+	messages     *MessageStorage
+	retentions   *RetentionStorage
+	sheetChanges *SheetChangeStorage
+}
+
+// OpenCSV locks dataDir against other processes, restores corrupt files from
+// their backups and opens the CSV files, creating any that are missing
+func OpenCSV(dataDir string) (Store, []Recovery, error) {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("failed to create data directory: %w", err)
+	}
+
+	lock, err := lockDir(dataDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s, recoveries, err := openCSVFiles(dataDir)
+	if err != nil {
+		lock.unlock()
+		return nil, recoveries, err
+	}
+	s.lock = lock
+
+	return s, recoveries, nil
+}
+
+// openCSVFiles recovers and opens the files of a locked data directory
+func openCSVFiles(dataDir string) (*csvStore, []Recovery, error) {
+	recoveries, err := RecoverCSV(dataDir)
+	if err != nil {
+		return nil, recoveries, err
+	}
+
+	var s csvStore
+	if s.transactions, err = NewTransactionStorage(dataDir); err != nil {
+		return nil, recoveries, fmt.Errorf("failed to open transaction storage: %w", err)
+	}
+	if s.waivers, err = NewWaiverStorage(dataDir); err != nil {
+		return nil, recoveries, fmt.Errorf("failed to open waiver storage: %w", err)
+	}
+	if s.messages, err = NewMessageStorage(dataDir); err != nil {
+		return nil, recoveries, fmt.Errorf("failed to open message storage: %w", err)
+	}
+	if s.retentions, err = NewRetentionStorage(dataDir); err != nil {
+		return nil, recoveries, fmt.Errorf("failed to open retention storage: %w", err)
+	}
+	if s.sheetChanges, err = NewSheetChangeStorage(dataDir); err != nil {
+		return nil, recoveries, fmt.Errorf("failed to open sheet change storage: %w", err)
+	}
+
+	return &s, recoveries, nil
+}
+
+func (s *csvStore) Transactions() TransactionStore { return s.transactions }
+func (s *csvStore) Waivers() WaiverStore           { return s.waivers }
+func (s *csvStore) Messages() MessageStore         { return s.messages }
+func (s *csvStore) Retentions() RetentionStore     { return s.retentions }
+func (s *csvStore) SheetChanges() SheetChangeStore { return s.sheetChanges }
+
+// Close releases the data directory lock; CSV files are only open while
+// they are read or written
+func (s *csvStore) Close() error { return s.lock.unlock() }
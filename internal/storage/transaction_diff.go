@@ -0,0 +1,85 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/pmurley/go-fantrax/models"
+)
+
+// FieldChange is one field that differs between a stored transaction and Fantrax
+type FieldChange struct {
+	Field string
+	Old   string
+	New   string
+}
+
+// Amendment is a stored transaction that Fantrax now reports differently
+type Amendment struct {
+	Old     models.Transaction
+	New     models.Transaction
+	Changes []FieldChange
+}
+
+// TransactionDiff is the result of comparing stored transactions with a full Fantrax fetch
+type TransactionDiff struct {
+	Reversed   []models.Transaction // Stored, but no longer returned by Fantrax
+	Amended    []Amendment          // Returned with different fields
+	Backfilled []models.Transaction // Only gained data the stored row never had, e.g. ClaimType
+}
+
+// DiffTransactions compares stored transactions with everything Fantrax
+// currently returns. Transactions Fantrax returns that are not stored yet are
+// ignored; the monitor's regular poll picks those up.
+func DiffTransactions(stored, fetched []models.Transaction) TransactionDiff {
+	current := make(map[string]models.Transaction, len(fetched))
+	for _, tx := range fetched {
+		current[tx.ID] = tx
+	}
+
+	var diff TransactionDiff
+	for _, old := range stored {
+		tx, exists := current[old.ID]
+		if !exists {
+			diff.Reversed = append(diff.Reversed, old)
+			continue
+		}
+
+		changes := compareTransactions(old, tx)
+		switch {
+		case len(changes) > 0:
+			diff.Amended = append(diff.Amended, Amendment{Old: old, New: tx, Changes: changes})
+		case old.ClaimType == "" && tx.ClaimType != "":
+			// Rows stored before ClaimType was recorded are filled in quietly
+			diff.Backfilled = append(diff.Backfilled, tx)
+		}
+	}
+
+	return diff
+}
+
+// compareTransactions lists the meaningful fields that differ. ClaimType only
+// counts when the stored row had one, so older rows are not flagged.
+func compareTransactions(old, tx models.Transaction) []FieldChange {
+	var changes []FieldChange
+	add := func(field, oldValue, newValue string) {
+		if oldValue != newValue {
+			changes = append(changes, FieldChange{Field: field, Old: oldValue, New: newValue})
+		}
+	}
+
+	add("Type", old.Type, tx.Type)
+	if old.ClaimType != "" {
+		add("Claim type", old.ClaimType, tx.ClaimType)
+	}
+	add("Team", old.TeamName, tx.TeamName)
+	add("From", old.FromTeamName, tx.FromTeamName)
+	add("To", old.ToTeamName, tx.ToTeamName)
+	if old.PlayerID != tx.PlayerID || old.PlayerName != tx.PlayerName {
+		add("Player", old.PlayerName, tx.PlayerName)
+	}
+	add("Bid", old.BidAmount, tx.BidAmount)
+	add("Period", fmt.Sprint(old.Period), fmt.Sprint(tx.Period))
+	add("Executed by", old.ExecutedBy, tx.ExecutedBy)
+
+	return changes
+}
@@ -0,0 +1,82 @@
+package storage
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pmurley/go-fantrax/models"
+	ulbmodels "github.com/pmurley/ulb-bot/internal/models"
+)
+
+// TransactionFilter selects stored transactions. Zero values match everything.
+type TransactionFilter struct {
+	Team       string    // Team name (substring, either side of a trade)
+	Player     string    // Player name (substring)
+	Type       string    // CLAIM, DROP or TRADE
+	ClaimType  string    // FA or WW
+	ExecutedBy string    // e.g. COMMISSIONER
+	Period     int       // Fantrax scoring period
+	From       time.Time // Processed on or after
+	To         time.Time // Processed before
+}
+
+// Matches reports whether a transaction passes the filter
+func (f TransactionFilter) Matches(tx models.Transaction) bool {
+	if f.Team != "" && !containsFold(f.Team, tx.TeamName, tx.FromTeamName, tx.ToTeamName) {
+		return false
+	}
+	if f.Player != "" && !containsFold(f.Player, tx.PlayerName) {
+		return false
+	}
+	if f.Type != "" && !strings.EqualFold(f.Type, tx.Type) {
+		return false
+	}
+	if f.ClaimType != "" && !strings.EqualFold(f.ClaimType, tx.ClaimType) {
+		return false
+	}
+	if f.ExecutedBy != "" && !strings.EqualFold(f.ExecutedBy, tx.ExecutedBy) {
+		return false
+	}
+	if f.Period != 0 && f.Period != tx.Period {
+		return false
+	}
+	if !f.From.IsZero() && tx.ProcessedDate.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !tx.ProcessedDate.Before(f.To) {
+		return false
+	}
+	return true
+}
+
+// FilterTransactions returns the matching transactions, newest first
+func FilterTransactions(transactions []models.Transaction, filter TransactionFilter) []models.Transaction {
+	var matched []models.Transaction
+	for _, tx := range transactions {
+		if filter.Matches(tx) {
+			matched = append(matched, tx)
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].ProcessedDate.After(matched[j].ProcessedDate)
+	})
+	return matched
+}
+
+// containsFold reports whether any value contains the search string (case-insensitive)
+func containsFold(search string, values ...string) bool {
+	search = strings.ToLower(strings.TrimSpace(search))
+	for _, v := range values {
+		if v != "" && strings.Contains(strings.ToLower(v), search) {
+			return true
+		}
+	}
+	return false
+}
+
+// playerKey is the form player names are matched and indexed by
+func playerKey(name string) string {
+	return ulbmodels.NormalizeName(name)
+}
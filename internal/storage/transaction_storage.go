@@ -3,6 +3,7 @@ package storage
 import (
 	"encoding/csv"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -14,14 +15,33 @@ import (
 
 const transactionFileName = "transactions.csv"
 
+// transactionHeaders are the CSV columns, in order. Columns are only ever
+// appended so older files can be migrated by padding rows.
+var transactionHeaders = []string{
+	"ID", "Type", "TeamName", "TeamID", "FromTeamName", "FromTeamID",
+	"ToTeamName", "ToTeamID", "PlayerName", "PlayerID", "PlayerTeam",
+	"PlayerPosition", "BidAmount", "Priority", "ProcessedDate", "Period",
+	"Executed", "ExecutedBy", "TradeGroupID", "TradeGroupSize", "ClaimType",
+	"Status",
+}
+
+// statusColumn is the index of the Status column
+const statusColumn = 21
+
+// Transaction statuses recorded when Fantrax changes a transaction after it was stored
+const (
+	TransactionStatusReversed = "REVERSED" // No longer returned by Fantrax
+	TransactionStatusAmended  = "AMENDED"  // Fields changed since it was announced
+)
+
 // TransactionStorage handles persistent storage of transactions
 type TransactionStorage struct {
-	mu       sync.RWMutex
+	mu       *sync.RWMutex
 	filePath string
 }
 
-// NewTransactionStorage creates a new transaction storage instance
-func NewTransactionStorage() (*TransactionStorage, error) {
+// NewTransactionStorage creates a new transaction storage instance in the given data directory
+func NewTransactionStorage(dataDir string) (*TransactionStorage, error) {
 	// Ensure data directory exists
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
@@ -29,14 +49,17 @@ func NewTransactionStorage() (*TransactionStorage, error) {
 
 	filePath := filepath.Join(dataDir, transactionFileName)
 	ts := &TransactionStorage{
+		mu:       fileMutex(filePath),
 		filePath: filePath,
 	}
 
-	// Create file if it doesn't exist
+	// Create file if it doesn't exist, otherwise bring it up to the current columns
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
 		if err := ts.createFile(); err != nil {
 			return nil, err
 		}
+	} else if err := ts.migrate(); err != nil {
+		return nil, err
 	}
 
 	return ts, nil
@@ -44,23 +67,44 @@ func NewTransactionStorage() (*TransactionStorage, error) {
 
 // createFile creates the CSV file with headers
 func (ts *TransactionStorage) createFile() error {
-	file, err := os.Create(ts.filePath)
-	if err != nil {
+	if err := writeCSVAtomic(ts.filePath, [][]string{transactionHeaders}); err != nil {
 		return fmt.Errorf("failed to create transaction file: %w", err)
 	}
-	defer file.Close()
+	return nil
+}
 
-	writer := csv.NewWriter(file)
-	headers := []string{
-		"ID", "Type", "TeamName", "TeamID", "FromTeamName", "FromTeamID",
-		"ToTeamName", "ToTeamID", "PlayerName", "PlayerID", "PlayerTeam",
-		"PlayerPosition", "BidAmount", "Priority", "ProcessedDate", "Period",
-		"Executed", "ExecutedBy", "TradeGroupID", "TradeGroupSize",
+// migrate pads files written by older versions with the columns added since
+func (ts *TransactionStorage) migrate() error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	file, err := os.Open(ts.filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open transaction file: %w", err)
 	}
-	if err := writer.Write(headers); err != nil {
-		return fmt.Errorf("failed to write headers: %w", err)
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read transaction file: %w", err)
+	}
+
+	if len(records) == 0 || len(records[0]) >= len(transactionHeaders) {
+		return nil
+	}
+
+	records[0] = transactionHeaders
+	for i := 1; i < len(records); i++ {
+		for len(records[i]) < len(transactionHeaders) {
+			records[i] = append(records[i], "")
+		}
+	}
+
+	if err := writeCSVAtomic(ts.filePath, records); err != nil {
+		return fmt.Errorf("failed to write migrated transaction file: %w", err)
 	}
-	writer.Flush()
 
 	return nil
 }
@@ -70,49 +114,118 @@ func (ts *TransactionStorage) AddTransactions(transactions []models.Transaction)
 	ts.mu.Lock()
 	defer ts.mu.Unlock()
 
-	file, err := os.OpenFile(ts.filePath, os.O_APPEND|os.O_WRONLY, 0644)
+	records := make([][]string, 0, len(transactions))
+	for _, transaction := range transactions {
+		records = append(records, transactionRecord(transaction))
+	}
+
+	if err := appendCSVAtomic(ts.filePath, records); err != nil {
+		return fmt.Errorf("failed to write transaction records: %w", err)
+	}
+
+	return nil
+}
+
+// GetAllTransactions returns all stored transactions
+func (ts *TransactionStorage) GetAllTransactions() ([]models.Transaction, error) {
+	ts.mu.RLock()
+	defer ts.mu.RUnlock()
+
+	file, err := os.Open(ts.filePath)
 	if err != nil {
-		return fmt.Errorf("failed to open transaction file: %w", err)
+		return nil, fmt.Errorf("failed to open transaction file: %w", err)
 	}
 	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	return ReadTransactionsCSV(file)
+}
 
-	for _, transaction := range transactions {
-		record := []string{
-			transaction.ID,
-			transaction.Type,
-			transaction.TeamName,
-			transaction.TeamID,
-			transaction.FromTeamName,
-			transaction.FromTeamID,
-			transaction.ToTeamName,
-			transaction.ToTeamID,
-			transaction.PlayerName,
-			transaction.PlayerID,
-			transaction.PlayerTeam,
-			transaction.PlayerPosition,
-			transaction.BidAmount,
-			transaction.Priority,
-			transaction.ProcessedDate.Format(time.RFC3339),
-			strconv.Itoa(transaction.Period),
-			strconv.FormatBool(transaction.Executed),
-			transaction.ExecutedBy,
-			transaction.TradeGroupID,
-			strconv.Itoa(transaction.TradeGroupSize),
+// readCSVTransactions reads the transaction file in dataDir without creating
+// or migrating it. Files are replaced by rename, so a concurrent write is seen
+// whole or not at all.
+func readCSVTransactions(dataDir string) ([]models.Transaction, error) {
+	file, err := os.Open(filepath.Join(dataDir, transactionFileName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transaction file: %w", err)
+	}
+	defer file.Close()
+
+	return ReadTransactionsCSV(file)
+}
+
+// readCSVStoredTransactions reads every row of the transaction file in
+// dataDir, with its status, without creating or migrating the file
+func readCSVStoredTransactions(dataDir string) ([]storedTransaction, error) {
+	filePath := filepath.Join(dataDir, transactionFileName)
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return nil, nil
+	}
+	ts := &TransactionStorage{mu: fileMutex(filePath), filePath: filePath}
+	return ts.getStoredTransactions()
+}
+
+// ReadTransactionsCSV reads transactions in the storage CSV format. The first
+// row is treated as the header; rows that cannot be parsed and reversed
+// transactions are skipped.
+func ReadTransactionsCSV(r io.Reader) ([]models.Transaction, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transaction file: %w", err)
+	}
+
+	var transactions []models.Transaction
+	// Skip header row
+	for i := 1; i < len(records); i++ {
+		if len(records[i]) > statusColumn && records[i][statusColumn] == TransactionStatusReversed {
+			continue
 		}
+		if transaction, ok := parseTransactionRecord(records[i]); ok {
+			transactions = append(transactions, transaction)
+		}
+	}
 
-		if err := writer.Write(record); err != nil {
-			return fmt.Errorf("failed to write transaction record: %w", err)
+	return transactions, nil
+}
+
+// GetTradeGroup returns the stored pieces of one trade
+func (ts *TransactionStorage) GetTradeGroup(tradeGroupID string) ([]models.Transaction, error) {
+	transactions, err := ts.GetAllTransactions()
+	if err != nil {
+		return nil, err
+	}
+	return GroupTransactionsByTradeGroup(transactions)[tradeGroupID], nil
+}
+
+// GetPlayerTransactions returns every stored transaction involving a player
+func (ts *TransactionStorage) GetPlayerTransactions(playerName string) ([]models.Transaction, error) {
+	transactions, err := ts.GetAllTransactions()
+	if err != nil {
+		return nil, err
+	}
+
+	key := playerKey(playerName)
+	var matches []models.Transaction
+	for _, tx := range transactions {
+		if playerKey(tx.PlayerName) == key {
+			matches = append(matches, tx)
 		}
 	}
+	return matches, nil
+}
 
-	return nil
+// storedTransaction is a transaction with the status the bot recorded on it
+type storedTransaction struct {
+	models.Transaction
+	Status string `json:"status,omitempty"`
 }
 
-// GetAllTransactions returns all stored transactions
-func (ts *TransactionStorage) GetAllTransactions() ([]models.Transaction, error) {
+// getStoredTransactions returns every row with its status, including reversed ones
+func (ts *TransactionStorage) getStoredTransactions() ([]storedTransaction, error) {
 	ts.mu.RLock()
 	defer ts.mu.RUnlock()
 
@@ -123,55 +236,182 @@ func (ts *TransactionStorage) GetAllTransactions() ([]models.Transaction, error)
 	defer file.Close()
 
 	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
 	records, err := reader.ReadAll()
 	if err != nil {
 		return nil, fmt.Errorf("failed to read transaction file: %w", err)
 	}
 
-	var transactions []models.Transaction
+	var stored []storedTransaction
 	// Skip header row
 	for i := 1; i < len(records); i++ {
-		record := records[i]
-		if len(record) < 20 {
+		transaction, ok := parseTransactionRecord(records[i])
+		if !ok {
 			continue
 		}
+		row := storedTransaction{Transaction: transaction}
+		if len(records[i]) > statusColumn {
+			row.Status = records[i][statusColumn]
+		}
+		stored = append(stored, row)
+	}
 
-		processedDate, err := time.Parse(time.RFC3339, record[14])
-		if err != nil {
-			continue
+	return stored, nil
+}
+
+// UpdateTransactions replaces the stored rows of the given transactions
+// (matched by ID) with their new values. A non-empty status is recorded on
+// each row; an empty status keeps the row's current status.
+func (ts *TransactionStorage) UpdateTransactions(transactions []models.Transaction, status string) error {
+	updates := make(map[string]models.Transaction, len(transactions))
+	for _, tx := range transactions {
+		updates[tx.ID] = tx
+	}
+
+	return ts.rewrite(func(record []string) []string {
+		tx, exists := updates[record[0]]
+		if !exists {
+			return record
 		}
+		updated := transactionRecord(tx)
+		updated[statusColumn] = record[statusColumn]
+		if status != "" {
+			updated[statusColumn] = status
+		}
+		return updated
+	})
+}
 
-		period, _ := strconv.Atoi(record[15])
-		executed, _ := strconv.ParseBool(record[16])
-		tradeGroupSize, _ := strconv.Atoi(record[19])
-
-		transaction := models.Transaction{
-			ID:             record[0],
-			Type:           record[1],
-			TeamName:       record[2],
-			TeamID:         record[3],
-			FromTeamName:   record[4],
-			FromTeamID:     record[5],
-			ToTeamName:     record[6],
-			ToTeamID:       record[7],
-			PlayerName:     record[8],
-			PlayerID:       record[9],
-			PlayerTeam:     record[10],
-			PlayerPosition: record[11],
-			BidAmount:      record[12],
-			Priority:       record[13],
-			ProcessedDate:  processedDate,
-			Period:         period,
-			Executed:       executed,
-			ExecutedBy:     record[17],
-			TradeGroupID:   record[18],
-			TradeGroupSize: tradeGroupSize,
+// MarkTransactions records a status on the stored transactions with the given IDs
+func (ts *TransactionStorage) MarkTransactions(ids []string, status string) error {
+	marked := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		marked[id] = true
+	}
+
+	return ts.rewrite(func(record []string) []string {
+		if marked[record[0]] {
+			record[statusColumn] = status
 		}
+		return record
+	})
+}
 
-		transactions = append(transactions, transaction)
+// rewrite applies update to every data row and writes the file back
+func (ts *TransactionStorage) rewrite(update func(record []string) []string) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	file, err := os.Open(ts.filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open transaction file: %w", err)
 	}
 
-	return transactions, nil
+	reader := csv.NewReader(file)
+	records, err := reader.ReadAll()
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read transaction file: %w", err)
+	}
+
+	for i := 1; i < len(records); i++ {
+		if len(records[i]) == len(transactionHeaders) {
+			records[i] = update(records[i])
+		}
+	}
+
+	if err := writeCSVAtomic(ts.filePath, records); err != nil {
+		return fmt.Errorf("failed to write transaction records: %w", err)
+	}
+
+	return nil
+}
+
+// WriteTransactionsCSV writes transactions in the storage CSV format, including the header row
+func WriteTransactionsCSV(w io.Writer, transactions []models.Transaction) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(transactionHeaders); err != nil {
+		return fmt.Errorf("failed to write headers: %w", err)
+	}
+	for _, transaction := range transactions {
+		if err := writer.Write(transactionRecord(transaction)); err != nil {
+			return fmt.Errorf("failed to write transaction record: %w", err)
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// transactionRecord converts a transaction into a CSV row
+func transactionRecord(transaction models.Transaction) []string {
+	return []string{
+		transaction.ID,
+		transaction.Type,
+		transaction.TeamName,
+		transaction.TeamID,
+		transaction.FromTeamName,
+		transaction.FromTeamID,
+		transaction.ToTeamName,
+		transaction.ToTeamID,
+		transaction.PlayerName,
+		transaction.PlayerID,
+		transaction.PlayerTeam,
+		transaction.PlayerPosition,
+		transaction.BidAmount,
+		transaction.Priority,
+		transaction.ProcessedDate.Format(time.RFC3339),
+		strconv.Itoa(transaction.Period),
+		strconv.FormatBool(transaction.Executed),
+		transaction.ExecutedBy,
+		transaction.TradeGroupID,
+		strconv.Itoa(transaction.TradeGroupSize),
+		transaction.ClaimType,
+		"", // Status
+	}
+}
+
+// parseTransactionRecord converts a CSV row into a transaction
+func parseTransactionRecord(record []string) (models.Transaction, bool) {
+	if len(record) < 20 {
+		return models.Transaction{}, false
+	}
+
+	processedDate, err := time.Parse(time.RFC3339, record[14])
+	if err != nil {
+		return models.Transaction{}, false
+	}
+
+	period, _ := strconv.Atoi(record[15])
+	executed, _ := strconv.ParseBool(record[16])
+	tradeGroupSize, _ := strconv.Atoi(record[19])
+
+	transaction := models.Transaction{
+		ID:             record[0],
+		Type:           record[1],
+		TeamName:       record[2],
+		TeamID:         record[3],
+		FromTeamName:   record[4],
+		FromTeamID:     record[5],
+		ToTeamName:     record[6],
+		ToTeamID:       record[7],
+		PlayerName:     record[8],
+		PlayerID:       record[9],
+		PlayerTeam:     record[10],
+		PlayerPosition: record[11],
+		BidAmount:      record[12],
+		Priority:       record[13],
+		ProcessedDate:  processedDate,
+		Period:         period,
+		Executed:       executed,
+		ExecutedBy:     record[17],
+		TradeGroupID:   record[18],
+		TradeGroupSize: tradeGroupSize,
+	}
+	if len(record) > 20 {
+		transaction.ClaimType = record[20]
+	}
+
+	return transaction, true
 }
 
 // GetTransactionIDs returns a set of all stored transaction IDs for quick lookup
@@ -12,19 +12,20 @@ import (
 	"github.com/pmurley/ulb-bot/internal/models"
 )
 
-const (
-	waiverFileName = "waivers.csv"
-	dataDir        = "./data"
-)
+const waiverFileName = "waivers.csv"
+
+// waiverHeaders are the CSV columns, in order. Columns are only ever appended;
+// files written before ThreadID and Cancelled were added have fewer.
+var waiverHeaders = []string{"PlayerName", "TeamName", "UserID", "StartTime", "EndTime", "MessageID", "ChannelID", "Processed", "ThreadID", "Cancelled"}
 
 // WaiverStorage handles persistent storage of waivers
 type WaiverStorage struct {
-	mu       sync.RWMutex
+	mu       *sync.RWMutex
 	filePath string
 }
 
-// NewWaiverStorage creates a new waiver storage instance
-func NewWaiverStorage() (*WaiverStorage, error) {
+// NewWaiverStorage creates a new waiver storage instance in the given data directory
+func NewWaiverStorage(dataDir string) (*WaiverStorage, error) {
 	// Ensure data directory exists
 	if err := os.MkdirAll(dataDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create data directory: %w", err)
@@ -32,6 +33,7 @@ func NewWaiverStorage() (*WaiverStorage, error) {
 
 	filePath := filepath.Join(dataDir, waiverFileName)
 	ws := &WaiverStorage{
+		mu:       fileMutex(filePath),
 		filePath: filePath,
 	}
 
@@ -47,19 +49,9 @@ func NewWaiverStorage() (*WaiverStorage, error) {
 
 // createFile creates the CSV file with headers
 func (ws *WaiverStorage) createFile() error {
-	file, err := os.Create(ws.filePath)
-	if err != nil {
+	if err := writeCSVAtomic(ws.filePath, [][]string{waiverHeaders}); err != nil {
 		return fmt.Errorf("failed to create waiver file: %w", err)
 	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
-	headers := []string{"PlayerName", "TeamName", "UserID", "StartTime", "EndTime", "MessageID", "ChannelID", "Processed"}
-	if err := writer.Write(headers); err != nil {
-		return fmt.Errorf("failed to write headers: %w", err)
-	}
-	writer.Flush()
-
 	return nil
 }
 
@@ -68,13 +60,6 @@ func (ws *WaiverStorage) AddWaiver(waiver *models.Waiver) error {
 	ws.mu.Lock()
 	defer ws.mu.Unlock()
 
-	file, err := os.OpenFile(ws.filePath, os.O_APPEND|os.O_WRONLY, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to open waiver file: %w", err)
-	}
-	defer file.Close()
-
-	writer := csv.NewWriter(file)
 	record := []string{
 		waiver.PlayerName,
 		waiver.TeamName,
@@ -84,18 +69,39 @@ func (ws *WaiverStorage) AddWaiver(waiver *models.Waiver) error {
 		waiver.MessageID,
 		waiver.ChannelID,
 		strconv.FormatBool(waiver.Processed),
+		waiver.ThreadID,
+		strconv.FormatBool(waiver.Cancelled),
 	}
 
-	if err := writer.Write(record); err != nil {
+	if err := appendCSVAtomic(ws.filePath, [][]string{record}); err != nil {
 		return fmt.Errorf("failed to write waiver record: %w", err)
 	}
-	writer.Flush()
 
 	return nil
 }
 
 // GetActiveWaivers returns all unprocessed waivers
 func (ws *WaiverStorage) GetActiveWaivers() ([]*models.Waiver, error) {
+	return ws.readWaivers(false)
+}
+
+// GetAllWaivers returns every stored waiver, including processed ones
+func (ws *WaiverStorage) GetAllWaivers() ([]*models.Waiver, error) {
+	return ws.readWaivers(true)
+}
+
+// readCSVWaivers reads every waiver in dataDir without creating the file
+func readCSVWaivers(dataDir string) ([]*models.Waiver, error) {
+	filePath := filepath.Join(dataDir, waiverFileName)
+	if _, err := os.Stat(filePath); os.IsNotExist(err) {
+		return nil, nil
+	}
+	ws := &WaiverStorage{mu: fileMutex(filePath), filePath: filePath}
+	return ws.readWaivers(true)
+}
+
+// readWaivers reads waivers from the CSV file, optionally skipping processed ones
+func (ws *WaiverStorage) readWaivers(includeProcessed bool) ([]*models.Waiver, error) {
 	ws.mu.RLock()
 	defer ws.mu.RUnlock()
 
@@ -105,7 +111,9 @@ func (ws *WaiverStorage) GetActiveWaivers() ([]*models.Waiver, error) {
 	}
 	defer file.Close()
 
+	// Rows written before ThreadID and Cancelled were added have fewer columns
 	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
 	records, err := reader.ReadAll()
 	if err != nil {
 		return nil, fmt.Errorf("failed to read waiver file: %w", err)
@@ -120,7 +128,7 @@ func (ws *WaiverStorage) GetActiveWaivers() ([]*models.Waiver, error) {
 		}
 
 		processed, _ := strconv.ParseBool(record[7])
-		if processed {
+		if processed && !includeProcessed {
 			continue // Skip already processed waivers
 		}
 
@@ -144,6 +152,12 @@ func (ws *WaiverStorage) GetActiveWaivers() ([]*models.Waiver, error) {
 			ChannelID:  record[6],
 			Processed:  processed,
 		}
+		if len(record) > 8 {
+			waiver.ThreadID = record[8]
+		}
+		if len(record) > 9 {
+			waiver.Cancelled, _ = strconv.ParseBool(record[9])
+		}
 
 		waivers = append(waivers, waiver)
 	}
@@ -153,6 +167,18 @@ func (ws *WaiverStorage) GetActiveWaivers() ([]*models.Waiver, error) {
 
 // MarkWaiverProcessed updates a waiver as processed
 func (ws *WaiverStorage) MarkWaiverProcessed(messageID string) error {
+	return ws.markWaivers(messageID, false)
+}
+
+// CancelWaiver marks a waiver processed and cancelled, so it is not reported
+// as expired
+func (ws *WaiverStorage) CancelWaiver(messageID string) error {
+	return ws.markWaivers(messageID, true)
+}
+
+// markWaivers sets the processed flag, and optionally the cancelled flag, on
+// every waiver announced by the message
+func (ws *WaiverStorage) markWaivers(messageID string, cancel bool) error {
 	ws.mu.Lock()
 	defer ws.mu.Unlock()
 
@@ -163,6 +189,7 @@ func (ws *WaiverStorage) MarkWaiverProcessed(messageID string) error {
 	}
 
 	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = -1
 	records, err := reader.ReadAll()
 	if err != nil {
 		file.Close()
@@ -175,6 +202,12 @@ func (ws *WaiverStorage) MarkWaiverProcessed(messageID string) error {
 	for i := 1; i < len(records); i++ {
 		if len(records[i]) >= 8 && records[i][5] == messageID {
 			records[i][7] = "true"
+			if cancel {
+				for len(records[i]) < len(waiverHeaders) {
+					records[i] = append(records[i], "")
+				}
+				records[i][9] = "true"
+			}
 			updated = true
 		}
 	}
@@ -183,18 +216,15 @@ func (ws *WaiverStorage) MarkWaiverProcessed(messageID string) error {
 		return fmt.Errorf("waiver with message ID %s not found", messageID)
 	}
 
-	// Write all records back
-	file, err = os.Create(ws.filePath)
-	if err != nil {
-		return fmt.Errorf("failed to create waiver file: %w", err)
+	// Upgrade the header of files created before the newer columns were added
+	if len(records[0]) < len(waiverHeaders) {
+		records[0] = waiverHeaders
 	}
-	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	if err := writer.WriteAll(records); err != nil {
+	// Write all records back
+	if err := writeCSVAtomic(ws.filePath, records); err != nil {
 		return fmt.Errorf("failed to write waiver records: %w", err)
 	}
-	writer.Flush()
 
 	return nil
 }
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"strings"
@@ -23,10 +24,15 @@ type Logger struct {
 }
 
 func New(levelStr string) *Logger {
+	return NewWithOutput(levelStr, os.Stdout)
+}
+
+// NewWithOutput creates a logger that writes to w instead of stdout
+func NewWithOutput(levelStr string, w io.Writer) *Logger {
 	level := parseLevel(levelStr)
 	return &Logger{
 		level:  level,
-		logger: log.New(os.Stdout, "", 0),
+		logger: log.New(w, "", 0),
 	}
 }
 